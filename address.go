@@ -0,0 +1,36 @@
+package servicefabric
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ReplicaAddress encapsulates the JSON document stored in
+// the Address field of a replica or instance
+type ReplicaAddress struct {
+	Endpoints map[string]string `json:"Endpoints"`
+}
+
+// ParseReplicaAddress returns the named endpoints published
+// in the Address field of a replica or instance
+func ParseReplicaAddress(address string) (map[string]string, error) {
+	if address == "" {
+		return map[string]string{}, nil
+	}
+
+	var replicaAddress ReplicaAddress
+	err := json.Unmarshal([]byte(address), &replicaAddress)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise replica address %q: %+v", address, err)
+	}
+
+	if replicaAddress.Endpoints == nil {
+		return map[string]string{}, nil
+	}
+	return replicaAddress.Endpoints, nil
+}
+
+// GetEndpoints returns the named endpoints published by the replica
+func (m *ReplicaItemBase) GetEndpoints() (map[string]string, error) {
+	return ParseReplicaAddress(m.Address)
+}
@@ -0,0 +1,187 @@
+package servicefabric
+
+import (
+	"context"
+	"time"
+)
+
+// API is the Service Fabric API implemented by *Client. Code that
+// depends on the narrowest reader or writer it needs can be tested
+// with the mock in the sfmock package instead of a cluster.
+type API interface {
+	Reader
+	Writer
+}
+
+// Reader reads from the cluster without changing it
+type Reader interface {
+	ClusterReader
+	ApplicationReader
+	ServiceReader
+	PartitionReader
+	ReplicaReader
+	PropertyReader
+	EventReader
+	BackupReader
+	ChaosReader
+	FaultReader
+	RepairReader
+}
+
+// Writer changes the state of the cluster
+type Writer interface {
+	PropertyWriter
+	BackupWriter
+	ChaosWriter
+	FaultWriter
+	RepairWriter
+}
+
+// ClusterReader reads the cluster version
+type ClusterReader interface {
+	GetClusterVersion() (*ClusterVersion, error)
+}
+
+// ApplicationReader reads applications
+type ApplicationReader interface {
+	GetApplications() (*ApplicationItemsPage, error)
+	GetApplicationsWithOptions(opts ApplicationsOptions) (*ApplicationItemsPage, error)
+}
+
+// ServiceReader reads services, their types and manifests
+type ServiceReader interface {
+	GetServices(appName string) (*ServiceItemsPage, error)
+	GetServicesWithOptions(appName string, opts ServicesOptions) (*ServiceItemsPage, error)
+	GetServiceApplicationName(serviceID ServiceID) (*NameInfo, error)
+	GetServiceTypes(appType, applicationVersion string) ([]ServiceType, error)
+	GetServiceExtension(appType, applicationVersion, serviceTypeName, extensionKey string, response interface{}) error
+	GetServiceExtensionMap(service *ServiceItem, app *ApplicationItem, extensionKey string) (map[string]string, error)
+	GetServiceManifest(appType, applicationVersion, serviceManifestName string) (*ServiceManifest, error)
+	GetServiceGroupMembers(appName, serviceName string) (*ServiceGroupMembers, error)
+	GetServiceGroupDescription(serviceID ServiceID) (*ServiceGroupDescription, error)
+}
+
+// PartitionReader reads partitions
+type PartitionReader interface {
+	GetPartitions(appName, serviceName string) (*PartitionItemsPage, error)
+	GetPartitionsWithOptions(appName, serviceName string, opts ListOptions) (*PartitionItemsPage, error)
+	GetServicePartitions(serviceID ServiceID) (*PartitionItemsPage, error)
+	GetPartition(partitionID PartitionID) (*PartitionItem, error)
+	GetPartitionServiceName(partitionID PartitionID) (*NameInfo, error)
+}
+
+// ReplicaReader reads the replicas and instances of partitions
+type ReplicaReader interface {
+	GetInstances(appName, serviceName, partitionName string) (*InstanceItemsPage, error)
+	GetInstancesWithOptions(appName, serviceName, partitionName string, opts ListOptions) (*InstanceItemsPage, error)
+	GetReplicas(appName, serviceName, partitionName string) (*ReplicaItemsPage, error)
+	GetReplicasWithOptions(appName, serviceName, partitionName string, opts ListOptions) (*ReplicaItemsPage, error)
+	GetPartitionMembers(appName, serviceName, partitionName string) ([]PartitionMember, error)
+	GetPartitionMembersWithOptions(appName, serviceName, partitionName string, opts ListOptions) ([]PartitionMember, error)
+	GetPartitionMembersByID(partitionID PartitionID) ([]PartitionMember, error)
+	GetPrimary(ctx context.Context, appName, serviceName, partitionName string) (*ReplicaItem, error)
+}
+
+// PropertyReader reads Naming properties and the labels built from them
+type PropertyReader interface {
+	GetProperties(name string) (bool, map[string]string, error)
+	GetProperty(name, propertyName string) (*Property, error)
+	GetServiceLabels(service *ServiceItem, app *ApplicationItem, prefix string) (map[string]string, error)
+}
+
+// LabelReader reads the manifests, service groups and
+// properties labels are resolved from
+type LabelReader interface {
+	ServiceReader
+	PropertyReader
+}
+
+// PropertyWriter sets Naming properties
+type PropertyWriter interface {
+	PutProperty(name, propertyName, value string) error
+}
+
+// EventReader reads events from the EventStore
+type EventReader interface {
+	GetClusterEvents(opts EventsOptions) ([]Event, error)
+	GetNodesEvents(opts EventsOptions) ([]Event, error)
+	GetNodeEvents(nodeName string, opts EventsOptions) ([]Event, error)
+	GetApplicationsEvents(opts EventsOptions) ([]Event, error)
+	GetApplicationEvents(applicationID ApplicationID, opts EventsOptions) ([]Event, error)
+	GetServicesEvents(opts EventsOptions) ([]Event, error)
+	GetServiceEvents(serviceID ServiceID, opts EventsOptions) ([]Event, error)
+	GetPartitionsEvents(opts EventsOptions) ([]Event, error)
+	GetPartitionEvents(partitionID PartitionID, opts EventsOptions) ([]Event, error)
+	GetPartitionReplicasEvents(partitionID PartitionID, opts EventsOptions) ([]Event, error)
+	GetPartitionReplicaEvents(partitionID PartitionID, replicaID string, opts EventsOptions) ([]Event, error)
+}
+
+// BackupReader reads backup policies, backups and their progress
+type BackupReader interface {
+	GetBackupPolicy(policyName string) (*BackupPolicyDescription, error)
+	GetPartitionBackupList(partitionID PartitionID, opts BackupListOptions) ([]BackupInfo, error)
+	GetPartitionBackupProgress(partitionID PartitionID) (*BackupProgressInfo, error)
+	GetPartitionRestoreProgress(partitionID PartitionID) (*RestoreProgressInfo, error)
+	WaitForBackup(ctx context.Context, partitionID PartitionID) (*BackupProgressInfo, error)
+	WaitForRestore(ctx context.Context, partitionID PartitionID) (*RestoreProgressInfo, error)
+}
+
+// BackupWriter creates backup policies, backs up and restores partitions
+type BackupWriter interface {
+	CreateBackupPolicy(policy BackupPolicyDescription) error
+	EnableApplicationBackup(applicationID ApplicationID, policyName string) error
+	EnableServiceBackup(serviceID ServiceID, policyName string) error
+	EnablePartitionBackup(partitionID PartitionID, policyName string) error
+	BackupPartition(partitionID PartitionID, opts BackupPartitionOptions) error
+	RestorePartition(partitionID PartitionID, restore RestorePartitionDescription) error
+}
+
+// ChaosReader reads the status, events and schedule of Chaos
+type ChaosReader interface {
+	GetChaos() (*ChaosInfo, error)
+	GetChaosEvents(opts ChaosEventsOptions) ([]ChaosEvent, error)
+	GetChaosSchedule() (*ChaosScheduleDescription, error)
+}
+
+// ChaosWriter starts, stops and schedules Chaos
+type ChaosWriter interface {
+	StartChaos(params ChaosParameters) error
+	StopChaos() error
+	PostChaosSchedule(schedule ChaosScheduleDescription) error
+	RunChaos(ctx context.Context, params ChaosParameters, duration time.Duration) (*ChaosSummary, error)
+}
+
+// FaultReader reads the progress of fault operations
+type FaultReader interface {
+	GetDataLossProgress(serviceID ServiceID, partitionID PartitionID, operationID OperationID) (*PartitionDataLossProgress, error)
+	GetQuorumLossProgress(serviceID ServiceID, partitionID PartitionID, operationID OperationID) (*PartitionQuorumLossProgress, error)
+	GetPartitionRestartProgress(serviceID ServiceID, partitionID PartitionID, operationID OperationID) (*PartitionRestartProgress, error)
+	GetNodeTransitionProgress(nodeName string, operationID OperationID) (*NodeTransitionProgress, error)
+}
+
+// FaultWriter injects faults into partitions, nodes and replicas
+type FaultWriter interface {
+	StartDataLoss(serviceID ServiceID, partitionID PartitionID, mode DataLossMode) (*FaultOperation, error)
+	StartQuorumLoss(serviceID ServiceID, partitionID PartitionID, mode QuorumLossMode, duration time.Duration) (*FaultOperation, error)
+	StartPartitionRestart(serviceID ServiceID, partitionID PartitionID, mode RestartPartitionMode) (*FaultOperation, error)
+	StartNodeTransition(nodeName, nodeInstanceID string, transition NodeTransitionType, stopDuration time.Duration) (*FaultOperation, error)
+	CancelOperation(operationID OperationID, force bool) error
+	RestartReplica(nodeName string, partitionID PartitionID, replicaID string) error
+	RemoveReplica(nodeName string, partitionID PartitionID, replicaID string, force bool) error
+}
+
+// RepairReader reads repair tasks
+type RepairReader interface {
+	GetRepairTaskList(opts RepairTaskListOptions) ([]RepairTask, error)
+}
+
+// RepairWriter creates and updates repair tasks
+type RepairWriter interface {
+	CreateRepairTask(task RepairTask) (string, error)
+	CancelRepairTask(taskID, version string, requestAbort bool) (string, error)
+	ForceApproveRepairTask(taskID, version string) (string, error)
+	UpdateRepairExecutionState(task RepairTask) (string, error)
+	DeleteRepairTask(taskID, version string) error
+}
+
+var _ API = (*Client)(nil)
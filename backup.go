@@ -0,0 +1,642 @@
+package servicefabric
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Backup storage kinds as returned in the StorageKind
+// field of a backup storage description
+const (
+	BackupStorageKindAzureBlobStore                = "AzureBlobStore"
+	BackupStorageKindFileShare                     = "FileShare"
+	BackupStorageKindManagedIdentityAzureBlobStore = "ManagedIdentityAzureBlobStore"
+)
+
+// Backup schedule kinds as returned in the ScheduleKind
+// field of a backup schedule description
+const (
+	BackupScheduleKindFrequencyBased = "FrequencyBased"
+	BackupScheduleKindTimeBased      = "TimeBased"
+)
+
+// BackupState the state of a partition backup
+type BackupState string
+
+// Backup states as returned by the Service Fabric API
+const (
+	BackupStateInvalid    BackupState = "Invalid"
+	BackupStateAccepted   BackupState = "Accepted"
+	BackupStateInProgress BackupState = "BackupInProgress"
+	BackupStateSuccess    BackupState = "Success"
+	BackupStateFailure    BackupState = "Failure"
+	BackupStateTimeout    BackupState = "Timeout"
+)
+
+// RestoreState the state of a partition restore
+type RestoreState string
+
+// Restore states as returned by the Service Fabric API
+const (
+	RestoreStateInvalid    RestoreState = "Invalid"
+	RestoreStateAccepted   RestoreState = "Accepted"
+	RestoreStateInProgress RestoreState = "RestoreInProgress"
+	RestoreStateSuccess    RestoreState = "Success"
+	RestoreStateFailure    RestoreState = "Failure"
+	RestoreStateTimeout    RestoreState = "Timeout"
+)
+
+// ErrBackupFailed is returned when a backup or restore
+// awaited by WaitForBackup or WaitForRestore fails or times out
+var ErrBackupFailed = errors.New("backup operation failed")
+
+const (
+	backupPollInitialInterval = time.Second
+	backupPollMaxInterval     = 10 * time.Second
+)
+
+// BackupStorageDescription is implemented by every
+// storage a backup can be saved to or restored from
+type BackupStorageDescription interface {
+	StorageKind() string
+}
+
+// AzureBlobBackupStorageDescription stores backups in an
+// Azure blob container authenticated by connection string
+type AzureBlobBackupStorageDescription struct {
+	FriendlyName     string `json:"FriendlyName,omitempty"`
+	ConnectionString string `json:"ConnectionString"`
+	ContainerName    string `json:"ContainerName"`
+}
+
+// StorageKind returns the backup storage kind
+func (AzureBlobBackupStorageDescription) StorageKind() string {
+	return BackupStorageKindAzureBlobStore
+}
+
+// MarshalJSON encodes the storage along with its StorageKind field
+func (d AzureBlobBackupStorageDescription) MarshalJSON() ([]byte, error) {
+	type storage AzureBlobBackupStorageDescription
+	return json.Marshal(struct {
+		StorageKind string `json:"StorageKind"`
+		storage
+	}{d.StorageKind(), storage(d)})
+}
+
+// FileShareBackupStorageDescription stores backups in a file share
+type FileShareBackupStorageDescription struct {
+	FriendlyName      string `json:"FriendlyName,omitempty"`
+	Path              string `json:"Path"`
+	PrimaryUserName   string `json:"PrimaryUserName,omitempty"`
+	PrimaryPassword   string `json:"PrimaryPassword,omitempty"`
+	SecondaryUserName string `json:"SecondaryUserName,omitempty"`
+	SecondaryPassword string `json:"SecondaryPassword,omitempty"`
+}
+
+// StorageKind returns the backup storage kind
+func (FileShareBackupStorageDescription) StorageKind() string {
+	return BackupStorageKindFileShare
+}
+
+// MarshalJSON encodes the storage along with its StorageKind field
+func (d FileShareBackupStorageDescription) MarshalJSON() ([]byte, error) {
+	type storage FileShareBackupStorageDescription
+	return json.Marshal(struct {
+		StorageKind string `json:"StorageKind"`
+		storage
+	}{d.StorageKind(), storage(d)})
+}
+
+// ManagedIdentityAzureBlobBackupStorageDescription stores backups in
+// an Azure blob container authenticated by a managed identity
+type ManagedIdentityAzureBlobBackupStorageDescription struct {
+	FriendlyName string `json:"FriendlyName,omitempty"`
+	// ManagedIdentityType VMSS or Cluster
+	ManagedIdentityType string `json:"ManagedIdentityType"`
+	BlobServiceURI      string `json:"BlobServiceUri"`
+	ContainerName       string `json:"ContainerName"`
+}
+
+// StorageKind returns the backup storage kind
+func (ManagedIdentityAzureBlobBackupStorageDescription) StorageKind() string {
+	return BackupStorageKindManagedIdentityAzureBlobStore
+}
+
+// MarshalJSON encodes the storage along with its StorageKind field
+func (d ManagedIdentityAzureBlobBackupStorageDescription) MarshalJSON() ([]byte, error) {
+	type storage ManagedIdentityAzureBlobBackupStorageDescription
+	return json.Marshal(struct {
+		StorageKind string `json:"StorageKind"`
+		storage
+	}{d.StorageKind(), storage(d)})
+}
+
+// UnknownBackupStorageDescription holds a backup storage whose
+// kind is not known to this client so it is not lost on decode
+type UnknownBackupStorageDescription struct {
+	Kind string
+	Raw  json.RawMessage
+}
+
+// StorageKind returns the backup storage kind
+func (d UnknownBackupStorageDescription) StorageKind() string {
+	return d.Kind
+}
+
+// MarshalJSON returns the storage as it was decoded
+func (d UnknownBackupStorageDescription) MarshalJSON() ([]byte, error) {
+	return d.Raw, nil
+}
+
+// BackupScheduleDescription is implemented by every
+// schedule a backup policy can take backups on
+type BackupScheduleDescription interface {
+	ScheduleKind() string
+}
+
+// FrequencyBasedBackupScheduleDescription takes a backup every Interval
+type FrequencyBasedBackupScheduleDescription struct {
+	// Interval an ISO 8601 duration such as PT1H
+	Interval string `json:"Interval"`
+}
+
+// ScheduleKind returns the backup schedule kind
+func (FrequencyBasedBackupScheduleDescription) ScheduleKind() string {
+	return BackupScheduleKindFrequencyBased
+}
+
+// MarshalJSON encodes the schedule along with its ScheduleKind field
+func (d FrequencyBasedBackupScheduleDescription) MarshalJSON() ([]byte, error) {
+	type schedule FrequencyBasedBackupScheduleDescription
+	return json.Marshal(struct {
+		ScheduleKind string `json:"ScheduleKind"`
+		schedule
+	}{d.ScheduleKind(), schedule(d)})
+}
+
+// TimeBasedBackupScheduleDescription takes backups at
+// fixed times of the day on a daily or weekly basis
+type TimeBasedBackupScheduleDescription struct {
+	// ScheduleFrequencyType Daily or Weekly
+	ScheduleFrequencyType string `json:"ScheduleFrequencyType"`
+	// RunDays the days of the week of a Weekly schedule
+	RunDays []string `json:"RunDays,omitempty"`
+	// RunTimes ISO 8601 times of the day such as 0001-01-01T09:00:00Z
+	RunTimes []string `json:"RunTimes"`
+}
+
+// ScheduleKind returns the backup schedule kind
+func (TimeBasedBackupScheduleDescription) ScheduleKind() string {
+	return BackupScheduleKindTimeBased
+}
+
+// MarshalJSON encodes the schedule along with its ScheduleKind field
+func (d TimeBasedBackupScheduleDescription) MarshalJSON() ([]byte, error) {
+	type schedule TimeBasedBackupScheduleDescription
+	return json.Marshal(struct {
+		ScheduleKind string `json:"ScheduleKind"`
+		schedule
+	}{d.ScheduleKind(), schedule(d)})
+}
+
+// UnknownBackupScheduleDescription holds a backup schedule whose
+// kind is not known to this client so it is not lost on decode
+type UnknownBackupScheduleDescription struct {
+	Kind string
+	Raw  json.RawMessage
+}
+
+// ScheduleKind returns the backup schedule kind
+func (d UnknownBackupScheduleDescription) ScheduleKind() string {
+	return d.Kind
+}
+
+// MarshalJSON returns the schedule as it was decoded
+func (d UnknownBackupScheduleDescription) MarshalJSON() ([]byte, error) {
+	return d.Raw, nil
+}
+
+// RetentionPolicyDescription describes how long backups are kept
+type RetentionPolicyDescription struct {
+	// RetentionPolicyType Basic is the only supported type
+	RetentionPolicyType string `json:"RetentionPolicyType"`
+	// RetentionDuration an ISO 8601 duration such as P30D
+	RetentionDuration      string `json:"RetentionDuration"`
+	MinimumNumberOfBackups int64  `json:"MinimumNumberOfBackups,omitempty"`
+}
+
+// BackupPolicyDescription describes a backup policy
+type BackupPolicyDescription struct {
+	Name                  string                      `json:"Name"`
+	AutoRestoreOnDataLoss bool                        `json:"AutoRestoreOnDataLoss"`
+	MaxIncrementalBackups int64                       `json:"MaxIncrementalBackups"`
+	Schedule              BackupScheduleDescription   `json:"Schedule"`
+	Storage               BackupStorageDescription    `json:"Storage"`
+	RetentionPolicy       *RetentionPolicyDescription `json:"RetentionPolicy,omitempty"`
+}
+
+// UnmarshalJSON decodes the schedule and storage
+// of the policy based on their kind fields
+func (d *BackupPolicyDescription) UnmarshalJSON(data []byte) error {
+	type policy BackupPolicyDescription
+	var raw struct {
+		policy
+		Schedule json.RawMessage `json:"Schedule"`
+		Storage  json.RawMessage `json:"Storage"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*d = BackupPolicyDescription(raw.policy)
+	var err error
+	if len(raw.Schedule) > 0 && string(raw.Schedule) != "null" {
+		d.Schedule, err = unmarshalBackupSchedule(raw.Schedule)
+		if err != nil {
+			return err
+		}
+	}
+	if len(raw.Storage) > 0 && string(raw.Storage) != "null" {
+		d.Storage, err = unmarshalBackupStorage(raw.Storage)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackupEpoch the epoch of the last record in a backup
+type BackupEpoch struct {
+	ConfigurationNumber string `json:"ConfigurationNumber"`
+	DataLossNumber      string `json:"DataLossNumber"`
+}
+
+// FabricErrorDetail an error reported by Service Fabric
+type FabricErrorDetail struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// BackupInfo describes a backup of a partition
+type BackupInfo struct {
+	BackupID                string               `json:"BackupId"`
+	BackupChainID           string               `json:"BackupChainId"`
+	ApplicationName         string               `json:"ApplicationName"`
+	ServiceName             string               `json:"ServiceName"`
+	PartitionInformation    PartitionInformation `json:"PartitionInformation"`
+	BackupLocation          string               `json:"BackupLocation"`
+	BackupType              string               `json:"BackupType"`
+	EpochOfLastBackupRecord BackupEpoch          `json:"EpochOfLastBackupRecord"`
+	LsnOfLastBackupRecord   string               `json:"LsnOfLastBackupRecord"`
+	CreationTimeUtc         time.Time            `json:"CreationTimeUtc"`
+	ServiceManifestVersion  string               `json:"ServiceManifestVersion"`
+	FailureError            *FabricErrorDetail   `json:"FailureError"`
+}
+
+// BackupInfoPage encapsulates the response model
+// for backups in the Service Fabric API
+type BackupInfoPage struct {
+	ContinuationToken *string      `json:"ContinuationToken"`
+	Items             []BackupInfo `json:"Items"`
+}
+
+// BackupProgressInfo describes the progress of a partition backup
+type BackupProgressInfo struct {
+	BackupState             BackupState        `json:"BackupState"`
+	TimeStampUtc            time.Time          `json:"TimeStampUtc"`
+	BackupID                string             `json:"BackupId"`
+	BackupLocation          string             `json:"BackupLocation"`
+	EpochOfLastBackupRecord BackupEpoch        `json:"EpochOfLastBackupRecord"`
+	LsnOfLastBackupRecord   string             `json:"LsnOfLastBackupRecord"`
+	FailureError            *FabricErrorDetail `json:"FailureError"`
+}
+
+// RestoreProgressInfo describes the progress of a partition restore
+type RestoreProgressInfo struct {
+	RestoreState  RestoreState       `json:"RestoreState"`
+	TimeStampUtc  time.Time          `json:"TimeStampUtc"`
+	RestoredEpoch BackupEpoch        `json:"RestoredEpoch"`
+	RestoredLsn   string             `json:"RestoredLsn"`
+	FailureError  *FabricErrorDetail `json:"FailureError"`
+}
+
+// BackupListOptions selects the backups returned by GetPartitionBackupList
+type BackupListOptions struct {
+	// Latest only return the latest backup
+	Latest bool
+	// StartTime only return backups taken at or after StartTime
+	StartTime time.Time
+	// EndTime only return backups taken at or before EndTime
+	EndTime time.Time
+}
+
+func (o BackupListOptions) queryParams() queryParamsFunc {
+	var params []queryParamsFunc
+	if o.Latest {
+		params = append(params, withParam("Latest", "true"))
+	}
+	if !o.StartTime.IsZero() {
+		params = append(params, withParam("StartDateTimeFilter", o.StartTime.UTC().Format(time.RFC3339)))
+	}
+	if !o.EndTime.IsZero() {
+		params = append(params, withParam("EndDateTimeFilter", o.EndTime.UTC().Format(time.RFC3339)))
+	}
+	return withParams(params...)
+}
+
+// BackupPartitionOptions configures a backup taken by BackupPartition
+type BackupPartitionOptions struct {
+	// Storage overrides the storage of the partition's backup policy
+	Storage BackupStorageDescription
+	// Timeout how long the cluster waits for the backup, rounded
+	// up to whole minutes, zero leaves the timeout up to the cluster
+	Timeout time.Duration
+}
+
+// RestorePartitionDescription describes the backup a partition is restored from
+type RestorePartitionDescription struct {
+	BackupID       string `json:"BackupId"`
+	BackupLocation string `json:"BackupLocation"`
+	// BackupStorage overrides the storage of the partition's backup policy
+	BackupStorage BackupStorageDescription `json:"BackupStorage,omitempty"`
+	// Timeout how long the cluster waits for the restore, rounded
+	// up to whole minutes, zero leaves the timeout up to the cluster
+	Timeout time.Duration `json:"-"`
+}
+
+// CreateBackupPolicy creates a backup policy which can then be
+// enabled on applications, services or partitions by its name.
+func (c Client) CreateBackupPolicy(policy BackupPolicyDescription) error {
+	_, err := c.postHTTP(opCreateBackupPolicy, "BackupRestore/BackupPolicies/$/Create", policy)
+	return err
+}
+
+// GetBackupPolicy returns the backup policy named policyName.
+func (c Client) GetBackupPolicy(policyName string) (*BackupPolicyDescription, error) {
+	res, err := c.getHTTP(opGetBackupPolicy, "BackupRestore/BackupPolicies/"+escapeSegment(policyName))
+	if err != nil {
+		return nil, err
+	}
+
+	var policy BackupPolicyDescription
+	err = json.Unmarshal(res, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &policy, nil
+}
+
+// EnableApplicationBackup periodically backs up every partition of
+// the application identified by applicationID using the named policy.
+func (c Client) EnableApplicationBackup(applicationID ApplicationID, policyName string) error {
+	return c.enableBackup(opEnableApplicationBackup, "Applications/"+escapeSegment(applicationID.String()), policyName)
+}
+
+// EnableServiceBackup periodically backs up every partition of
+// the service identified by serviceID using the named policy.
+func (c Client) EnableServiceBackup(serviceID ServiceID, policyName string) error {
+	return c.enableBackup(opEnableServiceBackup, "Services/"+escapeSegment(serviceID.String()), policyName)
+}
+
+// EnablePartitionBackup periodically backs up the partition
+// identified by partitionID using the named policy.
+func (c Client) EnablePartitionBackup(partitionID PartitionID, policyName string) error {
+	return c.enableBackup(opEnablePartitionBackup, "Partitions/"+escapeSegment(partitionID.String()), policyName)
+}
+
+func (c Client) enableBackup(op operation, basePath, policyName string) error {
+	body := struct {
+		BackupPolicyName string `json:"BackupPolicyName"`
+	}{policyName}
+	_, err := c.postHTTP(op, basePath+"/$/EnableBackup", body)
+	return err
+}
+
+// GetPartitionBackupList returns the backups of the partition
+// identified by partitionID selected by opts.
+func (c Client) GetPartitionBackupList(partitionID PartitionID, opts BackupListOptions) ([]BackupInfo, error) {
+	var backups []BackupInfo
+	var continueToken string
+	for {
+		res, err := c.getHTTP(opGetPartitionBackupList, "Partitions/"+escapeSegment(partitionID.String())+"/$/GetBackups", withContinue(continueToken), opts.queryParams())
+		if err != nil {
+			return nil, err
+		}
+
+		var backupPage BackupInfoPage
+		err = json.Unmarshal(res, &backupPage)
+		if err != nil {
+			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+		}
+		backups = append(backups, backupPage.Items...)
+
+		continueToken = getString(backupPage.ContinuationToken)
+		c.logPage(opGetPartitionBackupList, len(backupPage.Items), continueToken)
+		if continueToken == "" {
+			break
+		}
+	}
+	return backups, nil
+}
+
+// BackupPartition starts a backup of the partition identified by
+// partitionID. Use GetPartitionBackupProgress or WaitForBackup to
+// follow the backup.
+func (c Client) BackupPartition(partitionID PartitionID, opts BackupPartitionOptions) error {
+	var body interface{}
+	if opts.Storage != nil {
+		body = struct {
+			BackupStorage BackupStorageDescription `json:"BackupStorage"`
+		}{opts.Storage}
+	}
+	_, err := c.postHTTP(opBackupPartition, "Partitions/"+escapeSegment(partitionID.String())+"/$/Backup", body, withTimeoutMinutes("BackupTimeout", opts.Timeout))
+	return err
+}
+
+// GetPartitionBackupProgress returns the progress of the latest
+// backup of the partition identified by partitionID.
+func (c Client) GetPartitionBackupProgress(partitionID PartitionID) (*BackupProgressInfo, error) {
+	res, err := c.getHTTP(opGetPartitionBackupProgress, "Partitions/"+escapeSegment(partitionID.String())+"/$/GetBackupProgress")
+	if err != nil {
+		return nil, err
+	}
+
+	var progress BackupProgressInfo
+	err = json.Unmarshal(res, &progress)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &progress, nil
+}
+
+// RestorePartition starts restoring the partition identified by
+// partitionID from a backup. Use GetPartitionRestoreProgress or
+// WaitForRestore to follow the restore.
+func (c Client) RestorePartition(partitionID PartitionID, restore RestorePartitionDescription) error {
+	_, err := c.postHTTP(opRestorePartition, "Partitions/"+escapeSegment(partitionID.String())+"/$/Restore", restore, withTimeoutMinutes("RestoreTimeout", restore.Timeout))
+	return err
+}
+
+// GetPartitionRestoreProgress returns the progress of the latest
+// restore of the partition identified by partitionID.
+func (c Client) GetPartitionRestoreProgress(partitionID PartitionID) (*RestoreProgressInfo, error) {
+	res, err := c.getHTTP(opGetPartitionRestoreProgress, "Partitions/"+escapeSegment(partitionID.String())+"/$/GetRestoreProgress")
+	if err != nil {
+		return nil, err
+	}
+
+	var progress RestoreProgressInfo
+	err = json.Unmarshal(res, &progress)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &progress, nil
+}
+
+// WaitForBackup polls the backup progress of the partition identified
+// by partitionID until the backup succeeds, fails or ctx is done.
+// A failed or timed out backup returns its progress along with
+// ErrBackupFailed.
+func (c Client) WaitForBackup(ctx context.Context, partitionID PartitionID) (*BackupProgressInfo, error) {
+	var progress *BackupProgressInfo
+	err := pollUntil(ctx, backupPollInitialInterval, backupPollMaxInterval, func() (bool, error) {
+		var err error
+		progress, err = c.WithContext(ctx).GetPartitionBackupProgress(partitionID)
+		if err != nil {
+			// A request cancelled with ctx is reported as ctx's error
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+		switch progress.BackupState {
+		case BackupStateSuccess, BackupStateFailure, BackupStateTimeout:
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return progress, err
+	}
+
+	if progress.BackupState != BackupStateSuccess {
+		return progress, fmt.Errorf("%w: backup of partition %s: %s", ErrBackupFailed, partitionID, failureReason(string(progress.BackupState), progress.FailureError))
+	}
+	return progress, nil
+}
+
+// WaitForRestore polls the restore progress of the partition identified
+// by partitionID until the restore succeeds, fails or ctx is done.
+// A failed or timed out restore returns its progress along with
+// ErrBackupFailed.
+func (c Client) WaitForRestore(ctx context.Context, partitionID PartitionID) (*RestoreProgressInfo, error) {
+	var progress *RestoreProgressInfo
+	err := pollUntil(ctx, backupPollInitialInterval, backupPollMaxInterval, func() (bool, error) {
+		var err error
+		progress, err = c.WithContext(ctx).GetPartitionRestoreProgress(partitionID)
+		if err != nil {
+			// A request cancelled with ctx is reported as ctx's error
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+		switch progress.RestoreState {
+		case RestoreStateSuccess, RestoreStateFailure, RestoreStateTimeout:
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return progress, err
+	}
+
+	if progress.RestoreState != RestoreStateSuccess {
+		return progress, fmt.Errorf("%w: restore of partition %s: %s", ErrBackupFailed, partitionID, failureReason(string(progress.RestoreState), progress.FailureError))
+	}
+	return progress, nil
+}
+
+func failureReason(state string, failure *FabricErrorDetail) string {
+	if failure == nil {
+		return state
+	}
+	return state + ": " + failure.Code + " " + failure.Message
+}
+
+func unmarshalBackupStorage(raw json.RawMessage) (BackupStorageDescription, error) {
+	var header struct {
+		StorageKind string `json:"StorageKind"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("could not deserialise backup storage: %+v", err)
+	}
+
+	var (
+		storage BackupStorageDescription
+		err     error
+	)
+	switch header.StorageKind {
+	case BackupStorageKindAzureBlobStore:
+		var v AzureBlobBackupStorageDescription
+		err = json.Unmarshal(raw, &v)
+		storage = v
+	case BackupStorageKindFileShare:
+		var v FileShareBackupStorageDescription
+		err = json.Unmarshal(raw, &v)
+		storage = v
+	case BackupStorageKindManagedIdentityAzureBlobStore:
+		var v ManagedIdentityAzureBlobBackupStorageDescription
+		err = json.Unmarshal(raw, &v)
+		storage = v
+	default:
+		storage = UnknownBackupStorageDescription{Kind: header.StorageKind, Raw: raw}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise %s backup storage: %+v", header.StorageKind, err)
+	}
+	return storage, nil
+}
+
+func unmarshalBackupSchedule(raw json.RawMessage) (BackupScheduleDescription, error) {
+	var header struct {
+		ScheduleKind string `json:"ScheduleKind"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("could not deserialise backup schedule: %+v", err)
+	}
+
+	var (
+		schedule BackupScheduleDescription
+		err      error
+	)
+	switch header.ScheduleKind {
+	case BackupScheduleKindFrequencyBased:
+		var v FrequencyBasedBackupScheduleDescription
+		err = json.Unmarshal(raw, &v)
+		schedule = v
+	case BackupScheduleKindTimeBased:
+		var v TimeBasedBackupScheduleDescription
+		err = json.Unmarshal(raw, &v)
+		schedule = v
+	default:
+		schedule = UnknownBackupScheduleDescription{Kind: header.ScheduleKind, Raw: raw}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise %s backup schedule: %+v", header.ScheduleKind, err)
+	}
+	return schedule, nil
+}
+
+// withTimeoutMinutes sets a timeout parameter measured in whole minutes
+func withTimeoutMinutes(name string, timeout time.Duration) queryParamsFunc {
+	if timeout <= 0 {
+		return noOp
+	}
+	minutes := int64((timeout + time.Minute - 1) / time.Minute)
+	return withParam(name, strconv.FormatInt(minutes, 10))
+}
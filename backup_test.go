@@ -0,0 +1,178 @@
+package servicefabric
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testPartitionID PartitionID = "bce46a8c-b62d-4996-89dc-7ffc00a96902"
+
+func TestBackupPolicy(t *testing.T) {
+	gateway := newFakeBackupGateway()
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	expected := BackupPolicyDescription{
+		Name:                  "DailyAzure",
+		AutoRestoreOnDataLoss: true,
+		MaxIncrementalBackups: 5,
+		Schedule:              FrequencyBasedBackupScheduleDescription{Interval: "PT1H"},
+		Storage: AzureBlobBackupStorageDescription{
+			ConnectionString: "DefaultEndpointsProtocol=https;AccountName=test",
+			ContainerName:    "backups",
+		},
+		RetentionPolicy: &RetentionPolicyDescription{RetentionPolicyType: "Basic", RetentionDuration: "P30D"},
+	}
+	if err := sfClient.CreateBackupPolicy(expected); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	actual, err := sfClient.GetBackupPolicy("DailyAzure")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !reflect.DeepEqual(*actual, expected) {
+		t.Errorf("Got %+v, want %+v", *actual, expected)
+	}
+
+	if err := sfClient.EnableApplicationBackup("TestApplication", "DailyAzure"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := sfClient.EnableServiceBackup("TestApplication~TestService", "DailyAzure"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := sfClient.EnablePartitionBackup(testPartitionID, "DailyAzure"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := sfClient.EnablePartitionBackup(testPartitionID, "Missing"); err == nil {
+		t.Error("Expected an error enabling a missing backup policy")
+	}
+
+	expectedEnabled := map[string]string{
+		"Applications/TestApplication":                    "DailyAzure",
+		"Services/TestApplication~TestService":            "DailyAzure",
+		"Partitions/bce46a8c-b62d-4996-89dc-7ffc00a96902": "DailyAzure",
+	}
+	if !reflect.DeepEqual(gateway.enabled, expectedEnabled) {
+		t.Errorf("Got %v, want %v", gateway.enabled, expectedEnabled)
+	}
+	if gateway.queries[0] != "api-version=6.4" {
+		t.Errorf("Got %s, want api-version=6.4", gateway.queries[0])
+	}
+}
+
+func TestBackupAndRestorePartition(t *testing.T) {
+	gateway := newFakeBackupGateway()
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	storage := FileShareBackupStorageDescription{Path: `\\fileserver\backups`, PrimaryUserName: "backup"}
+	err := sfClient.BackupPartition(testPartitionID, BackupPartitionOptions{Storage: storage, Timeout: 90 * time.Second})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if gateway.queries[0] != "api-version=6.4&BackupTimeout=2" {
+		t.Errorf("Got %s, want api-version=6.4&BackupTimeout=2", gateway.queries[0])
+	}
+	if !reflect.DeepEqual(gateway.storage, storage) {
+		t.Errorf("Got %+v, want %+v", gateway.storage, storage)
+	}
+
+	backup, err := sfClient.WaitForBackup(ctx, testPartitionID)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if backup.BackupID != "backup-bce46a8c-b62d-4996-89dc-7ffc00a96902" {
+		t.Errorf("Got %s, want backup-bce46a8c-b62d-4996-89dc-7ffc00a96902", backup.BackupID)
+	}
+
+	backups, err := sfClient.GetPartitionBackupList(testPartitionID, BackupListOptions{Latest: true})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(backups) != 1 || backups[0].BackupID != backup.BackupID {
+		t.Fatalf("Got %+v, want the latest backup", backups)
+	}
+
+	restore := RestorePartitionDescription{BackupID: backups[0].BackupID, BackupLocation: backups[0].BackupLocation}
+	if err := sfClient.RestorePartition(testPartitionID, restore); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	restored, err := sfClient.WaitForRestore(ctx, testPartitionID)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if restored.RestoredLsn != "1234" {
+		t.Errorf("Got %s, want 1234", restored.RestoredLsn)
+	}
+
+	gateway.failRestore = true
+	if err := sfClient.RestorePartition(testPartitionID, restore); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	failed, err := sfClient.WaitForRestore(ctx, testPartitionID)
+	if !errors.Is(err, ErrBackupFailed) {
+		t.Errorf("Got %v, want %v", err, ErrBackupFailed)
+	}
+	if failed == nil || failed.RestoreState != RestoreStateFailure {
+		t.Errorf("Got %+v, want the failed restore progress", failed)
+	}
+}
+
+func TestBackupStorageJSON(t *testing.T) {
+	testCases := []struct {
+		storage  BackupStorageDescription
+		expected string
+	}{
+		{
+			storage:  AzureBlobBackupStorageDescription{ConnectionString: "conn", ContainerName: "backups"},
+			expected: `{"StorageKind":"AzureBlobStore","ConnectionString":"conn","ContainerName":"backups"}`,
+		},
+		{
+			storage:  FileShareBackupStorageDescription{FriendlyName: "share", Path: `\\server\share`},
+			expected: `{"StorageKind":"FileShare","FriendlyName":"share","Path":"\\\\server\\share"}`,
+		},
+		{
+			storage:  ManagedIdentityAzureBlobBackupStorageDescription{ManagedIdentityType: "VMSS", BlobServiceURI: "https://test.blob.core.windows.net", ContainerName: "backups"},
+			expected: `{"StorageKind":"ManagedIdentityAzureBlobStore","ManagedIdentityType":"VMSS","BlobServiceUri":"https://test.blob.core.windows.net","ContainerName":"backups"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		actual, err := json.Marshal(test.storage)
+		if err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+		if string(actual) != test.expected {
+			t.Errorf("Got %s, want %s", actual, test.expected)
+		}
+
+		decoded, err := unmarshalBackupStorage(actual)
+		if err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+		if !reflect.DeepEqual(decoded, test.storage) {
+			t.Errorf("Got %+v, want %+v", decoded, test.storage)
+		}
+	}
+
+	unknown, err := unmarshalBackupStorage(json.RawMessage(`{"StorageKind":"DsmsAzureBlobStore","StorageCredentialsSourceLocation":"x"}`))
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if unknown.StorageKind() != "DsmsAzureBlobStore" {
+		t.Errorf("Got %s, want DsmsAzureBlobStore", unknown.StorageKind())
+	}
+}
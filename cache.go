@@ -0,0 +1,397 @@
+package servicefabric
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CacheOptions sets how long a CachedClient keeps each resource
+type CacheOptions struct {
+	// ApplicationsTTL how long the results of GetApplications are served from the cache
+	ApplicationsTTL time.Duration
+	// ServicesTTL how long the results of GetServices are served from the cache
+	ServicesTTL time.Duration
+	// PropertiesTTL how long the results of GetProperties are served from the cache
+	PropertiesTTL time.Duration
+	// MissingNameTTL how long GetProperties reports a name which
+	// does not exist as missing without asking the cluster again,
+	// zero does not cache missing names
+	MissingNameTTL time.Duration
+	// StaleTTL how long an expired result is still served while it is
+	// refreshed in the background. When the refresh fails, such as when
+	// the gateway cannot be reached, the expired result keeps being
+	// served until StaleTTL has passed, unless the cluster responded
+	// that the resource was not found.
+	StaleTTL time.Duration
+}
+
+// Cached resources
+const (
+	cacheApplications = "applications"
+	cacheServices     = "services"
+	cacheProperties   = "properties"
+)
+
+// cacheKey identifies a cached result by the call and its arguments
+type cacheKey struct {
+	resource string
+	name     string
+	opts     interface{}
+}
+
+// cacheScope the results invalidated together, such as the
+// services of an application whatever options they were listed with
+type cacheScope struct {
+	resource string
+	name     string
+}
+
+func (k cacheKey) scope() cacheScope {
+	return cacheScope{resource: k.resource, name: k.name}
+}
+
+// cacheGeneration changes whenever the results of a scope are
+// invalidated, a result fetched in an earlier generation is not cached
+type cacheGeneration struct {
+	all   uint64
+	scope uint64
+}
+
+// cacheEntry a cached result and when it was fetched
+type cacheEntry struct {
+	value      interface{}
+	fetched    time.Time
+	ttl        time.Duration
+	refreshing bool
+}
+
+// CachedClient is a Client which serves applications, services and
+// properties from a cache, and GetServiceLabels reads the cached
+// properties. Every other call goes to the cluster, and PutProperty
+// invalidates the cached properties of the name it changes.
+// Copies made with its With methods share its cache. A CachedClient is
+// safe for use by multiple goroutines.
+type CachedClient struct {
+	*Client
+	*responseCache
+}
+
+// responseCache the cache shared by a CachedClient and its copies
+type responseCache struct {
+	opts CacheOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+	// fetching counts the fetches in flight of each scope, invalidated
+	// the calls to InvalidateAll, and invalidations the invalidations
+	// of each scope with fetches in flight since InvalidateAll
+	fetching      map[cacheScope]int
+	invalidated   uint64
+	invalidations map[cacheScope]uint64
+}
+
+// cacheRefreshTimeout bounds a background refresh, which
+// is not tied to the context of the call that started it
+const cacheRefreshTimeout = time.Minute
+
+var _ API = (*CachedClient)(nil)
+
+// NewCachedClient returns a client caching the results of client
+// as set by opts. Results which have a TTL of zero are not cached.
+func NewCachedClient(client *Client, opts CacheOptions) *CachedClient {
+	return &CachedClient{
+		Client: client,
+		responseCache: &responseCache{
+			opts:          opts,
+			now:           time.Now,
+			entries:       make(map[cacheKey]*cacheEntry),
+			fetching:      make(map[cacheScope]int),
+			invalidations: make(map[cacheScope]uint64),
+		},
+	}
+}
+
+// WithContext returns a copy of the client whose requests are bound
+// to ctx, see Client.WithContext. The copy shares the client's cache.
+func (c *CachedClient) WithContext(ctx context.Context) *CachedClient {
+	return &CachedClient{Client: c.Client.WithContext(ctx), responseCache: c.responseCache}
+}
+
+// WithObserver returns a copy of the client whose requests are also
+// observed by observer, see Client.WithObserver. The copy shares the
+// client's cache.
+func (c *CachedClient) WithObserver(observer Observer) *CachedClient {
+	return &CachedClient{Client: c.Client.WithObserver(observer), responseCache: c.responseCache}
+}
+
+// WithLogger returns a copy of the client which logs to logger, see
+// Client.WithLogger. The copy shares the client's cache.
+func (c *CachedClient) WithLogger(logger *slog.Logger) *CachedClient {
+	return &CachedClient{Client: c.Client.WithLogger(logger), responseCache: c.responseCache}
+}
+
+// WithUnredactedLogs returns a copy of the client which logs sensitive
+// values, see Client.WithUnredactedLogs. The copy shares the client's cache.
+func (c *CachedClient) WithUnredactedLogs() *CachedClient {
+	return &CachedClient{Client: c.Client.WithUnredactedLogs(), responseCache: c.responseCache}
+}
+
+// WithRateLimit returns a copy of the client whose requests are limited
+// by limit, see Client.WithRateLimit. The copy shares the client's cache.
+func (c *CachedClient) WithRateLimit(limit RateLimit) *CachedClient {
+	return &CachedClient{Client: c.Client.WithRateLimit(limit), responseCache: c.responseCache}
+}
+
+// GetApplications returns the registered applications within the
+// Service Fabric cluster, from the cache when they are fresh enough.
+func (c *CachedClient) GetApplications() (*ApplicationItemsPage, error) {
+	return c.GetApplicationsWithOptions(ApplicationsOptions{})
+}
+
+// GetApplicationsWithOptions returns the registered applications
+// selected by opts, from the cache when they are fresh enough.
+func (c *CachedClient) GetApplicationsWithOptions(opts ApplicationsOptions) (*ApplicationItemsPage, error) {
+	key := cacheKey{resource: cacheApplications, opts: opts}
+	value, err := c.get(key, func(client *Client) (interface{}, time.Duration, error) {
+		apps, err := client.GetApplicationsWithOptions(opts)
+		return apps, c.opts.ApplicationsTTL, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apps := *value.(*ApplicationItemsPage)
+	apps.Items = append([]ApplicationItem(nil), apps.Items...)
+	return &apps, nil
+}
+
+// GetServices returns the services associated with a Service Fabric
+// application, from the cache when they are fresh enough.
+func (c *CachedClient) GetServices(appName string) (*ServiceItemsPage, error) {
+	return c.GetServicesWithOptions(appName, ServicesOptions{})
+}
+
+// GetServicesWithOptions returns the services of an application
+// selected by opts, from the cache when they are fresh enough.
+func (c *CachedClient) GetServicesWithOptions(appName string, opts ServicesOptions) (*ServiceItemsPage, error) {
+	key := cacheKey{resource: cacheServices, name: appName, opts: opts}
+	value, err := c.get(key, func(client *Client) (interface{}, time.Duration, error) {
+		services, err := client.GetServicesWithOptions(appName, opts)
+		return services, c.opts.ServicesTTL, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	services := *value.(*ServiceItemsPage)
+	services.Items = append([]ServiceItem(nil), services.Items...)
+	return &services, nil
+}
+
+// cachedProperties the result of GetProperties
+type cachedProperties struct {
+	exists     bool
+	properties map[string]string
+}
+
+// GetProperties uses the list of property names to fetch the
+// properties of a name, from the cache when they are fresh enough.
+// Names which do not exist are cached for MissingNameTTL.
+func (c *CachedClient) GetProperties(name string) (bool, map[string]string, error) {
+	key := cacheKey{resource: cacheProperties, name: name}
+	value, err := c.get(key, func(client *Client) (interface{}, time.Duration, error) {
+		exists, properties, err := client.GetProperties(name)
+		if !exists {
+			return cachedProperties{}, c.opts.MissingNameTTL, err
+		}
+		return cachedProperties{exists: true, properties: properties}, c.opts.PropertiesTTL, err
+	})
+	if err != nil {
+		return false, nil, err
+	}
+
+	cached := value.(cachedProperties)
+	if !cached.exists {
+		return false, nil, nil
+	}
+	properties := make(map[string]string, len(cached.properties))
+	for k, v := range cached.properties {
+		properties[k] = v
+	}
+	return true, properties, nil
+}
+
+// GetServiceLabels add labels from service manifest extensions and
+// properties manager, reading the properties from the cache
+//
+// Deprecated: Use LabelResolver which merges labels from the
+// manifest extension, properties and application parameters instead.
+func (c *CachedClient) GetServiceLabels(service *ServiceItem, app *ApplicationItem, prefix string) (map[string]string, error) {
+	return serviceLabels(c, service, app, prefix)
+}
+
+// PutProperty creates or updates a property of a name and
+// invalidates the cached properties of the name
+func (c *CachedClient) PutProperty(name, propertyName, value string) error {
+	err := c.Client.PutProperty(name, propertyName, value)
+	c.InvalidateProperties(name)
+	return err
+}
+
+// InvalidateApplications removes every cached list of applications
+func (c *CachedClient) InvalidateApplications() {
+	c.invalidate(cacheApplications, "")
+}
+
+// InvalidateServices removes the cached services of an application
+func (c *CachedClient) InvalidateServices(appName string) {
+	c.invalidate(cacheServices, appName)
+}
+
+// InvalidateProperties removes the cached properties of a name,
+// including that it does not exist
+func (c *CachedClient) InvalidateProperties(name string) {
+	c.invalidate(cacheProperties, name)
+}
+
+// InvalidateAll empties the cache
+func (c *CachedClient) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[cacheKey]*cacheEntry)
+	c.invalidations = make(map[cacheScope]uint64)
+	c.invalidated++
+}
+
+// invalidate removes the entries of name's resource
+func (c *CachedClient) invalidate(resource, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	scope := cacheScope{resource: resource, name: name}
+	for key := range c.entries {
+		if key.scope() == scope {
+			delete(c.entries, key)
+		}
+	}
+	// Only fetches in flight compare the invalidations of a scope
+	if c.fetching[scope] > 0 {
+		c.invalidations[scope]++
+	}
+}
+
+// generation returns the generation of key, c.mu must be held
+func (c *CachedClient) generation(key cacheKey) cacheGeneration {
+	return cacheGeneration{all: c.invalidated, scope: c.invalidations[key.scope()]}
+}
+
+// doneFetching records that a fetch of scope finished, forgetting the
+// invalidations of scope once no fetch compares them, c.mu must be held
+func (c *CachedClient) doneFetching(scope cacheScope) {
+	c.fetching[scope]--
+	if c.fetching[scope] <= 0 {
+		delete(c.fetching, scope)
+		delete(c.invalidations, scope)
+	}
+}
+
+// get returns the cached value of key. A fresh value is returned as it is.
+// An expired value within StaleTTL is returned while fetch refreshes it in
+// the background. Otherwise fetch is called with the client, and its value
+// cached for the TTL it returns unless key was invalidated while it was
+// being fetched.
+func (c *CachedClient) get(key cacheKey, fetch func(client *Client) (interface{}, time.Duration, error)) (interface{}, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	generation := c.generation(key)
+	entry, ok := c.entries[key]
+	if ok {
+		age := now.Sub(entry.fetched)
+		if age < entry.ttl {
+			c.mu.Unlock()
+			c.logHit(key, age, false)
+			return entry.value, nil
+		}
+		if age < entry.ttl+c.opts.StaleTTL {
+			if !entry.refreshing {
+				entry.refreshing = true
+				go c.refresh(key, entry, fetch)
+			}
+			c.mu.Unlock()
+			c.logHit(key, age, true)
+			return entry.value, nil
+		}
+	}
+	c.fetching[key.scope()]++
+	c.mu.Unlock()
+
+	value, ttl, err := fetch(c.Client)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil && c.generation(key) == generation {
+		c.storeLocked(key, value, ttl, now)
+	}
+	c.doneFetching(key.scope())
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// refresh fetches the value of an expired entry. The fetch is bound
+// to its own context as the call which found the entry expired may
+// have returned by then. When the cluster responds that the resource
+// is not found the entry is removed, on any other error the stale
+// entry is kept to be served until StaleTTL passes.
+func (c *CachedClient) refresh(key cacheKey, entry *cacheEntry, fetch func(client *Client) (interface{}, time.Duration, error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), cacheRefreshTimeout)
+	defer cancel()
+
+	now := c.now()
+	value, ttl, err := fetch(c.Client.WithContext(ctx))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[key] != entry {
+		// Invalidated while refreshing
+		return
+	}
+	entry.refreshing = false
+
+	if err != nil {
+		var responseErr *ResponseError
+		gone := errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound
+		if gone {
+			delete(c.entries, key)
+		}
+		c.debug("servicefabric: cache refresh failed",
+			slog.String("resource", key.resource),
+			slog.String("name", key.name),
+			slog.Bool("removed", gone))
+		return
+	}
+	c.storeLocked(key, value, ttl, now)
+}
+
+// storeLocked caches value unless its ttl is zero, c.mu must be held
+func (c *CachedClient) storeLocked(key cacheKey, value interface{}, ttl time.Duration, fetched time.Time) {
+	if ttl <= 0 {
+		delete(c.entries, key)
+		return
+	}
+	c.entries[key] = &cacheEntry{value: value, fetched: fetched, ttl: ttl}
+}
+
+func (c *CachedClient) logHit(key cacheKey, age time.Duration, stale bool) {
+	c.debug("servicefabric: cache hit",
+		slog.String("resource", key.resource),
+		slog.String("name", key.name),
+		slog.Duration("age", age),
+		slog.Bool("stale", stale))
+}
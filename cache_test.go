@@ -0,0 +1,438 @@
+package servicefabric
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheServer counts the requests handled by handler and responds
+// with status instead while status is set
+type cacheServer struct {
+	handler http.Handler
+
+	mu       sync.Mutex
+	requests int
+	status   int
+}
+
+func (s *cacheServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	status := s.status
+	s.mu.Unlock()
+
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	// Background refreshes send the server side timeout of their context
+	var params []string
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		if !strings.HasPrefix(param, "timeout=") {
+			params = append(params, param)
+		}
+	}
+	r.URL.RawQuery = strings.Join(params, "&")
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *cacheServer) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *cacheServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// fakeClock a clock which only moves when advanced
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestCachedClient(t *testing.T, handler http.Handler, opts CacheOptions) (*CachedClient, *cacheServer, *fakeClock) {
+	counter := &cacheServer{handler: handler}
+	server := httptest.NewServer(counter)
+	t.Cleanup(server.Close)
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	cached := NewCachedClient(sfClient, opts)
+	clock := &fakeClock{now: time.Now()}
+	cached.now = clock.Now
+	return cached, counter, clock
+}
+
+// waitForRefresh waits until no entry of c is being refreshed
+func waitForRefresh(t *testing.T, c *CachedClient) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		refreshing := false
+		c.mu.Lock()
+		for _, entry := range c.entries {
+			refreshing = refreshing || entry.refreshing
+		}
+		c.mu.Unlock()
+		if !refreshing {
+			return
+		}
+	}
+	t.Fatal("Timed out waiting for the cache to refresh")
+}
+
+func TestCachedClientApplications(t *testing.T) {
+	cached, server, clock := newTestCachedClient(t, http.HandlerFunc(handleApplications), CacheOptions{ApplicationsTTL: time.Minute})
+
+	apps, err := cached.GetApplications()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	apps.Items[0].Name = "changed"
+
+	clock.advance(30 * time.Second)
+	cachedApps, err := cached.GetApplications()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	// Both pages of the applications are fetched once
+	if server.count() != 2 {
+		t.Errorf("Got %d requests, want 2", server.count())
+	}
+	if len(cachedApps.Items) != 2 || cachedApps.Items[0].Name == "changed" {
+		t.Errorf("Got %+v, want the cached applications unchanged", cachedApps.Items)
+	}
+
+	clock.advance(30 * time.Second)
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != 4 {
+		t.Errorf("Got %d requests, want the expired applications fetched again", server.count())
+	}
+
+	cached.InvalidateApplications()
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != 6 {
+		t.Errorf("Got %d requests, want the invalidated applications fetched again", server.count())
+	}
+}
+
+func TestCachedClientServicesByApplication(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/Applications/TestApplication/$/GetServices", handleFixture("fixtures/services.json"))
+	mux.Handle("/Applications/OtherApplication/$/GetServices", handleFixture("fixtures/services.json"))
+	cached, server, _ := newTestCachedClient(t, mux, CacheOptions{ServicesTTL: time.Minute})
+
+	for _, appName := range []string{"TestApplication", "OtherApplication", "TestApplication", "OtherApplication"} {
+		if _, err := cached.GetServices(appName); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+	}
+	if server.count() != 2 {
+		t.Errorf("Got %d requests, want one for each application", server.count())
+	}
+
+	cached.InvalidateServices("TestApplication")
+	for _, appName := range []string{"TestApplication", "OtherApplication"} {
+		if _, err := cached.GetServices(appName); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+	}
+	if server.count() != 3 {
+		t.Errorf("Got %d requests, want only the invalidated application fetched again", server.count())
+	}
+}
+
+func TestCachedClientMissingNames(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Names/TestApplication/Missing", http.NotFound)
+	cached, server, clock := newTestCachedClient(t, mux, CacheOptions{PropertiesTTL: time.Hour, MissingNameTTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		exists, properties, err := cached.GetProperties("TestApplication/Missing")
+		if err != nil || exists || properties != nil {
+			t.Fatalf("Got %v %v %v, want the name missing", exists, properties, err)
+		}
+	}
+	if server.count() != 1 {
+		t.Errorf("Got %d requests, want the missing name cached", server.count())
+	}
+
+	clock.advance(time.Minute)
+	if _, _, err := cached.GetProperties("TestApplication/Missing"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != 2 {
+		t.Errorf("Got %d requests, want the missing name looked up after MissingNameTTL", server.count())
+	}
+}
+
+func TestCachedClientPutPropertyInvalidates(t *testing.T) {
+	mux := handleLabels().(*http.ServeMux)
+	mux.HandleFunc("/Names/TestApplication/TestService/$/GetProperty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	cached, server, _ := newTestCachedClient(t, mux, CacheOptions{PropertiesTTL: time.Minute})
+
+	exists, properties, err := cached.GetProperties("TestApplication/TestService")
+	if err != nil || !exists {
+		t.Fatalf("Got %v %v, want the properties", exists, err)
+	}
+	properties["traefik.frontend.rule"] = "changed"
+
+	_, cachedProperties, err := cached.GetProperties("TestApplication/TestService")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != 2 || cachedProperties["traefik.frontend.rule"] != "Host:${Host}" {
+		t.Errorf("Got %d requests and %v, want the cached properties unchanged", server.count(), cachedProperties)
+	}
+
+	if err := cached.PutProperty("TestApplication/TestService", "traefik.enable", "true"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if _, _, err := cached.GetProperties("TestApplication/TestService"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != 5 {
+		t.Errorf("Got %d requests, want the properties fetched again after PutProperty", server.count())
+	}
+}
+
+func TestCachedClientServiceLabels(t *testing.T) {
+	cached, server, _ := newTestCachedClient(t, handleLabels(), CacheOptions{PropertiesTTL: time.Minute})
+
+	expected := map[string]string{"enable": "true", "frontend.rule": "Host:${Host}"}
+	for i := 0; i < 2; i++ {
+		labels, err := cached.GetServiceLabels(labelTestService, labelTestApp, "traefik")
+		if err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+		if !reflect.DeepEqual(labels, expected) {
+			t.Errorf("Got %+v, want %+v", labels, expected)
+		}
+	}
+	if _, err := NewLabelResolver(cached, "traefik").Resolve(labelTestService, labelTestApp); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	// Only the manifest extension is read again
+	if server.count() != 5 {
+		t.Errorf("Got %d requests, want the properties read once", server.count())
+	}
+}
+
+func TestCachedClientForgetsInvalidations(t *testing.T) {
+	mux := handleLabels().(*http.ServeMux)
+	mux.HandleFunc("/Names/TestApplication/TestService/$/GetProperty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	cached, _, _ := newTestCachedClient(t, mux, CacheOptions{PropertiesTTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if err := cached.PutProperty("TestApplication/TestService", "traefik.enable", "true"); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+		if _, _, err := cached.GetProperties("TestApplication/TestService"); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+	}
+
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+	if len(cached.invalidations) != 0 || len(cached.fetching) != 0 {
+		t.Errorf("Got %v and %v, want no invalidations kept without fetches in flight", cached.invalidations, cached.fetching)
+	}
+}
+
+func TestCachedClientInvalidatedWhileFetching(t *testing.T) {
+	var cached *CachedClient
+	mux := handleLabels().(*http.ServeMux)
+	mux.HandleFunc("/Names/TestApplication/Written", func(w http.ResponseWriter, r *http.Request) {
+		// The name is written to after it was read
+		cached.InvalidateProperties("TestApplication/Written")
+		http.NotFound(w, r)
+	})
+	cached, server, _ := newTestCachedClient(t, mux, CacheOptions{PropertiesTTL: time.Minute, MissingNameTTL: time.Minute})
+
+	for _, name := range []string{"TestApplication/Written", "TestApplication/Written", "TestApplication/TestService"} {
+		if _, _, err := cached.GetProperties(name); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+	}
+	requests := server.count()
+	if requests != 4 {
+		t.Errorf("Got %d requests, want the properties read while invalidated not cached", requests)
+	}
+
+	// Invalidating another name leaves the properties cached
+	cached.InvalidateProperties("TestApplication/Other")
+	if _, _, err := cached.GetProperties("TestApplication/TestService"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != requests {
+		t.Errorf("Got %d requests, want the cached properties served", server.count()-requests)
+	}
+}
+
+func TestCachedClientServesStaleDuringOutage(t *testing.T) {
+	cached, server, clock := newTestCachedClient(t, http.HandlerFunc(handleApplications), CacheOptions{ApplicationsTTL: time.Minute, StaleTTL: 5 * time.Minute})
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	server.setStatus(http.StatusServiceUnavailable)
+	clock.advance(2 * time.Minute)
+	for i := 0; i < 2; i++ {
+		apps, err := cached.GetApplications()
+		if err != nil || len(apps.Items) != 2 {
+			t.Fatalf("Got %+v %v, want the stale applications", apps, err)
+		}
+		waitForRefresh(t, cached)
+	}
+	if server.count() != 4 {
+		t.Errorf("Got %d requests, want a refresh for each stale read", server.count())
+	}
+
+	clock.advance(5 * time.Minute)
+	if _, err := cached.GetApplications(); err == nil {
+		t.Error("Got nil, want an error once the applications are too stale")
+	}
+
+	server.setStatus(0)
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	clock.advance(2 * time.Minute)
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	waitForRefresh(t, cached)
+	requests := server.count()
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != requests {
+		t.Errorf("Got %d requests, want the refreshed applications served", server.count()-requests)
+	}
+}
+
+func TestCachedClientRefreshesAfterCallReturns(t *testing.T) {
+	cached, server, clock := newTestCachedClient(t, http.HandlerFunc(handleApplications), CacheOptions{ApplicationsTTL: time.Minute, StaleTTL: 5 * time.Minute})
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	// The caller's context is done by the time the refresh runs
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	clock.advance(2 * time.Minute)
+	if _, err := cached.WithContext(ctx).GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	waitForRefresh(t, cached)
+
+	requests := server.count()
+	if requests != 4 {
+		t.Errorf("Got %d requests, want the applications refreshed", requests)
+	}
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if server.count() != requests {
+		t.Errorf("Got %d requests, want the refreshed applications served", server.count()-requests)
+	}
+}
+
+func TestCachedClientDropsRemovedResources(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/Applications/TestApplication/$/GetServices", handleFixture("fixtures/services.json"))
+	cached, server, clock := newTestCachedClient(t, mux, CacheOptions{ServicesTTL: time.Minute, StaleTTL: time.Hour})
+	if _, err := cached.GetServices("TestApplication"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	server.setStatus(http.StatusNotFound)
+	clock.advance(2 * time.Minute)
+	if _, err := cached.GetServices("TestApplication"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	waitForRefresh(t, cached)
+
+	if _, err := cached.GetServices("TestApplication"); err == nil {
+		t.Error("Got nil, want the removed application's services dropped")
+	}
+}
+
+func TestCachedClientLogsHits(t *testing.T) {
+	cached, _, clock := newTestCachedClient(t, http.HandlerFunc(handleApplications), CacheOptions{ApplicationsTTL: time.Minute})
+	var buf bytes.Buffer
+	cached = cached.WithLogger(newDebugLogger(&buf))
+
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	buf.Reset()
+	clock.advance(time.Second)
+	if _, err := cached.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := `"msg":"servicefabric: cache hit","resource":"applications","name":"","age":1000000000,"stale":false`
+	if !strings.Contains(buf.String(), expected) || strings.Contains(buf.String(), "servicefabric: request") {
+		t.Errorf("Got %s, want only a cache hit", buf.String())
+	}
+}
+
+func TestCachedClientCopiesShareCache(t *testing.T) {
+	cached, server, _ := newTestCachedClient(t, http.HandlerFunc(handleApplications), CacheOptions{ApplicationsTTL: time.Minute})
+
+	observer := &recordingObserver{}
+	for _, copied := range []*CachedClient{
+		cached,
+		cached.WithContext(context.Background()),
+		cached.WithObserver(observer),
+		cached.WithLogger(nil),
+		cached.WithUnredactedLogs(),
+		cached.WithRateLimit(RateLimit{MaxInFlight: 1}),
+	} {
+		if _, err := copied.GetApplications(); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+	}
+	if server.count() != 2 || len(observer.infos) != 0 {
+		t.Errorf("Got %d requests, want the applications fetched once", server.count())
+	}
+
+	cached.WithContext(context.Background()).InvalidateApplications()
+	if _, err := cached.WithObserver(observer).GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(observer.infos) != 2 {
+		t.Errorf("Got %+v, want the copy to observe its requests", observer.infos)
+	}
+}
@@ -0,0 +1,589 @@
+package servicefabric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ChaosStatus whether Chaos is running
+type ChaosStatus string
+
+// Chaos statuses as returned by the Service Fabric API
+const (
+	ChaosStatusInvalid ChaosStatus = "Invalid"
+	ChaosStatusRunning ChaosStatus = "Running"
+	ChaosStatusStopped ChaosStatus = "Stopped"
+)
+
+// ChaosScheduleStatus the status of the Chaos schedule
+type ChaosScheduleStatus string
+
+// Chaos schedule statuses as returned by the Service Fabric API
+const (
+	ChaosScheduleStatusInvalid ChaosScheduleStatus = "Invalid"
+	ChaosScheduleStatusStopped ChaosScheduleStatus = "Stopped"
+	ChaosScheduleStatusActive  ChaosScheduleStatus = "Active"
+	ChaosScheduleStatusExpired ChaosScheduleStatus = "Expired"
+	ChaosScheduleStatusPending ChaosScheduleStatus = "Pending"
+)
+
+// ChaosEventKind the kind of a Chaos event
+type ChaosEventKind string
+
+// Chaos event kinds as returned in the Kind field of a Chaos event
+const (
+	ChaosEventKindStarted          ChaosEventKind = "Started"
+	ChaosEventKindExecutingFaults  ChaosEventKind = "ExecutingFaults"
+	ChaosEventKindWaiting          ChaosEventKind = "Waiting"
+	ChaosEventKindValidationFailed ChaosEventKind = "ValidationFailed"
+	ChaosEventKindTestError        ChaosEventKind = "TestError"
+	ChaosEventKindStopped          ChaosEventKind = "Stopped"
+)
+
+const (
+	chaosPollInitialInterval = time.Second
+	chaosPollMaxInterval     = 15 * time.Second
+
+	// fileTimeUnixEpoch the Windows file time of the Unix epoch
+	fileTimeUnixEpoch = 116444736000000000
+)
+
+// ClusterHealthPolicy the health policy Chaos validates the cluster against
+type ClusterHealthPolicy struct {
+	ConsiderWarningAsError          bool `json:"ConsiderWarningAsError"`
+	MaxPercentUnhealthyNodes        int  `json:"MaxPercentUnhealthyNodes"`
+	MaxPercentUnhealthyApplications int  `json:"MaxPercentUnhealthyApplications"`
+	// ApplicationTypeHealthPolicyMap the maximum percentage of
+	// unhealthy applications keyed by application type name
+	ApplicationTypeHealthPolicyMap map[string]int `json:"-"`
+}
+
+// ChaosTargetFilter limits the faults Chaos injects to
+// the listed node types and applications
+type ChaosTargetFilter struct {
+	NodeTypeInclusionList    []string `json:"NodeTypeInclusionList,omitempty"`
+	ApplicationInclusionList []string `json:"ApplicationInclusionList,omitempty"`
+}
+
+// ChaosParameters configures a Chaos run. Zero durations and
+// counts leave the value up to the cluster.
+type ChaosParameters struct {
+	// TimeToRun how long Chaos runs before stopping itself
+	TimeToRun time.Duration
+	// MaxClusterStabilizationTimeout how long to wait for the
+	// cluster to become healthy before reporting a validation failure
+	MaxClusterStabilizationTimeout time.Duration
+	// MaxConcurrentFaults the maximum number of faults per iteration
+	MaxConcurrentFaults int64
+	// DisableMoveReplicaFaults stops Chaos from moving replicas
+	DisableMoveReplicaFaults bool
+	// WaitTimeBetweenFaults the wait between faults within an iteration
+	WaitTimeBetweenFaults time.Duration
+	// WaitTimeBetweenIterations the wait between iterations
+	WaitTimeBetweenIterations time.Duration
+	// ClusterHealthPolicy the health policy used to validate the cluster
+	ClusterHealthPolicy *ClusterHealthPolicy
+	// Context user defined values recorded with the run
+	Context map[string]string
+	// ChaosTargetFilter limits where Chaos injects faults
+	ChaosTargetFilter *ChaosTargetFilter
+}
+
+// chaosParametersJSON the wire format of ChaosParameters
+type chaosParametersJSON struct {
+	TimeToRunInSeconds                      string                   `json:"TimeToRunInSeconds,omitempty"`
+	MaxClusterStabilizationTimeoutInSeconds int64                    `json:"MaxClusterStabilizationTimeoutInSeconds,omitempty"`
+	MaxConcurrentFaults                     int64                    `json:"MaxConcurrentFaults,omitempty"`
+	EnableMoveReplicaFaults                 *bool                    `json:"EnableMoveReplicaFaults,omitempty"`
+	WaitTimeBetweenFaultsInSeconds          int64                    `json:"WaitTimeBetweenFaultsInSeconds,omitempty"`
+	WaitTimeBetweenIterationsInSeconds      int64                    `json:"WaitTimeBetweenIterationsInSeconds,omitempty"`
+	ClusterHealthPolicy                     *clusterHealthPolicyJSON `json:"ClusterHealthPolicy,omitempty"`
+	Context                                 *chaosContextJSON        `json:"Context,omitempty"`
+	ChaosTargetFilter                       *ChaosTargetFilter       `json:"ChaosTargetFilter,omitempty"`
+}
+
+type clusterHealthPolicyJSON struct {
+	ClusterHealthPolicy
+	ApplicationTypeHealthPolicyMap []applicationTypeHealthPolicyJSON `json:"ApplicationTypeHealthPolicyMap,omitempty"`
+}
+
+type applicationTypeHealthPolicyJSON struct {
+	Key   string `json:"Key"`
+	Value int    `json:"Value"`
+}
+
+type chaosContextJSON struct {
+	Map map[string]string `json:"Map"`
+}
+
+// MarshalJSON encodes the parameters in the
+// seconds based format of the Service Fabric API
+func (p ChaosParameters) MarshalJSON() ([]byte, error) {
+	raw := chaosParametersJSON{
+		MaxClusterStabilizationTimeoutInSeconds: durationSeconds(p.MaxClusterStabilizationTimeout),
+		MaxConcurrentFaults:                     p.MaxConcurrentFaults,
+		WaitTimeBetweenFaultsInSeconds:          durationSeconds(p.WaitTimeBetweenFaults),
+		WaitTimeBetweenIterationsInSeconds:      durationSeconds(p.WaitTimeBetweenIterations),
+		ChaosTargetFilter:                       p.ChaosTargetFilter,
+	}
+	if p.DisableMoveReplicaFaults {
+		enableMoveReplicaFaults := false
+		raw.EnableMoveReplicaFaults = &enableMoveReplicaFaults
+	}
+	if p.TimeToRun > 0 {
+		raw.TimeToRunInSeconds = strconv.FormatInt(durationSeconds(p.TimeToRun), 10)
+	}
+	if p.ClusterHealthPolicy != nil {
+		policy := &clusterHealthPolicyJSON{ClusterHealthPolicy: *p.ClusterHealthPolicy}
+		applicationTypes := make([]string, 0, len(p.ClusterHealthPolicy.ApplicationTypeHealthPolicyMap))
+		for applicationType := range p.ClusterHealthPolicy.ApplicationTypeHealthPolicyMap {
+			applicationTypes = append(applicationTypes, applicationType)
+		}
+		sort.Strings(applicationTypes)
+		for _, applicationType := range applicationTypes {
+			maxPercent := p.ClusterHealthPolicy.ApplicationTypeHealthPolicyMap[applicationType]
+			policy.ApplicationTypeHealthPolicyMap = append(policy.ApplicationTypeHealthPolicyMap, applicationTypeHealthPolicyJSON{Key: applicationType, Value: maxPercent})
+		}
+		raw.ClusterHealthPolicy = policy
+	}
+	if len(p.Context) > 0 {
+		raw.Context = &chaosContextJSON{Map: p.Context}
+	}
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON decodes parameters in the
+// seconds based format of the Service Fabric API
+func (p *ChaosParameters) UnmarshalJSON(data []byte) error {
+	var raw chaosParametersJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*p = ChaosParameters{
+		MaxClusterStabilizationTimeout: time.Duration(raw.MaxClusterStabilizationTimeoutInSeconds) * time.Second,
+		MaxConcurrentFaults:            raw.MaxConcurrentFaults,
+		DisableMoveReplicaFaults:       raw.EnableMoveReplicaFaults != nil && !*raw.EnableMoveReplicaFaults,
+		WaitTimeBetweenFaults:          time.Duration(raw.WaitTimeBetweenFaultsInSeconds) * time.Second,
+		WaitTimeBetweenIterations:      time.Duration(raw.WaitTimeBetweenIterationsInSeconds) * time.Second,
+		ChaosTargetFilter:              raw.ChaosTargetFilter,
+	}
+	if raw.TimeToRunInSeconds != "" {
+		seconds, err := strconv.ParseInt(raw.TimeToRunInSeconds, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid TimeToRunInSeconds %q: %v", raw.TimeToRunInSeconds, err)
+		}
+		p.TimeToRun = time.Duration(seconds) * time.Second
+	}
+	if raw.ClusterHealthPolicy != nil {
+		policy := raw.ClusterHealthPolicy.ClusterHealthPolicy
+		if len(raw.ClusterHealthPolicy.ApplicationTypeHealthPolicyMap) > 0 {
+			policy.ApplicationTypeHealthPolicyMap = map[string]int{}
+			for _, item := range raw.ClusterHealthPolicy.ApplicationTypeHealthPolicyMap {
+				policy.ApplicationTypeHealthPolicyMap[item.Key] = item.Value
+			}
+		}
+		p.ClusterHealthPolicy = &policy
+	}
+	if raw.Context != nil && len(raw.Context.Map) > 0 {
+		p.Context = raw.Context.Map
+	}
+	return nil
+}
+
+// ChaosInfo describes the current state of Chaos
+type ChaosInfo struct {
+	ChaosParameters *ChaosParameters    `json:"ChaosParameters"`
+	Status          ChaosStatus         `json:"Status"`
+	ScheduleStatus  ChaosScheduleStatus `json:"ScheduleStatus"`
+}
+
+// ChaosEvent is implemented by every event recorded by Chaos.
+// Events are decoded into their concrete types by Kind.
+type ChaosEvent interface {
+	// GetChaosEventBase returns the fields shared by every Chaos event
+	GetChaosEventBase() *ChaosEventBase
+}
+
+// ChaosEventBase the fields shared by every Chaos event
+type ChaosEventBase struct {
+	Kind         ChaosEventKind `json:"Kind"`
+	TimeStampUtc time.Time      `json:"TimeStampUtc"`
+}
+
+// GetChaosEventBase returns the fields shared by every Chaos event
+func (e *ChaosEventBase) GetChaosEventBase() *ChaosEventBase {
+	return e
+}
+
+// StartedChaosEvent Chaos has started
+type StartedChaosEvent struct {
+	ChaosEventBase
+	ChaosParameters ChaosParameters `json:"ChaosParameters"`
+}
+
+// ExecutingFaultsChaosEvent Chaos is injecting the faults of an iteration
+type ExecutingFaultsChaosEvent struct {
+	ChaosEventBase
+	Faults []string `json:"Faults"`
+}
+
+// WaitingChaosEvent Chaos is waiting for the cluster to stabilize
+type WaitingChaosEvent struct {
+	ChaosEventBase
+	Reason string `json:"Reason"`
+}
+
+// ValidationFailedChaosEvent the cluster did not become
+// healthy within the stabilization timeout
+type ValidationFailedChaosEvent struct {
+	ChaosEventBase
+	Reason string `json:"Reason"`
+}
+
+// TestErrorChaosEvent Chaos hit an unexpected error
+type TestErrorChaosEvent struct {
+	ChaosEventBase
+	Reason string `json:"Reason"`
+}
+
+// StoppedChaosEvent Chaos has stopped
+type StoppedChaosEvent struct {
+	ChaosEventBase
+	Reason string `json:"Reason"`
+}
+
+// UnknownChaosEvent holds a Chaos event whose kind is
+// not known to this client so it is not lost on decode
+type UnknownChaosEvent struct {
+	ChaosEventBase
+	Raw json.RawMessage
+}
+
+// ChaosEventsSegment encapsulates the response model
+// for Chaos events in the Service Fabric API
+type ChaosEventsSegment struct {
+	ContinuationToken *string `json:"ContinuationToken"`
+	History           []struct {
+		ChaosEvent json.RawMessage `json:"ChaosEvent"`
+	} `json:"History"`
+}
+
+// ChaosEventsOptions selects the events returned by GetChaosEvents
+type ChaosEventsOptions struct {
+	// StartTime only return events at or after StartTime, zero for all
+	StartTime time.Time
+	// EndTime only return events at or before EndTime, zero for all
+	EndTime time.Time
+	// MaxResults the maximum number of events returned per page,
+	// zero leaves the page size up to the cluster
+	MaxResults int64
+}
+
+func (o ChaosEventsOptions) queryParams() queryParamsFunc {
+	var params []queryParamsFunc
+	if !o.StartTime.IsZero() {
+		params = append(params, withParam("StartTimeUtc", fileTime(o.StartTime)))
+	}
+	if !o.EndTime.IsZero() {
+		params = append(params, withParam("EndTimeUtc", fileTime(o.EndTime)))
+	}
+	return withParams(params...)
+}
+
+// ChaosScheduleTimeOfDay a time of day in UTC
+type ChaosScheduleTimeOfDay struct {
+	Hour   int `json:"Hour"`
+	Minute int `json:"Minute"`
+}
+
+// ChaosScheduleTimeRange a time range within a day
+type ChaosScheduleTimeRange struct {
+	StartTime ChaosScheduleTimeOfDay `json:"StartTime"`
+	EndTime   ChaosScheduleTimeOfDay `json:"EndTime"`
+}
+
+// ChaosScheduleJobActiveDays the days of the week a job runs on
+type ChaosScheduleJobActiveDays struct {
+	Sunday    bool `json:"Sunday"`
+	Monday    bool `json:"Monday"`
+	Tuesday   bool `json:"Tuesday"`
+	Wednesday bool `json:"Wednesday"`
+	Thursday  bool `json:"Thursday"`
+	Friday    bool `json:"Friday"`
+	Saturday  bool `json:"Saturday"`
+}
+
+// ChaosScheduleJob runs Chaos with the named parameters
+// at the given times on the given days
+type ChaosScheduleJob struct {
+	// ChaosParameters the key of the parameters in ChaosParametersDictionary
+	ChaosParameters string                     `json:"ChaosParameters"`
+	Days            ChaosScheduleJobActiveDays `json:"Days"`
+	Times           []ChaosScheduleTimeRange   `json:"Times"`
+}
+
+// ChaosParametersDictionaryItem names a set of Chaos parameters
+type ChaosParametersDictionaryItem struct {
+	Key   string          `json:"Key"`
+	Value ChaosParameters `json:"Value"`
+}
+
+// ChaosSchedule describes when Chaos runs
+type ChaosSchedule struct {
+	StartDate                 time.Time                       `json:"StartDate"`
+	ExpiryDate                time.Time                       `json:"ExpiryDate"`
+	ChaosParametersDictionary []ChaosParametersDictionaryItem `json:"ChaosParametersDictionary"`
+	Jobs                      []ChaosScheduleJob              `json:"Jobs"`
+}
+
+// ChaosScheduleDescription a versioned Chaos schedule
+type ChaosScheduleDescription struct {
+	// Version must match the current version when posting a schedule
+	Version  int64         `json:"Version"`
+	Schedule ChaosSchedule `json:"Schedule"`
+}
+
+// ChaosSummary summarises a Chaos run
+type ChaosSummary struct {
+	Start time.Time
+	End   time.Time
+	// Iterations the number of iterations in which faults were injected
+	Iterations int
+	// Faults the faults injected, in the order they were injected
+	Faults []string
+	// ValidationFailures the reasons the cluster failed validation
+	ValidationFailures []string
+	// TestErrors the unexpected errors Chaos hit
+	TestErrors []string
+	// StopReason why Chaos stopped, if it reported stopping
+	StopReason string
+}
+
+// StartChaos starts Chaos in the cluster with params.
+func (c Client) StartChaos(params ChaosParameters) error {
+	_, err := c.postHTTP(opStartChaos, "Tools/Chaos/$/Start", params)
+	return err
+}
+
+// StopChaos stops Chaos if it is running and stops the Chaos schedule.
+func (c Client) StopChaos() error {
+	_, err := c.postHTTP(opStopChaos, "Tools/Chaos/$/Stop", nil)
+	return err
+}
+
+// GetChaos returns whether Chaos is running, the parameters
+// it runs with and the status of the Chaos schedule.
+func (c Client) GetChaos() (*ChaosInfo, error) {
+	res, err := c.getHTTP(opGetChaos, "Tools/Chaos")
+	if err != nil {
+		return nil, err
+	}
+
+	var info ChaosInfo
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &info, nil
+}
+
+// GetChaosEvents returns the Chaos events selected by opts.
+func (c Client) GetChaosEvents(opts ChaosEventsOptions) ([]ChaosEvent, error) {
+	var events []ChaosEvent
+	var continueToken string
+	for {
+		// The time window may only be given for the first page
+		paramsFunc := opts.queryParams()
+		if continueToken != "" {
+			paramsFunc = withContinue(continueToken)
+		}
+		var maxResults queryParamsFunc = noOp
+		if opts.MaxResults > 0 {
+			maxResults = withParam("MaxResults", strconv.FormatInt(opts.MaxResults, 10))
+		}
+
+		res, err := c.getHTTP(opGetChaosEvents, "Tools/Chaos/Events", paramsFunc, maxResults)
+		if err != nil {
+			return nil, err
+		}
+
+		var segment ChaosEventsSegment
+		err = json.Unmarshal(res, &segment)
+		if err != nil {
+			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+		}
+
+		for _, wrapper := range segment.History {
+			event, err := unmarshalChaosEvent(wrapper.ChaosEvent)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+
+		continueToken = getString(segment.ContinuationToken)
+		c.logPage(opGetChaosEvents, len(segment.History), continueToken)
+		if continueToken == "" {
+			break
+		}
+	}
+	return events, nil
+}
+
+// GetChaosSchedule returns the Chaos schedule and its version.
+func (c Client) GetChaosSchedule() (*ChaosScheduleDescription, error) {
+	res, err := c.getHTTP(opGetChaosSchedule, "Tools/Chaos/Schedule")
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule ChaosScheduleDescription
+	err = json.Unmarshal(res, &schedule)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &schedule, nil
+}
+
+// PostChaosSchedule replaces the Chaos schedule. The version of the
+// schedule must match the current version, which the cluster then
+// increments.
+func (c Client) PostChaosSchedule(schedule ChaosScheduleDescription) error {
+	_, err := c.postHTTP(opPostChaosSchedule, "Tools/Chaos/Schedule", schedule)
+	return err
+}
+
+// RunChaos runs Chaos with params for duration, stopping it early if
+// ctx is done, and summarises the events recorded during the run.
+func (c Client) RunChaos(ctx context.Context, params ChaosParameters, duration time.Duration) (*ChaosSummary, error) {
+	params.TimeToRun = duration
+	start := time.Now()
+	if err := c.WithContext(ctx).StartChaos(params); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	pollErr := pollUntil(runCtx, chaosPollInitialInterval, chaosPollMaxInterval, func() (bool, error) {
+		info, err := c.WithContext(runCtx).GetChaos()
+		if err != nil {
+			// A request cancelled at the end of the run is not an error
+			if runCtx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+		return info.Status == ChaosStatusStopped, nil
+	})
+
+	// Chaos stops itself after TimeToRun, but a run cut short by ctx or
+	// a failed poll, or still running on a slow cluster, must not leave
+	// Chaos running. A finished run is not stopped as stopping Chaos
+	// also stops its schedule.
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer stopCancel()
+	stop := pollErr != nil
+	if pollErr != nil && runCtx.Err() != nil && ctx.Err() == nil {
+		info, err := c.WithContext(stopCtx).GetChaos()
+		stop = err != nil || info.Status == ChaosStatusRunning
+	}
+	var stopErr error
+	if stop {
+		stopErr = c.WithContext(stopCtx).StopChaos()
+	}
+	if pollErr != nil && runCtx.Err() == nil {
+		return nil, pollErr
+	}
+	if stopErr != nil {
+		return nil, stopErr
+	}
+
+	end := time.Now()
+	events, err := c.WithContext(stopCtx).GetChaosEvents(ChaosEventsOptions{StartTime: start, EndTime: end})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := SummarizeChaosEvents(events)
+	summary.Start = start
+	summary.End = end
+	if ctx.Err() != nil {
+		return summary, ctx.Err()
+	}
+	return summary, nil
+}
+
+// SummarizeChaosEvents summarises the faults, validation failures
+// and errors recorded in events
+func SummarizeChaosEvents(events []ChaosEvent) *ChaosSummary {
+	summary := &ChaosSummary{}
+	for _, event := range events {
+		switch e := event.(type) {
+		case *ExecutingFaultsChaosEvent:
+			summary.Iterations++
+			summary.Faults = append(summary.Faults, e.Faults...)
+		case *ValidationFailedChaosEvent:
+			summary.ValidationFailures = append(summary.ValidationFailures, e.Reason)
+		case *TestErrorChaosEvent:
+			summary.TestErrors = append(summary.TestErrors, e.Reason)
+		case *StoppedChaosEvent:
+			summary.StopReason = e.Reason
+		}
+
+		timeStamp := event.GetChaosEventBase().TimeStampUtc
+		if summary.Start.IsZero() || timeStamp.Before(summary.Start) {
+			summary.Start = timeStamp
+		}
+		if timeStamp.After(summary.End) {
+			summary.End = timeStamp
+		}
+	}
+	return summary
+}
+
+func unmarshalChaosEvent(raw json.RawMessage) (ChaosEvent, error) {
+	var header ChaosEventBase
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("could not deserialise Chaos event: %+v", err)
+	}
+
+	var event ChaosEvent
+	switch header.Kind {
+	case ChaosEventKindStarted:
+		event = &StartedChaosEvent{}
+	case ChaosEventKindExecutingFaults:
+		event = &ExecutingFaultsChaosEvent{}
+	case ChaosEventKindWaiting:
+		event = &WaitingChaosEvent{}
+	case ChaosEventKindValidationFailed:
+		event = &ValidationFailedChaosEvent{}
+	case ChaosEventKindTestError:
+		event = &TestErrorChaosEvent{}
+	case ChaosEventKindStopped:
+		event = &StoppedChaosEvent{}
+	default:
+		return &UnknownChaosEvent{ChaosEventBase: header, Raw: raw}, nil
+	}
+
+	if err := json.Unmarshal(raw, event); err != nil {
+		return nil, fmt.Errorf("could not deserialise %s Chaos event: %+v", header.Kind, err)
+	}
+	return event, nil
+}
+
+// fileTime formats t as a Windows file time, the number of
+// 100 nanosecond intervals since the start of 1601 UTC
+func fileTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/100+fileTimeUnixEpoch, 10)
+}
+
+// durationSeconds returns d in seconds, rounding up so
+// that short durations are not mistaken for unset ones
+func durationSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
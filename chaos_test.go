@@ -0,0 +1,212 @@
+package servicefabric
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChaosParametersJSON(t *testing.T) {
+	params := ChaosParameters{
+		TimeToRun:                      time.Hour,
+		MaxClusterStabilizationTimeout: 30 * time.Second,
+		MaxConcurrentFaults:            2,
+		DisableMoveReplicaFaults:       true,
+		ClusterHealthPolicy: &ClusterHealthPolicy{
+			MaxPercentUnhealthyNodes:       10,
+			ApplicationTypeHealthPolicyMap: map[string]int{"TestApplicationType": 20, "OtherType": 0},
+		},
+		Context:           map[string]string{"run": "nightly"},
+		ChaosTargetFilter: &ChaosTargetFilter{NodeTypeInclusionList: []string{"FrontEnd"}},
+	}
+
+	actual, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	expected := `{"TimeToRunInSeconds":"3600","MaxClusterStabilizationTimeoutInSeconds":30,"MaxConcurrentFaults":2,"EnableMoveReplicaFaults":false,` +
+		`"ClusterHealthPolicy":{"ConsiderWarningAsError":false,"MaxPercentUnhealthyNodes":10,"MaxPercentUnhealthyApplications":0,` +
+		`"ApplicationTypeHealthPolicyMap":[{"Key":"OtherType","Value":0},{"Key":"TestApplicationType","Value":20}]},` +
+		`"Context":{"Map":{"run":"nightly"}},"ChaosTargetFilter":{"NodeTypeInclusionList":["FrontEnd"]}}`
+	if string(actual) != expected {
+		t.Errorf("Got %s, want %s", actual, expected)
+	}
+
+	var decoded ChaosParameters
+	if err := json.Unmarshal(actual, &decoded); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !reflect.DeepEqual(decoded, params) {
+		t.Errorf("Got %+v, want %+v", decoded, params)
+	}
+}
+
+func TestGetChaosEvents(t *testing.T) {
+	server := httptest.NewServer(&fakeChaos{})
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	start := time.Date(2018, 4, 3, 18, 0, 0, 0, time.UTC)
+	events, err := sfClient.GetChaosEvents(ChaosEventsOptions{StartTime: start, EndTime: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("Got %d events, want 5", len(events))
+	}
+
+	started, ok := events[0].(*StartedChaosEvent)
+	if !ok {
+		t.Fatalf("Got %T, want *StartedChaosEvent", events[0])
+	}
+	if started.ChaosParameters.TimeToRun != time.Minute || started.ChaosParameters.DisableMoveReplicaFaults {
+		t.Errorf("Got %+v, want a minute long run moving replicas", started.ChaosParameters)
+	}
+
+	summary := SummarizeChaosEvents(events)
+	expected := &ChaosSummary{
+		Start:      start,
+		End:        start.Add(time.Minute),
+		Iterations: 2,
+		Faults: []string{
+			"ActionType: RestartNode, NodeName: _Node_0",
+			"ActionType: MovePrimary, ServiceUri: fabric:/TestApplication/TestService",
+			"ActionType: RestartReplica, ReplicaId: 131496928082309293",
+		},
+		ValidationFailures: []string{"Cluster was unhealthy"},
+		StopReason:         "TimeToRunExpired",
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Got %+v, want %+v", summary, expected)
+	}
+}
+
+func TestFileTime(t *testing.T) {
+	actual := fileTime(time.Date(2018, 4, 3, 18, 0, 0, 0, time.UTC))
+	if actual != "131672520000000000" {
+		t.Errorf("Got %s, want 131672520000000000", actual)
+	}
+}
+
+func TestChaosSchedule(t *testing.T) {
+	server := httptest.NewServer(&fakeChaos{})
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	current, err := sfClient.GetChaosSchedule()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	schedule := ChaosScheduleDescription{
+		Version: current.Version,
+		Schedule: ChaosSchedule{
+			StartDate:  time.Date(2018, 4, 3, 0, 0, 0, 0, time.UTC),
+			ExpiryDate: time.Date(2018, 5, 3, 0, 0, 0, 0, time.UTC),
+			ChaosParametersDictionary: []ChaosParametersDictionaryItem{
+				{Key: "nightly", Value: ChaosParameters{TimeToRun: time.Hour, MaxConcurrentFaults: 1}},
+			},
+			Jobs: []ChaosScheduleJob{
+				{
+					ChaosParameters: "nightly",
+					Days:            ChaosScheduleJobActiveDays{Monday: true, Friday: true},
+					Times:           []ChaosScheduleTimeRange{{StartTime: ChaosScheduleTimeOfDay{Hour: 1}, EndTime: ChaosScheduleTimeOfDay{Hour: 2}}},
+				},
+			},
+		},
+	}
+	if err := sfClient.PostChaosSchedule(schedule); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	actual, err := sfClient.GetChaosSchedule()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	schedule.Version++
+	if !reflect.DeepEqual(*actual, schedule) {
+		t.Errorf("Got %+v, want %+v", *actual, schedule)
+	}
+
+	schedule.Version = 0
+	if err := sfClient.PostChaosSchedule(schedule); err == nil {
+		t.Error("Expected an error posting a stale schedule version")
+	}
+}
+
+func TestRunChaos(t *testing.T) {
+	chaos := &fakeChaos{}
+	server := httptest.NewServer(chaos)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	summary, err := sfClient.RunChaos(context.Background(), ChaosParameters{MaxConcurrentFaults: 2}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+	if chaos.stops != 1 || chaos.status != ChaosStatusStopped {
+		t.Errorf("Got %d stops, want Chaos to be stopped once", chaos.stops)
+	}
+	if chaos.params.MaxConcurrentFaults != 2 || chaos.params.TimeToRun != time.Second {
+		t.Errorf("Got %+v, want the run parameters", chaos.params)
+	}
+	if summary.Iterations != 2 || len(summary.Faults) != 3 {
+		t.Errorf("Got %+v, want the faults of both iterations", summary)
+	}
+	if summary.End.Before(summary.Start) {
+		t.Errorf("Got run from %v to %v, want the run window", summary.Start, summary.End)
+	}
+}
+
+func TestRunChaosFinished(t *testing.T) {
+	chaos := &fakeChaos{runPolls: 1}
+	server := httptest.NewServer(chaos)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	summary, err := sfClient.RunChaos(context.Background(), ChaosParameters{}, time.Minute)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+	if chaos.stops != 0 {
+		t.Errorf("Got %d stops, want a finished run not to be stopped", chaos.stops)
+	}
+	if summary.Iterations != 2 {
+		t.Errorf("Got %+v, want the faults of both iterations", summary)
+	}
+}
+
+func TestRunChaosStopsWhenPollingFails(t *testing.T) {
+	chaos := &fakeChaos{getStatus: http.StatusInternalServerError}
+	server := httptest.NewServer(chaos)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	_, err := sfClient.RunChaos(context.Background(), ChaosParameters{}, time.Minute)
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) || responseErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Got %v, want the polling error", err)
+	}
+
+	chaos.mu.Lock()
+	defer chaos.mu.Unlock()
+	if chaos.stops != 1 || chaos.status != ChaosStatusStopped {
+		t.Errorf("Got %d stops, want Chaos to be stopped", chaos.stops)
+	}
+}
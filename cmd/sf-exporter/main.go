@@ -0,0 +1,125 @@
+// Command sf-exporter serves the topology and health of a Service
+// Fabric cluster as Prometheus metrics, see the exporter package.
+//
+// Usage:
+//
+//	sf-exporter --endpoint https://cluster.example.com:19080 \
+//		--cert-file client.crt --key-file client.key \
+//		--interval 1m --max-calls 5000 --min-call-interval 10ms
+//
+// The metrics are served from /metrics on --listen.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+	"github.com/jjcollinge/servicefabric/exporter"
+)
+
+func main() {
+	listen := flag.String("listen", ":9808", "the address to serve metrics on")
+	endpoint := flag.String("endpoint", "", "the cluster management endpoint")
+	apiVersion := flag.String("api-version", sf.DefaultAPIVersion, "the API version")
+	certFile := flag.String("cert-file", "", "the PEM encoded client certificate")
+	keyFile := flag.String("key-file", "", "the PEM encoded client key, defaults to --cert-file")
+	caFile := flag.String("ca-file", "", "the PEM encoded certificates the cluster's certificate is verified against")
+	insecure := flag.Bool("insecure", false, "skip verifying the cluster's certificate")
+	interval := flag.Duration("interval", time.Minute, "the time between walks of the topology")
+	maxCalls := flag.Int("max-calls", 0, "the most requests made by a walk of the topology, zero for no limit")
+	minCallInterval := flag.Duration("min-call-interval", 0, "the least time between the start of two requests")
+	timeout := flag.Duration("scrape-timeout", 0, "the most time a walk of the topology may take, zero for no limit")
+	flag.Parse()
+
+	if *endpoint == "" {
+		fmt.Fprintln(os.Stderr, "sf-exporter: --endpoint is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	tlsConfig, err := loadTLSConfig(*certFile, *keyFile, *caFile, *insecure)
+	if err != nil {
+		log.Fatalf("sf-exporter: %v", err)
+	}
+	client, err := sf.NewClient(&http.Client{}, *endpoint, *apiVersion, tlsConfig)
+	if err != nil {
+		log.Fatalf("sf-exporter: %v", err)
+	}
+
+	e := exporter.New(client, exporter.Options{
+		Interval: *interval,
+		Budget: exporter.Budget{
+			MaxCalls:        *maxCalls,
+			MinCallInterval: *minCallInterval,
+			Timeout:         *timeout,
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	server := &http.Server{Addr: *listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelShutdown()
+		server.Shutdown(shutdown)
+	}()
+
+	go e.Run(ctx)
+
+	log.Printf("sf-exporter: serving metrics of %s on %s", *endpoint, *listen)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatalf("sf-exporter: %v", err)
+	}
+}
+
+func loadTLSConfig(certFile, keyFile, caFile string, insecure bool) (*tls.Config, error) {
+	if certFile == "" && caFile == "" && !insecure {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if certFile != "" {
+		if keyFile == "" {
+			keyFile = certFile
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate %s: %v", certFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificates %s: %v", caFile, err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"sort"
+	"strings"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+const defaultLabelPrefix = "traefik"
+
+func appList(c *cli, args []string) error {
+	fs, g := c.flagSet("app list")
+	typeName := fs.String("type", "", "only list applications of this application type")
+	if err := parseNone(fs, args); err != nil {
+		return err
+	}
+
+	client, cancel, err := c.client(g)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	apps, err := client.GetApplicationsWithOptions(sf.ApplicationsOptions{ApplicationTypeName: *typeName})
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"NAME", "TYPE", "VERSION", "STATUS", "HEALTH"}}
+	for _, app := range apps.Items {
+		t.rows = append(t.rows, []string{app.Name, app.TypeName, app.TypeVersion, app.Status, app.HealthState})
+	}
+	return c.print(g.output, apps.Items, t)
+}
+
+func serviceList(c *cli, args []string) error {
+	fs, g := c.flagSet("service list")
+	appName := fs.String("app", "", "the application name or id")
+	typeName := fs.String("type", "", "only list services of this service type")
+	if err := parseNone(fs, args); err != nil {
+		return err
+	}
+	if *appName == "" {
+		return usagef("--app is required")
+	}
+
+	client, cancel, err := c.client(g)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	services, err := client.GetServicesWithOptions(sf.ApplicationIDFromName(*appName).String(), sf.ServicesOptions{ServiceTypeName: *typeName})
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"NAME", "TYPE", "KIND", "STATUS", "HEALTH"}}
+	for _, service := range services.Items {
+		t.rows = append(t.rows, []string{service.Name, service.TypeName, service.ServiceKind, service.ServiceStatus, service.HealthState})
+	}
+	return c.print(g.output, services.Items, t)
+}
+
+func partitionList(c *cli, args []string) error {
+	fs, g := c.flagSet("partition list")
+	serviceName := fs.String("service", "", "the service name or id")
+	if err := parseNone(fs, args); err != nil {
+		return err
+	}
+	if *serviceName == "" {
+		return usagef("--service is required")
+	}
+
+	client, cancel, err := c.client(g)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	partitions, err := client.GetServicePartitions(sf.ServiceIDFromName(*serviceName))
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"ID", "KIND", "LOW KEY", "HIGH KEY", "STATUS", "HEALTH"}}
+	for _, partition := range partitions.Items {
+		info := partition.PartitionInformation
+		t.rows = append(t.rows, []string{info.ID, info.ServicePartitionKind, info.LowKey, info.HighKey, partition.PartitionStatus, partition.HealthState})
+	}
+	return c.print(g.output, partitions.Items, t)
+}
+
+func replicaList(c *cli, args []string) error {
+	fs, g := c.flagSet("replica list")
+	partitionID := fs.String("partition", "", "the partition id")
+	if err := parseNone(fs, args); err != nil {
+		return err
+	}
+	if *partitionID == "" {
+		return usagef("--partition is required")
+	}
+
+	client, cancel, err := c.client(g)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	members, err := client.GetPartitionMembersByID(sf.PartitionID(*partitionID))
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"ID", "ROLE", "STATUS", "HEALTH", "NODE", "ADDRESS"}}
+	for _, member := range members {
+		id, data := member.GetReplicaData()
+		if data == nil {
+			data = &sf.ReplicaItemBase{}
+		}
+		t.rows = append(t.rows, []string{id, string(data.ReplicaRole), string(data.ReplicaStatus), data.HealthState, data.NodeName, data.Address})
+	}
+	return c.print(g.output, members, t)
+}
+
+func propertyGet(c *cli, args []string) error {
+	fs, g := c.flagSet("property get")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		return usagef("expected NAME and optionally PROPERTY")
+	}
+	name := nameID(positional[0])
+
+	client, cancel, err := c.client(g)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if len(positional) == 2 {
+		property, err := client.GetProperty(name, positional[1])
+		if err != nil {
+			return err
+		}
+		t := table{headers: []string{"NAME", "KIND", "VALUE"}, rows: [][]string{{property.Name, property.Value.Kind, property.Value.Data}}}
+		return c.print(g.output, property, t)
+	}
+
+	exists, properties, err := client.GetProperties(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFound("FABRIC_E_NAME_DOES_NOT_EXIST", "name "+name+" does not exist")
+	}
+
+	t := table{headers: []string{"NAME", "VALUE"}}
+	for _, key := range sortedKeys(properties) {
+		t.rows = append(t.rows, []string{key, properties[key]})
+	}
+	return c.print(g.output, properties, t)
+}
+
+func propertySet(c *cli, args []string) error {
+	fs, g := c.flagSet("property set")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 3 {
+		return usagef("expected NAME, PROPERTY and VALUE")
+	}
+
+	client, cancel, err := c.client(g)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return client.PutProperty(nameID(positional[0]), positional[1], positional[2])
+}
+
+// label a resolved label as printed
+type label struct {
+	Key      string `json:"Key"`
+	Value    string `json:"Value"`
+	RawValue string `json:"RawValue"`
+	Source   string `json:"Source"`
+	Origin   string `json:"Origin"`
+}
+
+func labels(c *cli, args []string) error {
+	fs, g := c.flagSet("labels")
+	appName := fs.String("app", "", "the application name or id")
+	serviceName := fs.String("service", "", "the service name or id")
+	prefix := fs.String("prefix", defaultLabelPrefix, "the label prefix")
+	if err := parseNone(fs, args); err != nil {
+		return err
+	}
+	if *appName == "" || *serviceName == "" {
+		return usagef("--app and --service are required")
+	}
+
+	client, cancel, err := c.client(g)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	app, service, err := findService(client, sf.ApplicationIDFromName(*appName), sf.ServiceIDFromName(*serviceName))
+	if err != nil {
+		return err
+	}
+
+	resolved, err := sf.NewLabelResolver(client, *prefix).Resolve(service, app)
+	if err != nil {
+		return err
+	}
+
+	printed := []label{}
+	t := table{headers: []string{"KEY", "VALUE", "SOURCE", "ORIGIN"}}
+	for _, key := range resolved.Keys() {
+		l := resolved[key]
+		printed = append(printed, label{Key: key, Value: l.Value, RawValue: l.RawValue, Source: string(l.Source), Origin: l.Origin})
+		t.rows = append(t.rows, []string{key, l.Value, string(l.Source), l.Origin})
+	}
+	return c.print(g.output, printed, t)
+}
+
+// findService returns the application and service identified by
+// appID and serviceID, which the label resolver reads labels from
+func findService(client *sf.Client, appID sf.ApplicationID, serviceID sf.ServiceID) (*sf.ApplicationItem, *sf.ServiceItem, error) {
+	apps, err := client.GetApplications()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var app *sf.ApplicationItem
+	for i := range apps.Items {
+		if apps.Items[i].ID == appID.String() {
+			app = &apps.Items[i]
+		}
+	}
+	if app == nil {
+		return nil, nil, notFound("FABRIC_E_APPLICATION_NOT_FOUND", "application "+appID.Name()+" not found")
+	}
+
+	services, err := client.GetServices(appID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range services.Items {
+		if services.Items[i].ID == serviceID.String() {
+			return app, &services.Items[i], nil
+		}
+	}
+	return nil, nil, notFound("FABRIC_E_SERVICE_DOES_NOT_EXIST", "service "+serviceID.Name()+" not found")
+}
+
+// notFound reports an entity which the cluster listed no match for
+// as the cluster would report a missing entity. It has no request
+// URL, so message must name the entity which was not found.
+func notFound(code, message string) error {
+	return &sf.ResponseError{StatusCode: http.StatusNotFound, Status: "404 Not Found", Code: code, Message: message}
+}
+
+// parseNone parses args for a command which takes no positional arguments
+func parseNone(fs *flag.FlagSet, args []string) error {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usagef("unexpected arguments %s", strings.Join(positional, " "))
+	}
+	return nil
+}
+
+// nameID returns the Naming name id for a name given with or without
+// the fabric:/ scheme, or as an application or service id
+func nameID(name string) string {
+	return strings.Replace(strings.TrimPrefix(name, "fabric:/"), "~", "/", -1)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+const defaultTimeout = time.Minute
+
+// config the contents of the config file
+type config struct {
+	// DefaultProfile the profile used when none is selected
+	DefaultProfile string `json:"defaultProfile"`
+	// Profiles cluster profiles keyed by name
+	Profiles map[string]profile `json:"profiles"`
+}
+
+// profile how to connect to a cluster
+type profile struct {
+	Endpoint   string `json:"endpoint"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	// CertFile and KeyFile the PEM encoded client certificate and key
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// CAFile the PEM encoded certificates the cluster's certificate is
+	// verified against, the system roots are used when empty
+	CAFile string `json:"caFile,omitempty"`
+	// Insecure skips verifying the cluster's certificate
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// globalFlags the flags accepted by every command
+type globalFlags struct {
+	config     string
+	profile    string
+	endpoint   string
+	apiVersion string
+	output     string
+	timeout    time.Duration
+}
+
+// flagSet returns the flags of the named command
+// with the global flags already registered
+func (c *cli) flagSet(name string) (*flag.FlagSet, *globalFlags) {
+	fs := flag.NewFlagSet("sfgo "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	g := &globalFlags{}
+	fs.StringVar(&g.config, "config", "", "the config file, defaults to $SFGO_CONFIG or sfgo/config.json in the user config directory")
+	fs.StringVar(&g.profile, "profile", "", "the cluster profile, defaults to $SFGO_PROFILE or the config's default profile")
+	fs.StringVar(&g.endpoint, "endpoint", "", "the cluster management endpoint, overriding the profile")
+	fs.StringVar(&g.apiVersion, "api-version", "", "the API version, overriding the profile")
+	fs.StringVar(&g.output, "output", "table", "the output format: table, json or yaml")
+	fs.StringVar(&g.output, "o", "table", "shorthand for --output")
+	fs.DurationVar(&g.timeout, "timeout", defaultTimeout, "the time allowed for the command")
+	return fs, g
+}
+
+// parseFlags parses args allowing flags and positional
+// arguments to be interleaved, and returns the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, usagef("%v", err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// client returns a client of the cluster selected by the global
+// flags and a function releasing its context. The output format is
+// checked first, so that no request is made for a misspelt command.
+func (c *cli) client(g *globalFlags) (*sf.Client, context.CancelFunc, error) {
+	if err := checkOutput(g.output); err != nil {
+		return nil, nil, err
+	}
+	p, err := c.profile(g)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if p.CertFile != "" || p.CAFile != "" || p.Insecure {
+		tlsConfig, err = p.tlsConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	client, err := sf.NewClient(&http.Client{}, p.Endpoint, p.APIVersion, tlsConfig)
+	if err != nil {
+		return nil, nil, &configError{err: err}
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if g.timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), g.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	return client.WithContext(ctx), cancel, nil
+}
+
+// profile returns the profile selected by the global flags, with
+// the endpoint and API version overridden by the flags when set
+func (c *cli) profile(g *globalFlags) (profile, error) {
+	path := firstNonEmpty(g.config, c.getenv("SFGO_CONFIG"))
+	explicitPath := path != ""
+	if !explicitPath {
+		dir, err := os.UserConfigDir()
+		if err == nil {
+			path = filepath.Join(dir, "sfgo", "config.json")
+		}
+	}
+
+	var cfg config
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case os.IsNotExist(err) && !explicitPath:
+		case err != nil:
+			return profile{}, configErrorf("could not read config %s: %v", path, err)
+		default:
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return profile{}, configErrorf("could not deserialise config %s: %v", path, err)
+			}
+		}
+	}
+
+	var p profile
+	name := firstNonEmpty(g.profile, c.getenv("SFGO_PROFILE"), cfg.DefaultProfile)
+	if name != "" {
+		var ok bool
+		p, ok = cfg.Profiles[name]
+		if !ok {
+			return profile{}, configErrorf("no profile %q in config %s", name, path)
+		}
+		p.resolvePaths(filepath.Dir(path))
+	}
+
+	p.Endpoint = firstNonEmpty(g.endpoint, p.Endpoint)
+	p.APIVersion = firstNonEmpty(g.apiVersion, p.APIVersion)
+	if p.Endpoint == "" {
+		return profile{}, configErrorf("no cluster endpoint, set --endpoint or configure a profile")
+	}
+	return p, nil
+}
+
+// resolvePaths makes relative certificate paths relative to dir
+func (p *profile) resolvePaths(dir string) {
+	for _, path := range []*string{&p.CertFile, &p.KeyFile, &p.CAFile} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+}
+
+func (p profile) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: p.Insecure}
+
+	if p.CertFile != "" {
+		keyFile := firstNonEmpty(p.KeyFile, p.CertFile)
+		cert, err := tls.LoadX509KeyPair(p.CertFile, keyFile)
+		if err != nil {
+			return nil, configErrorf("could not load client certificate %s: %v", p.CertFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if p.CAFile != "" {
+		pem, err := ioutil.ReadFile(p.CAFile)
+		if err != nil {
+			return nil, configErrorf("could not read CA certificates %s: %v", p.CAFile, err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, configErrorf("no certificates found in %s", p.CAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+	return tlsConfig, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+// Command sfgo is a command-line client for the Service Fabric
+// management API built on the servicefabric package.
+//
+// Usage:
+//
+//	sfgo app list [--type NAME]
+//	sfgo service list --app APP [--type NAME]
+//	sfgo partition list --service SERVICE
+//	sfgo replica list --partition PARTITION
+//	sfgo property get NAME [PROPERTY]
+//	sfgo property set NAME PROPERTY VALUE
+//	sfgo labels --app APP --service SERVICE [--prefix PREFIX]
+//
+// Every command accepts --profile, --config, --endpoint, --api-version,
+// --timeout and --output (table, json or yaml). Cluster profiles are read
+// from a JSON config file, by default sfgo/config.json in the user's
+// config directory:
+//
+//	{
+//	  "defaultProfile": "dev",
+//	  "profiles": {
+//	    "dev": {
+//	      "endpoint": "https://dev.example.com:19080",
+//	      "apiVersion": "6.0",
+//	      "certFile": "dev.crt",
+//	      "keyFile": "dev.key",
+//	      "caFile": "dev-ca.crt"
+//	    }
+//	  }
+//	}
+//
+// Relative certificate paths are resolved from the directory of the
+// config file. The exit code reflects the kind of error, see the exit
+// code constants. Build a static binary with CGO_ENABLED=0.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// Exit codes reflecting the kind of error
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUsage        = 2
+	exitConfig       = 3
+	exitConnection   = 4
+	exitNotFound     = 5
+	exitUnauthorized = 6
+	exitCluster      = 7
+	exitUnsupported  = 8
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Getenv))
+}
+
+// cli the environment a command runs in
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(key string) string
+}
+
+// command a single sfgo command
+type command struct {
+	// usage the arguments of the command
+	usage string
+	// summary describes the command
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"app list":       {"[--type NAME]", "list applications", appList},
+	"service list":   {"--app APP [--type NAME]", "list the services of an application", serviceList},
+	"partition list": {"--service SERVICE", "list the partitions of a service", partitionList},
+	"replica list":   {"--partition PARTITION", "list the replicas or instances of a partition", replicaList},
+	"property get":   {"NAME [PROPERTY]", "get the string properties of a name, or a single property", propertyGet},
+	"property set":   {"NAME PROPERTY VALUE", "set a string property of a name", propertySet},
+	"labels":         {"--app APP --service SERVICE [--prefix PREFIX]", "resolve the labels of a service", labels},
+}
+
+// usageError a command line which could not be parsed
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// configError a config file or profile which could not be used
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+func (e *configError) Unwrap() error {
+	return e.err
+}
+
+func configErrorf(format string, args ...interface{}) error {
+	return &configError{err: fmt.Errorf(format, args...)}
+}
+
+// run runs the command line args and returns the exit code
+func run(args []string, stdout, stderr io.Writer, getenv func(key string) string) int {
+	c := &cli{stdout: stdout, stderr: stderr, getenv: getenv}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	name := args[0]
+	cmd, ok := commands[name]
+	if !ok && len(args) > 1 {
+		name = args[0] + " " + args[1]
+		cmd, ok = commands[name]
+	}
+	if !ok {
+		fmt.Fprintf(stderr, "sfgo: unknown command %q\n\n", strings.Join(args, " "))
+		c.usage()
+		return exitUsage
+	}
+
+	err := cmd.run(c, args[len(strings.Fields(name)):])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "sfgo %s: %v\n", name, err)
+		if _, ok := err.(*usageError); ok {
+			fmt.Fprintf(stderr, "usage: sfgo %s %s\n", name, cmd.usage)
+		}
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// exitCode returns the exit code reflecting the kind of err
+func exitCode(err error) int {
+	var usageErr *usageError
+	var configErr *configError
+	var responseErr *sf.ResponseError
+	var urlErr *url.Error
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &configErr):
+		return exitConfig
+	case errors.Is(err, sf.ErrUnsupportedByCluster):
+		return exitUnsupported
+	case errors.As(err, &responseErr):
+		switch responseErr.StatusCode {
+		case 401, 403:
+			return exitUnauthorized
+		case 404:
+			return exitNotFound
+		}
+		return exitCluster
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return exitConnection
+	}
+	return exitError
+}
+
+func (c *cli) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(c.stderr, "usage: sfgo COMMAND [flags]")
+	fmt.Fprintln(c.stderr, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(c.stderr, "  %-16s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(c.stderr, "\nRun sfgo COMMAND --help for the flags of a command.")
+}
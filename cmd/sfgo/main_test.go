@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sf "github.com/jjcollinge/servicefabric"
+	"github.com/jjcollinge/servicefabric/sftest"
+)
+
+const testPartitionID = "bce46a8c-b62d-4996-89dc-7ffc00a96902"
+
+func newTestCluster() *sftest.Cluster {
+	cluster := sftest.NewCluster()
+	cluster.AddApplication(sf.ApplicationItem{Name: "fabric:/TestApplication", TypeName: "TestApplicationType", TypeVersion: "1.0.0", Status: "Ready", HealthState: "Ok"})
+	cluster.AddService("fabric:/TestApplication", sf.ServiceItem{Name: "fabric:/TestApplication/TestService", ServiceKind: "Stateful", TypeName: "TestServiceType", ServiceStatus: "Active", HealthState: "Ok"})
+	cluster.AddPartition("fabric:/TestApplication/TestService", sf.PartitionItem{
+		PartitionInformation: sf.PartitionInformation{ID: testPartitionID, ServicePartitionKind: "Singleton"},
+		PartitionStatus:      "Ready",
+		HealthState:          "Ok",
+	})
+	cluster.AddReplica(testPartitionID, sf.ReplicaItem{
+		ID:              "131496928082309293",
+		ReplicaItemBase: &sf.ReplicaItemBase{NodeName: "_Node_0", ReplicaRole: "Primary", ReplicaStatus: "Ready", HealthState: "Ok", Address: "http://10.0.0.4:8080", ServiceKind: "Stateful"},
+	})
+	cluster.AddServiceType("TestApplicationType", "1.0.0", sf.ServiceType{
+		ServiceManifestName:    "TestServicePkg",
+		ServiceTypeDescription: sf.ServiceTypeDescription{ServiceTypeName: "TestServiceType", IsStateful: true},
+	})
+	return cluster
+}
+
+// runCommand runs sfgo with args against the cluster and returns the
+// exit code and output, the environment is empty apart from env
+func runCommand(cluster *sftest.Cluster, env map[string]string, args ...string) (int, string, string) {
+	if cluster != nil {
+		args = append(args, "--endpoint", cluster.URL())
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr, func(key string) string { return env[key] })
+	return code, stdout.String(), stderr.String()
+}
+
+func TestAppList(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	code, stdout, stderr := runCommand(cluster, nil, "app", "list")
+	if code != exitOK {
+		t.Fatalf("Got exit code %d, want %d: %s", code, exitOK, stderr)
+	}
+	expected := "NAME                     TYPE                 VERSION  STATUS  HEALTH\n" +
+		"fabric:/TestApplication  TestApplicationType  1.0.0    Ready   Ok\n"
+	if stdout != expected {
+		t.Errorf("Got %q, want %q", stdout, expected)
+	}
+}
+
+func TestServiceListJSON(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	code, stdout, stderr := runCommand(cluster, nil, "service", "list", "--app", "fabric:/TestApplication", "-o", "json")
+	if code != exitOK {
+		t.Fatalf("Got exit code %d, want %d: %s", code, exitOK, stderr)
+	}
+	if !strings.Contains(stdout, `"Name": "fabric:/TestApplication/TestService"`) {
+		t.Errorf("Got %s, want the service in JSON", stdout)
+	}
+
+	code, stdout, _ = runCommand(cluster, nil, "service", "list", "--app", "TestApplication", "--type", "OtherServiceType", "-o", "json")
+	if code != exitOK || stdout != "[]\n" {
+		t.Errorf("Got %d %q, want an empty list", code, stdout)
+	}
+}
+
+func TestPartitionAndReplicaList(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	code, stdout, stderr := runCommand(cluster, nil, "partition", "list", "--service", "fabric:/TestApplication/TestService")
+	if code != exitOK {
+		t.Fatalf("Got exit code %d, want %d: %s", code, exitOK, stderr)
+	}
+	if !strings.Contains(stdout, testPartitionID) || !strings.Contains(stdout, "Singleton") {
+		t.Errorf("Got %s, want the partition", stdout)
+	}
+
+	code, stdout, stderr = runCommand(cluster, nil, "replica", "list", "--partition", testPartitionID, "-o", "yaml")
+	if code != exitOK {
+		t.Fatalf("Got exit code %d, want %d: %s", code, exitOK, stderr)
+	}
+	for _, expected := range []string{"- Address: http://10.0.0.4:8080\n", "  NodeName: _Node_0\n", "  ReplicaId: \"131496928082309293\"\n"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Got %s, want it to contain %q", stdout, expected)
+		}
+	}
+}
+
+func TestPropertySetAndGet(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	// The output format is checked before the property is set
+	code, _, _ := runCommand(cluster, nil, "property", "set", "fabric:/TestApplication/TestService", "traefik.enable", "true", "-o", "xml")
+	if _, ok := cluster.Property("TestApplication/TestService", "traefik.enable"); code != exitUsage || ok {
+		t.Errorf("Got exit code %d, want %d and the property not set", code, exitUsage)
+	}
+
+	code, _, stderr := runCommand(cluster, nil, "property", "set", "fabric:/TestApplication/TestService", "traefik.enable", "true")
+	if code != exitOK {
+		t.Fatalf("Got exit code %d, want %d: %s", code, exitOK, stderr)
+	}
+	if value, ok := cluster.Property("TestApplication/TestService", "traefik.enable"); !ok || value.Data != "true" {
+		t.Errorf("Got %+v, want the property set", value)
+	}
+
+	code, stdout, _ := runCommand(cluster, nil, "property", "get", "TestApplication~TestService", "traefik.enable", "-o", "json")
+	if code != exitOK || !strings.Contains(stdout, `"Data": "true"`) {
+		t.Errorf("Got %d %s, want the property", code, stdout)
+	}
+
+	code, stdout, _ = runCommand(cluster, nil, "property", "get", "fabric:/TestApplication/TestService")
+	expected := "NAME            VALUE\ntraefik.enable  true\n"
+	if code != exitOK || stdout != expected {
+		t.Errorf("Got %d %q, want %q", code, stdout, expected)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.SetProperty("TestApplication/TestService", "traefik.frontend.rule", "Host:example.com")
+
+	code, stdout, stderr := runCommand(cluster, nil, "labels", "--app", "TestApplication", "--service", "fabric:/TestApplication/TestService")
+	if code != exitOK {
+		t.Fatalf("Got exit code %d, want %d: %s", code, exitOK, stderr)
+	}
+	if !strings.Contains(stdout, "frontend.rule  Host:example.com  Property") {
+		t.Errorf("Got %s, want the property label", stdout)
+	}
+
+	code, stdout, _ = runCommand(cluster, nil, "labels", "--app", "TestApplication", "--service", "fabric:/TestApplication/TestService", "-o", "json")
+	if code != exitOK || !strings.Contains(stdout, `"RawValue": "Host:example.com"`) {
+		t.Errorf("Got %d %s, want the raw label value", code, stdout)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	closed := sftest.NewCluster()
+	closed.Close()
+
+	dir, err := ioutil.TempDir("", "sfgo")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.json")
+	config := `{"defaultProfile": "test", "profiles": {"test": {"endpoint": "` + cluster.URL() + `"}}}`
+	if err := ioutil.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cluster  *sftest.Cluster
+		env      map[string]string
+		args     []string
+		expected int
+	}{
+		{"no command", nil, nil, nil, exitUsage},
+		{"unknown command", nil, nil, []string{"node", "list"}, exitUsage},
+		{"missing flag", cluster, nil, []string{"service", "list"}, exitUsage},
+		{"unknown output", cluster, nil, []string{"app", "list", "-o", "xml"}, exitUsage},
+		{"unknown output before connecting", closed, nil, []string{"app", "list", "-o", "bogus"}, exitUsage},
+		{"no endpoint", nil, nil, []string{"app", "list"}, exitConfig},
+		{"missing config", nil, map[string]string{"SFGO_CONFIG": filepath.Join(dir, "missing.json")}, []string{"app", "list"}, exitConfig},
+		{"missing profile", nil, map[string]string{"SFGO_CONFIG": configPath}, []string{"app", "list", "--profile", "prod"}, exitConfig},
+		{"profile", nil, map[string]string{"SFGO_CONFIG": configPath}, []string{"app", "list"}, exitOK},
+		{"not found", cluster, nil, []string{"partition", "list", "--service", "TestApplication/MissingService"}, exitNotFound},
+		{"missing name", cluster, nil, []string{"property", "get", "TestApplication/MissingService"}, exitNotFound},
+		{"connection refused", closed, nil, []string{"app", "list"}, exitConnection},
+	}
+
+	for _, test := range tests {
+		code, _, stderr := runCommand(test.cluster, test.env, test.args...)
+		if code != test.expected {
+			t.Errorf("%s: got exit code %d, want %d: %s", test.name, code, test.expected, stderr)
+		}
+	}
+}
+
+func TestNotFoundMessages(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	for _, test := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"property", "get", "TestApplication/MissingService"}, "404 Not Found: FABRIC_E_NAME_DOES_NOT_EXIST: name TestApplication/MissingService does not exist\n"},
+		{[]string{"labels", "--app", "MissingApplication", "--service", "MissingApplication/TestService"}, "404 Not Found: FABRIC_E_APPLICATION_NOT_FOUND: application fabric:/MissingApplication not found\n"},
+	} {
+		if _, _, stderr := runCommand(cluster, nil, test.args...); !strings.HasSuffix(stderr, test.expected) {
+			t.Errorf("Got %q, want it to end with %q", stderr, test.expected)
+		}
+	}
+}
+
+func TestClusterErrorExitCodes(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	cluster.InjectFault(sftest.Fault{Path: "Applications", StatusCode: 403, ErrorCode: "FABRIC_E_ACCESS_DENIED"})
+	if code, _, _ := runCommand(cluster, nil, "app", "list"); code != exitUnauthorized {
+		t.Errorf("Got exit code %d, want %d", code, exitUnauthorized)
+	}
+
+	cluster.ClearFaults()
+	cluster.InjectFault(sftest.Fault{Path: "Applications", ErrorCode: "FABRIC_E_COMMUNICATION_ERROR"})
+	if code, _, _ := runCommand(cluster, nil, "app", "list"); code != exitCluster {
+		t.Errorf("Got exit code %d, want %d", code, exitCluster)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table the rows printed by the table output format
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// print writes value to stdout in format, the table output
+// format prints t rather than value
+func (c *cli) print(format string, value interface{}, t table) error {
+	// An empty list is printed as such rather than as null
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && v.IsNil() {
+		value = []interface{}{}
+	}
+
+	switch format {
+	case outputTable:
+		return t.write(c)
+	case outputJSON:
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(c.stdout, "%s\n", data)
+		return err
+	case outputYAML:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := jsonToYAML(&buf, data); err != nil {
+			return err
+		}
+		_, err = c.stdout.Write(buf.Bytes())
+		return err
+	}
+	return checkOutput(format)
+}
+
+// checkOutput returns a usage error unless format is an output format
+func checkOutput(format string) error {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return usagef("unknown output format %q, expected table, json or yaml", format)
+}
+
+func (t table) write(c *cli) error {
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// yamlMember a member of a JSON object, kept in document order
+type yamlMember struct {
+	key   string
+	value interface{}
+}
+
+// yamlObject a JSON object in document order
+type yamlObject []yamlMember
+
+// jsonToYAML writes the JSON document data to w as YAML. Objects keep
+// the order of their members, so the output matches the JSON output.
+func jsonToYAML(w io.Writer, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, value, 0)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := yamlObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, yamlMember{key: key.(string), value: value})
+		}
+		_, err = dec.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = dec.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// writeYAML writes value in block style at indent. Collections
+// are started on a new line by the caller, scalars are not.
+func writeYAML(buf *bytes.Buffer, value interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch v := value.(type) {
+	case yamlObject:
+		if len(v) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		for _, member := range v {
+			buf.WriteString(pad + yamlString(member.key) + ":")
+			writeYAMLValue(buf, member.value, indent+1)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			buf.WriteString(pad + "-")
+			writeYAMLItem(buf, item, indent+1)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value of a mapping entry
+func writeYAMLValue(buf *bytes.Buffer, value interface{}, indent int) {
+	switch v := value.(type) {
+	case yamlObject:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAML(buf, v, indent)
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAML(buf, v, indent)
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLItem writes a sequence item, a mapping item
+// starts on the same line as its dash
+func writeYAMLItem(buf *bytes.Buffer, value interface{}, indent int) {
+	object, ok := value.(yamlObject)
+	if !ok || len(object) == 0 {
+		writeYAMLValue(buf, value, indent)
+		return
+	}
+
+	var nested bytes.Buffer
+	writeYAML(&nested, object, indent)
+	buf.WriteString(" ")
+	buf.Write(bytes.TrimLeft(nested.Bytes(), " "))
+}
+
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// yamlString returns s plain when a YAML parser would read it back
+// as the same string, and double quoted otherwise
+func yamlString(s string) string {
+	if yamlPlainSafe(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+func yamlPlainSafe(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o") || strings.HasPrefix(s, ".") {
+		return false
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
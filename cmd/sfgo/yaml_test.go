@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSONToYAML(t *testing.T) {
+	data := `{"Name":"fabric:/App","Empty":{},"List":[],"Count":3,"Enabled":true,"Missing":null,` +
+		`"Ambiguous":["true","1.5","","- dash","key: value","line\nbreak"],` +
+		`"Items":[{"Id":"a","Tags":["x"]},{"Id":"b","Nested":{"Kind":"Int64Range"}}]}`
+	expected := `Name: fabric:/App
+Empty: {}
+List: []
+Count: 3
+Enabled: true
+Missing: null
+Ambiguous:
+  - "true"
+  - "1.5"
+  - ""
+  - "- dash"
+  - "key: value"
+  - "line\nbreak"
+Items:
+  - Id: a
+    Tags:
+      - x
+  - Id: b
+    Nested:
+      Kind: Int64Range
+`
+
+	var buf bytes.Buffer
+	if err := jsonToYAML(&buf, []byte(data)); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("Got\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
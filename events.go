@@ -0,0 +1,437 @@
+package servicefabric
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// eventStoreTimeFormat the format of the start and end
+// times accepted by the EventStore API
+const eventStoreTimeFormat = "2006-01-02T15:04:05Z"
+
+// EventKind the kind of an EventStore event
+type EventKind string
+
+// Event kinds as returned in the Kind field of an EventStore event
+const (
+	EventKindClusterUpgradeStarted               EventKind = "ClusterUpgradeStarted"
+	EventKindClusterUpgradeCompleted             EventKind = "ClusterUpgradeCompleted"
+	EventKindClusterUpgradeRollbackStarted       EventKind = "ClusterUpgradeRollbackStarted"
+	EventKindClusterUpgradeRollbackCompleted     EventKind = "ClusterUpgradeRollbackCompleted"
+	EventKindNodeAddedToCluster                  EventKind = "NodeAddedToCluster"
+	EventKindNodeRemovedFromCluster              EventKind = "NodeRemovedFromCluster"
+	EventKindNodeDown                            EventKind = "NodeDown"
+	EventKindNodeUp                              EventKind = "NodeUp"
+	EventKindApplicationCreated                  EventKind = "ApplicationCreated"
+	EventKindApplicationDeleted                  EventKind = "ApplicationDeleted"
+	EventKindApplicationUpgradeStarted           EventKind = "ApplicationUpgradeStarted"
+	EventKindApplicationUpgradeCompleted         EventKind = "ApplicationUpgradeCompleted"
+	EventKindApplicationUpgradeRollbackStarted   EventKind = "ApplicationUpgradeRollbackStarted"
+	EventKindApplicationUpgradeRollbackCompleted EventKind = "ApplicationUpgradeRollbackCompleted"
+	EventKindServiceCreated                      EventKind = "ServiceCreated"
+	EventKindServiceDeleted                      EventKind = "ServiceDeleted"
+	EventKindPartitionReconfigured               EventKind = "PartitionReconfigured"
+)
+
+// Event is implemented by every event returned by the EventStore.
+// Events are decoded into their concrete types by Kind, so use a type
+// switch to read the fields of a specific kind of event.
+type Event interface {
+	// GetEventBase returns the fields shared by every event
+	GetEventBase() *EventBase
+}
+
+// EventBase the fields shared by every EventStore event
+type EventBase struct {
+	Kind                EventKind `json:"Kind"`
+	EventInstanceID     string    `json:"EventInstanceId"`
+	Category            string    `json:"Category"`
+	TimeStamp           time.Time `json:"TimeStamp"`
+	HasCorrelatedEvents bool      `json:"HasCorrelatedEvents"`
+}
+
+// GetEventBase returns the fields shared by every event
+func (e *EventBase) GetEventBase() *EventBase {
+	return e
+}
+
+// NodeEventBase the fields shared by every node event
+type NodeEventBase struct {
+	EventBase
+	NodeName string `json:"NodeName"`
+}
+
+// ApplicationEventBase the fields shared by every application event
+type ApplicationEventBase struct {
+	EventBase
+	ApplicationID string `json:"ApplicationId"`
+}
+
+// ServiceEventBase the fields shared by every service event
+type ServiceEventBase struct {
+	EventBase
+	ServiceID string `json:"ServiceId"`
+}
+
+// PartitionEventBase the fields shared by every partition event
+type PartitionEventBase struct {
+	EventBase
+	PartitionID string `json:"PartitionId"`
+}
+
+// ClusterUpgradeStartedEvent a cluster upgrade has started
+type ClusterUpgradeStartedEvent struct {
+	EventBase
+	CurrentClusterVersion string `json:"CurrentClusterVersion"`
+	TargetClusterVersion  string `json:"TargetClusterVersion"`
+	UpgradeType           string `json:"UpgradeType"`
+	RollingUpgradeMode    string `json:"RollingUpgradeMode"`
+	FailureAction         string `json:"FailureAction"`
+}
+
+// ClusterUpgradeCompletedEvent a cluster upgrade has completed
+type ClusterUpgradeCompletedEvent struct {
+	EventBase
+	TargetClusterVersion          string  `json:"TargetClusterVersion"`
+	OverallUpgradeElapsedTimeInMs float64 `json:"OverallUpgradeElapsedTimeInMs"`
+}
+
+// ClusterUpgradeRollbackStartedEvent a failed cluster upgrade has started rolling back
+type ClusterUpgradeRollbackStartedEvent struct {
+	EventBase
+	TargetClusterVersion          string  `json:"TargetClusterVersion"`
+	FailureReason                 string  `json:"FailureReason"`
+	OverallUpgradeElapsedTimeInMs float64 `json:"OverallUpgradeElapsedTimeInMs"`
+}
+
+// ClusterUpgradeRollbackCompletedEvent a failed cluster upgrade has been rolled back
+type ClusterUpgradeRollbackCompletedEvent struct {
+	EventBase
+	TargetClusterVersion          string  `json:"TargetClusterVersion"`
+	FailureReason                 string  `json:"FailureReason"`
+	OverallUpgradeElapsedTimeInMs float64 `json:"OverallUpgradeElapsedTimeInMs"`
+}
+
+// NodeAddedToClusterEvent a node has joined the cluster
+type NodeAddedToClusterEvent struct {
+	NodeEventBase
+	NodeID          string `json:"NodeId"`
+	NodeInstance    int64  `json:"NodeInstance"`
+	NodeType        string `json:"NodeType"`
+	FabricVersion   string `json:"FabricVersion"`
+	IPAddressOrFQDN string `json:"IpAddressOrFQDN"`
+	NodeCapacities  string `json:"NodeCapacities"`
+}
+
+// NodeRemovedFromClusterEvent a node has been removed from the cluster
+type NodeRemovedFromClusterEvent struct {
+	NodeEventBase
+	NodeID          string `json:"NodeId"`
+	NodeInstance    int64  `json:"NodeInstance"`
+	NodeType        string `json:"NodeType"`
+	FabricVersion   string `json:"FabricVersion"`
+	IPAddressOrFQDN string `json:"IpAddressOrFQDN"`
+	NodeCapacities  string `json:"NodeCapacities"`
+}
+
+// NodeDownEvent a node has gone down
+type NodeDownEvent struct {
+	NodeEventBase
+	NodeInstance int64     `json:"NodeInstance"`
+	LastNodeUpAt time.Time `json:"LastNodeUpAt"`
+}
+
+// NodeUpEvent a node has come up
+type NodeUpEvent struct {
+	NodeEventBase
+	NodeInstance   int64     `json:"NodeInstance"`
+	LastNodeDownAt time.Time `json:"LastNodeDownAt"`
+}
+
+// ApplicationCreatedEvent an application has been created
+type ApplicationCreatedEvent struct {
+	ApplicationEventBase
+	ApplicationTypeName       string `json:"ApplicationTypeName"`
+	ApplicationTypeVersion    string `json:"ApplicationTypeVersion"`
+	ApplicationDefinitionKind string `json:"ApplicationDefinitionKind"`
+}
+
+// ApplicationDeletedEvent an application has been deleted
+type ApplicationDeletedEvent struct {
+	ApplicationEventBase
+	ApplicationTypeName    string `json:"ApplicationTypeName"`
+	ApplicationTypeVersion string `json:"ApplicationTypeVersion"`
+}
+
+// ApplicationUpgradeStartedEvent an application upgrade has started
+type ApplicationUpgradeStartedEvent struct {
+	ApplicationEventBase
+	ApplicationTypeName           string `json:"ApplicationTypeName"`
+	CurrentApplicationTypeVersion string `json:"CurrentApplicationTypeVersion"`
+	ApplicationTypeVersion        string `json:"ApplicationTypeVersion"`
+	UpgradeType                   string `json:"UpgradeType"`
+	RollingUpgradeMode            string `json:"RollingUpgradeMode"`
+	FailureAction                 string `json:"FailureAction"`
+}
+
+// ApplicationUpgradeCompletedEvent an application upgrade has completed
+type ApplicationUpgradeCompletedEvent struct {
+	ApplicationEventBase
+	ApplicationTypeName           string  `json:"ApplicationTypeName"`
+	ApplicationTypeVersion        string  `json:"ApplicationTypeVersion"`
+	OverallUpgradeElapsedTimeInMs float64 `json:"OverallUpgradeElapsedTimeInMs"`
+}
+
+// ApplicationUpgradeRollbackStartedEvent a failed application upgrade has started rolling back
+type ApplicationUpgradeRollbackStartedEvent struct {
+	ApplicationEventBase
+	ApplicationTypeName           string  `json:"ApplicationTypeName"`
+	CurrentApplicationTypeVersion string  `json:"CurrentApplicationTypeVersion"`
+	ApplicationTypeVersion        string  `json:"ApplicationTypeVersion"`
+	FailureReason                 string  `json:"FailureReason"`
+	OverallUpgradeElapsedTimeInMs float64 `json:"OverallUpgradeElapsedTimeInMs"`
+}
+
+// ApplicationUpgradeRollbackCompletedEvent a failed application upgrade has been rolled back
+type ApplicationUpgradeRollbackCompletedEvent struct {
+	ApplicationEventBase
+	ApplicationTypeName           string  `json:"ApplicationTypeName"`
+	ApplicationTypeVersion        string  `json:"ApplicationTypeVersion"`
+	FailureReason                 string  `json:"FailureReason"`
+	OverallUpgradeElapsedTimeInMs float64 `json:"OverallUpgradeElapsedTimeInMs"`
+}
+
+// ServiceCreatedEvent a service has been created
+type ServiceCreatedEvent struct {
+	ServiceEventBase
+	ServiceTypeName       string `json:"ServiceTypeName"`
+	ApplicationName       string `json:"ApplicationName"`
+	ApplicationTypeName   string `json:"ApplicationTypeName"`
+	ServiceInstance       int64  `json:"ServiceInstance"`
+	IsStateful            bool   `json:"IsStateful"`
+	PartitionCount        int64  `json:"PartitionCount"`
+	TargetReplicaSetSize  int64  `json:"TargetReplicaSetSize"`
+	MinReplicaSetSize     int64  `json:"MinReplicaSetSize"`
+	ServicePackageVersion string `json:"ServicePackageVersion"`
+	PartitionID           string `json:"PartitionId"`
+}
+
+// ServiceDeletedEvent a service has been deleted
+type ServiceDeletedEvent struct {
+	ServiceEventBase
+	ServiceTypeName       string `json:"ServiceTypeName"`
+	ApplicationName       string `json:"ApplicationName"`
+	ApplicationTypeName   string `json:"ApplicationTypeName"`
+	ServiceInstance       int64  `json:"ServiceInstance"`
+	IsStateful            bool   `json:"IsStateful"`
+	PartitionCount        int64  `json:"PartitionCount"`
+	TargetReplicaSetSize  int64  `json:"TargetReplicaSetSize"`
+	MinReplicaSetSize     int64  `json:"MinReplicaSetSize"`
+	ServicePackageVersion string `json:"ServicePackageVersion"`
+}
+
+// PartitionReconfiguredEvent a partition has completed a reconfiguration
+type PartitionReconfiguredEvent struct {
+	PartitionEventBase
+	NodeName               string  `json:"NodeName"`
+	NodeInstanceID         string  `json:"NodeInstanceId"`
+	ServiceType            string  `json:"ServiceType"`
+	CcEpochDataLossVersion int64   `json:"CcEpochDataLossVersion"`
+	CcEpochConfigVersion   int64   `json:"CcEpochConfigVersion"`
+	ReconfigType           string  `json:"ReconfigType"`
+	Result                 string  `json:"Result"`
+	Phase0DurationMs       float64 `json:"Phase0DurationMs"`
+	Phase1DurationMs       float64 `json:"Phase1DurationMs"`
+	Phase2DurationMs       float64 `json:"Phase2DurationMs"`
+	Phase3DurationMs       float64 `json:"Phase3DurationMs"`
+	Phase4DurationMs       float64 `json:"Phase4DurationMs"`
+	TotalDurationMs        float64 `json:"TotalDurationMs"`
+}
+
+// UnknownEvent holds an event whose kind is not known
+// to this client so it is not lost on decode
+type UnknownEvent struct {
+	EventBase
+	Raw json.RawMessage
+}
+
+// EventsOptions selects the events returned by the EventStore calls
+type EventsOptions struct {
+	// StartTime the start of the time window, required
+	StartTime time.Time
+	// EndTime the end of the time window, required
+	EndTime time.Time
+	// EventTypes only return events of these kinds
+	EventTypes []EventKind
+	// ExcludeAnalysisEvents omit analysis events
+	ExcludeAnalysisEvents bool
+	// SkipCorrelationLookup do not look up correlated events
+	SkipCorrelationLookup bool
+	// Timeout the server side timeout for the request,
+	// zero leaves the timeout up to the cluster
+	Timeout time.Duration
+}
+
+func (o EventsOptions) queryParams() queryParamsFunc {
+	params := []queryParamsFunc{
+		withParam("StartTimeUtc", o.StartTime.UTC().Format(eventStoreTimeFormat)),
+		withParam("EndTimeUtc", o.EndTime.UTC().Format(eventStoreTimeFormat)),
+	}
+	if len(o.EventTypes) > 0 {
+		kinds := make([]string, 0, len(o.EventTypes))
+		for _, kind := range o.EventTypes {
+			kinds = append(kinds, string(kind))
+		}
+		params = append(params, withParam("EventsTypesFilter", strings.Join(kinds, ",")))
+	}
+	if o.ExcludeAnalysisEvents {
+		params = append(params, withParam("ExcludeAnalysisEvents", "true"))
+	}
+	if o.SkipCorrelationLookup {
+		params = append(params, withParam("SkipCorrelationLookup", "true"))
+	}
+	if o.Timeout > 0 {
+		params = append(params, withTimeout(o.Timeout))
+	}
+	return withParams(params...)
+}
+
+// GetClusterEvents returns the cluster events within the time window of opts.
+func (c Client) GetClusterEvents(opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetClusterEvents, "EventsStore/Cluster/Events", opts)
+}
+
+// GetNodesEvents returns the events of every node within the time window of opts.
+func (c Client) GetNodesEvents(opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetNodesEvents, "EventsStore/Nodes/Events", opts)
+}
+
+// GetNodeEvents returns the events of a node within the time window of opts.
+func (c Client) GetNodeEvents(nodeName string, opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetNodeEvents, "EventsStore/Nodes/"+escapeSegment(nodeName)+"/$/Events", opts)
+}
+
+// GetApplicationsEvents returns the events of every
+// application within the time window of opts.
+func (c Client) GetApplicationsEvents(opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetApplicationsEvents, "EventsStore/Applications/Events", opts)
+}
+
+// GetApplicationEvents returns the events of the application
+// identified by applicationID within the time window of opts.
+func (c Client) GetApplicationEvents(applicationID ApplicationID, opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetApplicationEvents, "EventsStore/Applications/"+escapeSegment(applicationID.String())+"/$/Events", opts)
+}
+
+// GetServicesEvents returns the events of every
+// service within the time window of opts.
+func (c Client) GetServicesEvents(opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetServicesEvents, "EventsStore/Services/Events", opts)
+}
+
+// GetServiceEvents returns the events of the service
+// identified by serviceID within the time window of opts.
+func (c Client) GetServiceEvents(serviceID ServiceID, opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetServiceEvents, "EventsStore/Services/"+escapeSegment(serviceID.String())+"/$/Events", opts)
+}
+
+// GetPartitionsEvents returns the events of every
+// partition within the time window of opts.
+func (c Client) GetPartitionsEvents(opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetPartitionsEvents, "EventsStore/Partitions/Events", opts)
+}
+
+// GetPartitionEvents returns the events of the partition
+// identified by partitionID within the time window of opts.
+func (c Client) GetPartitionEvents(partitionID PartitionID, opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetPartitionEvents, "EventsStore/Partitions/"+escapeSegment(partitionID.String())+"/$/Events", opts)
+}
+
+// GetPartitionReplicasEvents returns the events of every replica of the
+// partition identified by partitionID within the time window of opts.
+func (c Client) GetPartitionReplicasEvents(partitionID PartitionID, opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetPartitionReplicasEvents, "EventsStore/Partitions/"+escapeSegment(partitionID.String())+"/$/Replicas/Events", opts)
+}
+
+// GetPartitionReplicaEvents returns the events of a replica of the
+// partition identified by partitionID within the time window of opts.
+func (c Client) GetPartitionReplicaEvents(partitionID PartitionID, replicaID string, opts EventsOptions) ([]Event, error) {
+	return c.getEvents(opGetPartitionReplicaEvents, "EventsStore/Partitions/"+escapeSegment(partitionID.String())+"/$/Replicas/"+escapeSegment(replicaID)+"/$/Events", opts)
+}
+
+func (c Client) getEvents(op operation, basePath string, opts EventsOptions) ([]Event, error) {
+	res, err := c.getHTTP(op, basePath, opts.queryParams())
+	if err != nil {
+		return nil, err
+	}
+
+	var raws []json.RawMessage
+	err = json.Unmarshal(res, &raws)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+
+	events := make([]Event, 0, len(raws))
+	for _, raw := range raws {
+		event, err := unmarshalEvent(raw)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func unmarshalEvent(raw json.RawMessage) (Event, error) {
+	var header EventBase
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("could not deserialise event: %+v", err)
+	}
+
+	var event Event
+	switch header.Kind {
+	case EventKindClusterUpgradeStarted:
+		event = &ClusterUpgradeStartedEvent{}
+	case EventKindClusterUpgradeCompleted:
+		event = &ClusterUpgradeCompletedEvent{}
+	case EventKindClusterUpgradeRollbackStarted:
+		event = &ClusterUpgradeRollbackStartedEvent{}
+	case EventKindClusterUpgradeRollbackCompleted:
+		event = &ClusterUpgradeRollbackCompletedEvent{}
+	case EventKindNodeAddedToCluster:
+		event = &NodeAddedToClusterEvent{}
+	case EventKindNodeRemovedFromCluster:
+		event = &NodeRemovedFromClusterEvent{}
+	case EventKindNodeDown:
+		event = &NodeDownEvent{}
+	case EventKindNodeUp:
+		event = &NodeUpEvent{}
+	case EventKindApplicationCreated:
+		event = &ApplicationCreatedEvent{}
+	case EventKindApplicationDeleted:
+		event = &ApplicationDeletedEvent{}
+	case EventKindApplicationUpgradeStarted:
+		event = &ApplicationUpgradeStartedEvent{}
+	case EventKindApplicationUpgradeCompleted:
+		event = &ApplicationUpgradeCompletedEvent{}
+	case EventKindApplicationUpgradeRollbackStarted:
+		event = &ApplicationUpgradeRollbackStartedEvent{}
+	case EventKindApplicationUpgradeRollbackCompleted:
+		event = &ApplicationUpgradeRollbackCompletedEvent{}
+	case EventKindServiceCreated:
+		event = &ServiceCreatedEvent{}
+	case EventKindServiceDeleted:
+		event = &ServiceDeletedEvent{}
+	case EventKindPartitionReconfigured:
+		event = &PartitionReconfiguredEvent{}
+	default:
+		return &UnknownEvent{EventBase: header, Raw: raw}, nil
+	}
+
+	if err := json.Unmarshal(raw, event); err != nil {
+		return nil, fmt.Errorf("could not deserialise %s event: %+v", header.Kind, err)
+	}
+	return event, nil
+}
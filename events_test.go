@@ -0,0 +1,106 @@
+package servicefabric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var (
+	testEventsStart = time.Date(2018, 4, 3, 18, 0, 0, 0, time.UTC)
+	testEventsEnd   = testEventsStart.Add(24 * time.Hour)
+)
+
+func TestGetClusterEvents(t *testing.T) {
+	server := httptest.NewServer(handleEvents())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	events, err := sfClient.GetClusterEvents(EventsOptions{StartTime: testEventsStart, EndTime: testEventsEnd})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Got %d events, want 4", len(events))
+	}
+
+	nodeDown, ok := events[0].(*NodeDownEvent)
+	if !ok {
+		t.Fatalf("Got %T, want *NodeDownEvent", events[0])
+	}
+	if nodeDown.NodeName != "_Node_0" || nodeDown.NodeInstance != 131738445515291788 {
+		t.Errorf("Got %+v, want node _Node_0 instance 131738445515291788", nodeDown)
+	}
+	expectedUpAt := time.Date(2018, 4, 3, 20, 12, 59, 252559800, time.UTC)
+	if !nodeDown.LastNodeUpAt.Equal(expectedUpAt) {
+		t.Errorf("Got %v, want %v", nodeDown.LastNodeUpAt, expectedUpAt)
+	}
+
+	upgrade, ok := events[1].(*ApplicationUpgradeStartedEvent)
+	if !ok {
+		t.Fatalf("Got %T, want *ApplicationUpgradeStartedEvent", events[1])
+	}
+	if upgrade.ApplicationID != "TestApplication" || upgrade.ApplicationTypeVersion != "1.0.1" {
+		t.Errorf("Got %+v, want TestApplication upgrading to 1.0.1", upgrade)
+	}
+
+	reconfigured, ok := events[2].(*PartitionReconfiguredEvent)
+	if !ok {
+		t.Fatalf("Got %T, want *PartitionReconfiguredEvent", events[2])
+	}
+	if reconfigured.ReconfigType != "Failover" || !reconfigured.HasCorrelatedEvents {
+		t.Errorf("Got %+v, want a correlated failover", reconfigured)
+	}
+
+	unknown, ok := events[3].(*UnknownEvent)
+	if !ok {
+		t.Fatalf("Got %T, want *UnknownEvent", events[3])
+	}
+	if unknown.Kind != "PartitionNewHealthReport" || len(unknown.Raw) == 0 {
+		t.Errorf("Got %+v, want the raw PartitionNewHealthReport event", unknown)
+	}
+
+	for _, event := range events {
+		if event.GetEventBase().EventInstanceID == "" {
+			t.Errorf("Got %+v, want an EventInstanceId", event)
+		}
+	}
+}
+
+func TestGetNodeEventsWithFilter(t *testing.T) {
+	server := httptest.NewServer(handleEvents())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	opts := EventsOptions{
+		StartTime:             testEventsStart.In(time.FixedZone("UTC+2", 2*60*60)),
+		EndTime:               testEventsEnd,
+		EventTypes:            []EventKind{EventKindNodeDown, EventKindNodeUp},
+		ExcludeAnalysisEvents: true,
+	}
+	events, err := sfClient.GetNodeEvents("_Node_0", opts)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(events) != 4 {
+		t.Errorf("Got %d events, want 4", len(events))
+	}
+}
+
+func TestGetPartitionEvents(t *testing.T) {
+	server := httptest.NewServer(handleEvents())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	events, err := sfClient.GetPartitionEvents("bce46a8c-b62d-4996-89dc-7ffc00a96902", EventsOptions{StartTime: testEventsStart, EndTime: testEventsEnd})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(events) != 4 {
+		t.Errorf("Got %d events, want 4", len(events))
+	}
+}
@@ -0,0 +1,396 @@
+// Package exporter exposes the topology and health of a Service Fabric
+// cluster as Prometheus metrics. The topology is walked periodically
+// rather than on every scrape of the metrics endpoint, within a budget
+// so that walking a large cluster does not overload its gateway.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+	"github.com/jjcollinge/servicefabric/internal/promtext"
+)
+
+const defaultInterval = time.Minute
+
+// Names of the calls timed by the call latency metric
+const (
+	callGetApplications         = "GetApplications"
+	callGetServices             = "GetServices"
+	callGetServicePartitions    = "GetServicePartitions"
+	callGetPartitionMembersByID = "GetPartitionMembersByID"
+)
+
+// errBudgetExhausted stops a walk which has made its maximum number of calls
+var errBudgetExhausted = errors.New("scrape budget exhausted")
+
+// Client the calls used to walk the cluster topology, implemented by *sf.Client
+type Client interface {
+	sf.ApplicationReader
+	sf.ServiceReader
+	sf.PartitionReader
+	sf.ReplicaReader
+}
+
+// Budget limits the load a single walk of the topology puts on the gateway.
+// When the client is an *sf.Client the budget is charged for each request
+// it sends, so each page of a paged list counts. Any other client is
+// charged for each call made to it.
+type Budget struct {
+	// MaxCalls the most requests made by a walk, zero for no limit
+	MaxCalls int
+	// MinCallInterval the least time between the start of two requests
+	MinCallInterval time.Duration
+	// Timeout the most time a walk may take, zero for no limit
+	Timeout time.Duration
+}
+
+// Options controls how often and how heavily the topology is walked
+type Options struct {
+	// Interval the time between the start of two walks, defaults to a minute
+	Interval time.Duration
+	// Budget limits each walk. A walk which runs out of budget
+	// exports the topology it has seen and is not counted as a
+	// success, so raise the budget if that happens on every walk.
+	Budget Budget
+}
+
+// topology the metrics of a single walk of the
+// topology and the budget the walk has left
+type topology struct {
+	applications   *promtext.Vec
+	services       *promtext.Vec
+	partitions     *promtext.Vec
+	replicas       *promtext.Vec
+	nodeReplicas   *promtext.Vec
+	notReady       *promtext.Vec
+	calls          int
+	duration       time.Duration
+	truncated      bool
+	lastCallStart  time.Time
+	callsRemaining int
+	// perRequest whether the budget is charged for each request
+	// by a budgetObserver rather than for each call
+	perRequest bool
+	// exhausted whether the walk ran out of budget
+	exhausted bool
+}
+
+func newTopology() *topology {
+	return &topology{
+		applications: promtext.NewVec("health_state", "status"),
+		services:     promtext.NewVec("health_state", "status"),
+		partitions:   promtext.NewVec("health_state", "status"),
+		replicas:     promtext.NewVec("health_state", "status", "role"),
+		nodeReplicas: promtext.NewVec("node"),
+		notReady:     promtext.NewVec("service", "partition", "status"),
+	}
+}
+
+// Exporter walks the topology of a cluster and serves its metrics
+type Exporter struct {
+	client Client
+	opts   Options
+	now    func() time.Time
+
+	mu          sync.Mutex
+	last        *topology
+	lastSuccess time.Time
+	scrapes     *promtext.Vec
+	callErrors  *promtext.Vec
+	latency     *promtext.HistogramVec
+}
+
+// New returns an exporter of the cluster client connects to
+func New(client Client, opts Options) *Exporter {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	return &Exporter{
+		client:     client,
+		opts:       opts,
+		now:        time.Now,
+		scrapes:    promtext.NewVec("result"),
+		callErrors: promtext.NewVec("call"),
+		latency:    promtext.NewHistogramVec("call"),
+	}
+}
+
+// Run walks the topology immediately and then every interval
+// until ctx is done, returning the context's error
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.Scrape(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scrape walks the topology once and replaces the exported topology
+// metrics with what it saw. The first error is returned, but the walk
+// carries on past failed calls and the rest of the topology is exported.
+func (e *Exporter) Scrape(ctx context.Context) error {
+	if e.opts.Budget.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.opts.Budget.Timeout)
+		defer cancel()
+	}
+
+	walkCtx, cancelWalk := context.WithCancel(ctx)
+	defer cancelWalk()
+
+	t := newTopology()
+	t.callsRemaining = e.opts.Budget.MaxCalls
+	client := e.client
+	if c, ok := client.(*sf.Client); ok {
+		t.perRequest = true
+		client = c.WithContext(walkCtx).WithObserver(&budgetObserver{e: e, t: t, ctx: walkCtx, cancel: cancelWalk})
+	}
+
+	start := e.now()
+	err := e.walk(walkCtx, client, t)
+	t.duration = e.now().Sub(start)
+	if t.exhausted {
+		err = errBudgetExhausted
+	}
+
+	result := "success"
+	switch {
+	case err == errBudgetExhausted || ctx.Err() == context.DeadlineExceeded:
+		t.truncated = true
+		result = "truncated"
+	case err != nil:
+		result = "error"
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.last = t
+	e.scrapes.Add(1, result)
+	if err == nil {
+		e.lastSuccess = e.now()
+	}
+	return err
+}
+
+func (e *Exporter) walk(ctx context.Context, client Client, t *topology) error {
+	var apps *sf.ApplicationItemsPage
+	err := e.call(ctx, t, callGetApplications, func() (err error) {
+		apps, err = client.GetApplications()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, app := range apps.Items {
+		t.applications.Add(1, app.HealthState, app.Status)
+
+		var services *sf.ServiceItemsPage
+		err := e.call(ctx, t, callGetServices, func() (err error) {
+			services, err = client.GetServices(app.ID)
+			return err
+		})
+		if stop(err) {
+			return err
+		}
+		if err != nil {
+			firstErr = firstError(firstErr, err)
+			continue
+		}
+
+		for _, service := range services.Items {
+			t.services.Add(1, service.HealthState, service.ServiceStatus)
+			err := e.walkService(ctx, client, t, service)
+			if stop(err) {
+				return err
+			}
+			firstErr = firstError(firstErr, err)
+		}
+	}
+	return firstErr
+}
+
+func (e *Exporter) walkService(ctx context.Context, client Client, t *topology, service sf.ServiceItem) error {
+	var partitions *sf.PartitionItemsPage
+	err := e.call(ctx, t, callGetServicePartitions, func() (err error) {
+		partitions, err = client.GetServicePartitions(sf.ServiceID(service.ID))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, partition := range partitions.Items {
+		partitionID := partition.PartitionInformation.ID
+		t.partitions.Add(1, partition.HealthState, partition.PartitionStatus)
+		if partition.PartitionStatus != "Ready" {
+			t.notReady.Set(1, service.Name, partitionID, partition.PartitionStatus)
+		}
+
+		var members []sf.PartitionMember
+		err := e.call(ctx, t, callGetPartitionMembersByID, func() (err error) {
+			members, err = client.GetPartitionMembersByID(sf.PartitionID(partitionID))
+			return err
+		})
+		if stop(err) {
+			return err
+		}
+		if err != nil {
+			firstErr = firstError(firstErr, err)
+			continue
+		}
+
+		for _, member := range members {
+			_, data := member.GetReplicaData()
+			if data == nil {
+				continue
+			}
+			t.replicas.Add(1, data.HealthState, string(data.ReplicaStatus), string(data.ReplicaRole))
+			t.nodeReplicas.Add(1, data.NodeName)
+		}
+	}
+	return firstErr
+}
+
+// call makes a call within the budget of the walk, timing it
+// and counting it as failed if it returns an error
+func (e *Exporter) call(ctx context.Context, t *topology, name string, f func() error) error {
+	if !t.perRequest {
+		if err := e.charge(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	start := e.now()
+	err := f()
+	elapsed := e.now().Sub(start)
+	if t.exhausted {
+		// The request the budget ran out at was cancelled
+		return errBudgetExhausted
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.latency.Observe(elapsed.Seconds(), name)
+	if err != nil {
+		e.callErrors.Add(1, name)
+	}
+	return err
+}
+
+// charge charges a request, or a call, against the budget of the walk,
+// first waiting until MinCallInterval has passed since the last one started
+func (e *Exporter) charge(ctx context.Context, t *topology) error {
+	if e.opts.Budget.MaxCalls > 0 {
+		if t.callsRemaining == 0 {
+			t.exhausted = true
+			return errBudgetExhausted
+		}
+		t.callsRemaining--
+	}
+
+	if wait := t.lastCallStart.Add(e.opts.Budget.MinCallInterval).Sub(e.now()); !t.lastCallStart.IsZero() && wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.lastCallStart = e.now()
+	t.calls++
+	return nil
+}
+
+// budgetObserver charges each request an *sf.Client sends during a walk
+// against the walk's budget, cancelling the walk once it runs out
+type budgetObserver struct {
+	e      *Exporter
+	t      *topology
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// StartRequest waits until req may be sent within the budget
+func (o *budgetObserver) StartRequest(req *http.Request, info sf.RequestInfo) func(result sf.RequestResult) {
+	if err := o.e.charge(o.ctx, o.t); err == errBudgetExhausted {
+		o.cancel()
+	}
+	return nil
+}
+
+// stop reports whether err ends the walk rather than just the
+// part of the topology the failed call would have walked
+func stop(err error) bool {
+	return err == errBudgetExhausted || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func firstError(first, err error) error {
+	if first != nil {
+		return first
+	}
+	return err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	var buf bytes.Buffer
+	e.writeMetrics(&buf)
+	w.Write(buf.Bytes())
+}
+
+func (e *Exporter) writeMetrics(w io.Writer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m := promtext.NewWriter(w)
+	if t := e.last; t != nil {
+		m.Vec("sf_applications", "gauge", "Applications by health state and status.", t.applications)
+		m.Vec("sf_services", "gauge", "Services by health state and status.", t.services)
+		m.Vec("sf_partitions", "gauge", "Partitions by health state and status.", t.partitions)
+		m.Vec("sf_replicas", "gauge", "Replicas and instances by health state, status and role.", t.replicas)
+		m.Vec("sf_node_replicas", "gauge", "Replicas and instances placed on each node.", t.nodeReplicas)
+		m.Vec("sf_partition_not_ready", "gauge", "Partitions whose status is not Ready.", t.notReady)
+		m.Gauge("sf_exporter_scrape_duration_seconds", "The time taken by the last walk of the topology.", t.duration.Seconds())
+		m.Gauge("sf_exporter_scrape_calls", "The requests made by the last walk of the topology.", float64(t.calls))
+		m.Gauge("sf_exporter_scrape_truncated", "Whether the last walk of the topology ran out of budget.", boolValue(t.truncated))
+	}
+	if !e.lastSuccess.IsZero() {
+		m.Gauge("sf_exporter_last_success_timestamp_seconds", "The time the last successful walk of the topology finished.", float64(e.lastSuccess.UnixNano())/1e9)
+		m.Gauge("sf_exporter_seconds_since_last_success", "The time since the last successful walk of the topology finished.", e.now().Sub(e.lastSuccess).Seconds())
+	}
+	m.Vec("sf_exporter_scrapes_total", "counter", "Walks of the topology by result.", e.scrapes)
+	m.Vec("sf_exporter_call_errors_total", "counter", "Calls to the cluster which failed.", e.callErrors)
+	m.HistogramVec("sf_exporter_call_duration_seconds", "The latency of calls to the cluster.", e.latency)
+	m.Flush()
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
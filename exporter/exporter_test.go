@@ -0,0 +1,230 @@
+package exporter
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+	"github.com/jjcollinge/servicefabric/sftest"
+)
+
+const (
+	readyPartitionID    = "bce46a8c-b62d-4996-89dc-7ffc00a96902"
+	notReadyPartitionID = "824091ba-fa32-4e9c-9e9c-71738e018312"
+)
+
+func newTestCluster() *sftest.Cluster {
+	cluster := sftest.NewCluster()
+	cluster.AddApplication(sf.ApplicationItem{Name: "fabric:/TestApplication", Status: "Ready", HealthState: "Ok"})
+	cluster.AddApplication(sf.ApplicationItem{Name: "fabric:/OtherApplication", Status: "Ready", HealthState: "Warning"})
+	cluster.AddService("fabric:/TestApplication", sf.ServiceItem{Name: "fabric:/TestApplication/TestService", ServiceKind: "Stateful", ServiceStatus: "Active", HealthState: "Ok"})
+	cluster.AddPartition("fabric:/TestApplication/TestService", sf.PartitionItem{
+		PartitionInformation: sf.PartitionInformation{ID: readyPartitionID, ServicePartitionKind: "Singleton"},
+		PartitionStatus:      "Ready",
+		HealthState:          "Ok",
+	})
+	cluster.AddPartition("fabric:/TestApplication/TestService", sf.PartitionItem{
+		PartitionInformation: sf.PartitionInformation{ID: notReadyPartitionID, ServicePartitionKind: "Singleton"},
+		PartitionStatus:      "InQuorumLoss",
+		HealthState:          "Error",
+	})
+	for i, node := range []string{"_Node_0", "_Node_1", "_Node_1"} {
+		role := sf.ReplicaRoleActiveSecondary
+		if i == 0 {
+			role = sf.ReplicaRolePrimary
+		}
+		cluster.AddReplica(readyPartitionID, sf.ReplicaItem{
+			ID:              strings.Repeat("1", i+1),
+			ReplicaItemBase: &sf.ReplicaItemBase{NodeName: node, ReplicaRole: role, ReplicaStatus: sf.ReplicaStatusReady, HealthState: "Ok", ServiceKind: "Stateful"},
+		})
+	}
+	return cluster
+}
+
+func metrics(e *Exporter) string {
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	return recorder.Body.String()
+}
+
+func assertMetrics(t *testing.T, body string, expected ...string) {
+	t.Helper()
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Got\n%s\nwant it to contain %q", body, line)
+		}
+	}
+}
+
+func TestScrapeExportsTopology(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	e := New(cluster.Client(), Options{})
+	if err := e.Scrape(context.Background()); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	assertMetrics(t, metrics(e),
+		`sf_applications{health_state="Ok",status="Ready"} 1`,
+		`sf_applications{health_state="Warning",status="Ready"} 1`,
+		`sf_services{health_state="Ok",status="Active"} 1`,
+		`sf_partitions{health_state="Error",status="InQuorumLoss"} 1`,
+		`sf_partitions{health_state="Ok",status="Ready"} 1`,
+		`sf_replicas{health_state="Ok",status="Ready",role="ActiveSecondary"} 2`,
+		`sf_replicas{health_state="Ok",status="Ready",role="Primary"} 1`,
+		`sf_node_replicas{node="_Node_0"} 1`,
+		`sf_node_replicas{node="_Node_1"} 2`,
+		`sf_partition_not_ready{service="fabric:/TestApplication/TestService",partition="`+notReadyPartitionID+`",status="InQuorumLoss"} 1`,
+		`sf_exporter_scrape_calls 6`,
+		`sf_exporter_scrape_truncated 0`,
+		`sf_exporter_scrapes_total{result="success"} 1`,
+		`sf_exporter_call_duration_seconds_count{call="GetPartitionMembersByID"} 2`,
+		`sf_exporter_call_duration_seconds_bucket{call="GetApplications",le="+Inf"} 1`,
+	)
+}
+
+func TestScrapeStopsWhenBudgetIsExhausted(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	e := New(cluster.Client(), Options{Budget: Budget{MaxCalls: 3}})
+	if err := e.Scrape(context.Background()); err != errBudgetExhausted {
+		t.Fatalf("Got %v, want %v", err, errBudgetExhausted)
+	}
+	if requests := cluster.Requests(); len(requests) != 3 {
+		t.Errorf("Got %d requests, want 3", len(requests))
+	}
+
+	body := metrics(e)
+	assertMetrics(t, body,
+		`sf_applications{health_state="Ok",status="Ready"} 1`,
+		`sf_exporter_scrape_calls 3`,
+		`sf_exporter_scrape_truncated 1`,
+		`sf_exporter_scrapes_total{result="truncated"} 1`,
+	)
+	if strings.Contains(body, "sf_exporter_seconds_since_last_success") {
+		t.Errorf("Got\n%s\nwant no successful scrape", body)
+	}
+}
+
+func TestScrapeChargesEachPage(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.SetPageSize(1)
+
+	// Both applications are listed a page at a time
+	e := New(cluster.Client(), Options{Budget: Budget{MaxCalls: 2}})
+	if err := e.Scrape(context.Background()); err != errBudgetExhausted {
+		t.Fatalf("Got %v, want %v", err, errBudgetExhausted)
+	}
+	if requests := cluster.Requests(); len(requests) != 2 {
+		t.Errorf("Got %d requests, want 2", len(requests))
+	}
+
+	body := metrics(e)
+	assertMetrics(t, body,
+		`sf_exporter_scrape_calls 2`,
+		`sf_exporter_scrape_truncated 1`,
+	)
+	if strings.Contains(body, "sf_exporter_call_errors_total{") {
+		t.Errorf("Got\n%s\nwant the cancelled request not counted as an error", body)
+	}
+}
+
+func TestScrapeSpacesPages(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.SetPageSize(1)
+
+	interval := 10 * time.Millisecond
+	e := New(cluster.Client(), Options{Budget: Budget{MinCallInterval: interval}})
+	start := time.Now()
+	if err := e.Scrape(context.Background()); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	requests := len(cluster.Requests())
+	if requests <= 6 {
+		t.Fatalf("Got %d requests, want more requests than calls", requests)
+	}
+	if elapsed := time.Since(start); elapsed < time.Duration(requests-1)*interval {
+		t.Errorf("Got %v, want %d requests at least %v apart", elapsed, requests, interval)
+	}
+	assertMetrics(t, metrics(e), `sf_exporter_scrape_calls `+strconv.Itoa(requests))
+}
+
+func TestScrapeStopsAtTimeout(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.SetLatency(50 * time.Millisecond)
+
+	e := New(cluster.Client(), Options{Budget: Budget{Timeout: 75 * time.Millisecond}})
+	if err := e.Scrape(context.Background()); err == nil {
+		t.Fatal("Got nil, want the scrape to time out")
+	}
+	assertMetrics(t, metrics(e),
+		`sf_exporter_scrape_truncated 1`,
+		`sf_exporter_scrapes_total{result="truncated"} 1`,
+	)
+}
+
+func TestScrapeSpacesCalls(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	interval := 20 * time.Millisecond
+	e := New(cluster.Client(), Options{Budget: Budget{MinCallInterval: interval}})
+	start := time.Now()
+	if err := e.Scrape(context.Background()); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*interval {
+		t.Errorf("Got %v, want six calls at least %v apart", elapsed, interval)
+	}
+}
+
+func TestScrapeContinuesPastFailedCalls(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.InjectFault(sftest.Fault{Path: "Partitions/" + readyPartitionID + "/$/GetReplicas", ErrorCode: sftest.ErrorCodePartitionNotFound})
+
+	e := New(cluster.Client(), Options{})
+	if err := e.Scrape(context.Background()); err == nil {
+		t.Fatal("Got nil, want the failed call's error")
+	}
+
+	body := metrics(e)
+	assertMetrics(t, body,
+		`sf_partitions{health_state="Ok",status="Ready"} 1`,
+		`sf_exporter_scrape_truncated 0`,
+		`sf_exporter_scrapes_total{result="error"} 1`,
+		`sf_exporter_call_errors_total{call="GetPartitionMembersByID"} 1`,
+		`sf_exporter_call_duration_seconds_count{call="GetPartitionMembersByID"} 2`,
+	)
+	if strings.Contains(body, "sf_node_replicas{") {
+		t.Errorf("Got\n%s\nwant no replicas from the failed call", body)
+	}
+}
+
+func TestSecondsSinceLastSuccess(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	now := time.Unix(1500000000, 0)
+	e := New(cluster.Client(), Options{})
+	e.now = func() time.Time { return now }
+	if err := e.Scrape(context.Background()); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	now = now.Add(90 * time.Second)
+	assertMetrics(t, metrics(e),
+		`sf_exporter_last_success_timestamp_seconds 1.5e+09`,
+		`sf_exporter_seconds_since_last_success 90`,
+	)
+}
@@ -0,0 +1,387 @@
+package servicefabric
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// OperationID identifies a fault operation to the cluster
+type OperationID string
+
+// OperationState the state of a fault operation
+type OperationState string
+
+// Operation states as returned by the Service Fabric API
+const (
+	OperationStateInvalid        OperationState = "Invalid"
+	OperationStateRunning        OperationState = "Running"
+	OperationStateRollingBack    OperationState = "RollingBack"
+	OperationStateCompleted      OperationState = "Completed"
+	OperationStateFaulted        OperationState = "Faulted"
+	OperationStateCancelled      OperationState = "Cancelled"
+	OperationStateForceCancelled OperationState = "ForceCancelled"
+)
+
+// DataLossMode the kind of data loss StartDataLoss induces
+type DataLossMode string
+
+// Data loss modes as defined by the Service Fabric API
+const (
+	DataLossModePartial DataLossMode = "PartialDataLoss"
+	DataLossModeFull    DataLossMode = "FullDataLoss"
+)
+
+// QuorumLossMode the replicas StartQuorumLoss takes down
+type QuorumLossMode string
+
+// Quorum loss modes as defined by the Service Fabric API
+const (
+	QuorumLossModeQuorumReplicas QuorumLossMode = "QuorumReplicas"
+	QuorumLossModeAllReplicas    QuorumLossMode = "AllReplicas"
+)
+
+// RestartPartitionMode the replicas StartPartitionRestart restarts
+type RestartPartitionMode string
+
+// Restart partition modes as defined by the Service Fabric API
+const (
+	RestartPartitionModeAllReplicasOrInstances RestartPartitionMode = "AllReplicasOrInstances"
+	RestartPartitionModeOnlyActiveSecondaries  RestartPartitionMode = "OnlyActiveSecondaries"
+)
+
+// NodeTransitionType whether StartNodeTransition starts or stops a node
+type NodeTransitionType string
+
+// Node transition types as defined by the Service Fabric API
+const (
+	NodeTransitionTypeStart NodeTransitionType = "Start"
+	NodeTransitionTypeStop  NodeTransitionType = "Stop"
+)
+
+var (
+	// ErrOperationFaulted is returned when a fault operation fails
+	ErrOperationFaulted = errors.New("fault operation faulted")
+	// ErrOperationCancelled is returned when a fault operation is cancelled
+	ErrOperationCancelled = errors.New("fault operation cancelled")
+)
+
+const (
+	faultPollInitialInterval = 250 * time.Millisecond
+	faultPollMaxInterval     = 5 * time.Second
+)
+
+// SelectedPartition the partition a fault was injected into
+type SelectedPartition struct {
+	ServiceName string `json:"ServiceName"`
+	PartitionID string `json:"PartitionId"`
+}
+
+// PartitionFaultResult the result of a partition fault
+type PartitionFaultResult struct {
+	ErrorCode         int64             `json:"ErrorCode"`
+	SelectedPartition SelectedPartition `json:"SelectedPartition"`
+}
+
+// PartitionDataLossProgress the progress of a StartDataLoss operation
+type PartitionDataLossProgress struct {
+	State                OperationState       `json:"State"`
+	InvokeDataLossResult PartitionFaultResult `json:"InvokeDataLossResult"`
+}
+
+// PartitionQuorumLossProgress the progress of a StartQuorumLoss operation
+type PartitionQuorumLossProgress struct {
+	State                  OperationState       `json:"State"`
+	InvokeQuorumLossResult PartitionFaultResult `json:"InvokeQuorumLossResult"`
+}
+
+// PartitionRestartProgress the progress of a StartPartitionRestart operation
+type PartitionRestartProgress struct {
+	State                  OperationState       `json:"State"`
+	RestartPartitionResult PartitionFaultResult `json:"RestartPartitionResult"`
+}
+
+// NodeResult the node a transition was applied to
+type NodeResult struct {
+	NodeName       string `json:"NodeName"`
+	NodeInstanceID string `json:"NodeInstanceId"`
+}
+
+// NodeTransitionResult the result of a node transition
+type NodeTransitionResult struct {
+	ErrorCode  int64      `json:"ErrorCode"`
+	NodeResult NodeResult `json:"NodeResult"`
+}
+
+// NodeTransitionProgress the progress of a StartNodeTransition operation
+type NodeTransitionProgress struct {
+	State                OperationState       `json:"State"`
+	NodeTransitionResult NodeTransitionResult `json:"NodeTransitionResult"`
+}
+
+// FaultOperation tracks a fault operation started on the cluster
+// until it completes, fails or is cancelled
+type FaultOperation struct {
+	// ID identifies the operation to the cluster
+	ID OperationID
+
+	client   Client
+	progress func(c Client) (OperationState, interface{}, error)
+	last     interface{}
+}
+
+// State returns the current state of the operation
+func (o *FaultOperation) State() (OperationState, error) {
+	return o.state(o.client)
+}
+
+func (o *FaultOperation) state(c Client) (OperationState, error) {
+	state, progress, err := o.progress(c)
+	if err != nil {
+		return "", err
+	}
+	o.last = progress
+	return state, nil
+}
+
+// LastProgress returns the progress last returned by the cluster,
+// such as a *PartitionDataLossProgress, or nil before the first poll
+func (o *FaultOperation) LastProgress() interface{} {
+	return o.last
+}
+
+// Wait polls the operation until it completes, fails or is cancelled.
+// A failed operation returns ErrOperationFaulted and a cancelled
+// one ErrOperationCancelled. If ctx is done first its error is
+// returned and the operation keeps running.
+func (o *FaultOperation) Wait(ctx context.Context) error {
+	var state OperationState
+	err := pollUntil(ctx, faultPollInitialInterval, faultPollMaxInterval, func() (bool, error) {
+		var err error
+		state, err = o.state(*o.client.WithContext(ctx))
+		if err != nil {
+			// A request cancelled with ctx is reported as ctx's error
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+		switch state {
+		case OperationStateCompleted, OperationStateFaulted, OperationStateCancelled, OperationStateForceCancelled:
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case OperationStateFaulted:
+		return fmt.Errorf("%w: operation %s", ErrOperationFaulted, o.ID)
+	case OperationStateCancelled, OperationStateForceCancelled:
+		return fmt.Errorf("%w: operation %s", ErrOperationCancelled, o.ID)
+	}
+	return nil
+}
+
+// Cancel cancels the operation. A forced cancel skips rolling
+// back any state the operation has already changed.
+func (o *FaultOperation) Cancel(force bool) error {
+	return o.client.CancelOperation(o.ID, force)
+}
+
+// NewOperationID returns a random operation id
+func NewOperationID() (OperationID, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("could not generate operation id: %v", err)
+	}
+	// Version 4, variant 1 UUID
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return OperationID(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])), nil
+}
+
+// StartDataLoss induces data loss in the partition identified by
+// partitionID of the service identified by serviceID.
+func (c Client) StartDataLoss(serviceID ServiceID, partitionID PartitionID, mode DataLossMode) (*FaultOperation, error) {
+	return c.startFault(opStartDataLoss, partitionFaultsPath(serviceID, partitionID)+"/$/StartDataLoss",
+		func(c Client, id OperationID) (OperationState, interface{}, error) {
+			progress, err := c.GetDataLossProgress(serviceID, partitionID, id)
+			if err != nil {
+				return "", nil, err
+			}
+			return progress.State, progress, nil
+		},
+		withParam("DataLossMode", string(mode)))
+}
+
+// GetDataLossProgress returns the progress of the
+// StartDataLoss operation identified by operationID.
+func (c Client) GetDataLossProgress(serviceID ServiceID, partitionID PartitionID, operationID OperationID) (*PartitionDataLossProgress, error) {
+	var progress PartitionDataLossProgress
+	err := c.getFaultProgress(opGetDataLossProgress, partitionFaultsPath(serviceID, partitionID)+"/$/GetDataLossProgress", operationID, &progress)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// StartQuorumLoss puts the partition identified by partitionID of
+// the service identified by serviceID into quorum loss for duration.
+func (c Client) StartQuorumLoss(serviceID ServiceID, partitionID PartitionID, mode QuorumLossMode, duration time.Duration) (*FaultOperation, error) {
+	return c.startFault(opStartQuorumLoss, partitionFaultsPath(serviceID, partitionID)+"/$/StartQuorumLoss",
+		func(c Client, id OperationID) (OperationState, interface{}, error) {
+			progress, err := c.GetQuorumLossProgress(serviceID, partitionID, id)
+			if err != nil {
+				return "", nil, err
+			}
+			return progress.State, progress, nil
+		},
+		withParam("QuorumLossMode", string(mode)),
+		withParam("QuorumLossDuration", strconv.FormatInt(durationSeconds(duration), 10)))
+}
+
+// GetQuorumLossProgress returns the progress of the
+// StartQuorumLoss operation identified by operationID.
+func (c Client) GetQuorumLossProgress(serviceID ServiceID, partitionID PartitionID, operationID OperationID) (*PartitionQuorumLossProgress, error) {
+	var progress PartitionQuorumLossProgress
+	err := c.getFaultProgress(opGetQuorumLossProgress, partitionFaultsPath(serviceID, partitionID)+"/$/GetQuorumLossProgress", operationID, &progress)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// StartPartitionRestart restarts the replicas or instances of the
+// partition identified by partitionID of the service identified by serviceID.
+func (c Client) StartPartitionRestart(serviceID ServiceID, partitionID PartitionID, mode RestartPartitionMode) (*FaultOperation, error) {
+	return c.startFault(opStartPartitionRestart, partitionFaultsPath(serviceID, partitionID)+"/$/StartRestart",
+		func(c Client, id OperationID) (OperationState, interface{}, error) {
+			progress, err := c.GetPartitionRestartProgress(serviceID, partitionID, id)
+			if err != nil {
+				return "", nil, err
+			}
+			return progress.State, progress, nil
+		},
+		withParam("RestartPartitionMode", string(mode)))
+}
+
+// GetPartitionRestartProgress returns the progress of the
+// StartPartitionRestart operation identified by operationID.
+func (c Client) GetPartitionRestartProgress(serviceID ServiceID, partitionID PartitionID, operationID OperationID) (*PartitionRestartProgress, error) {
+	var progress PartitionRestartProgress
+	err := c.getFaultProgress(opGetPartitionRestartProgress, partitionFaultsPath(serviceID, partitionID)+"/$/GetRestartProgress", operationID, &progress)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// StartNodeTransition starts or stops the node named nodeName. The
+// node instance id must match the node's current instance. A stopped
+// node is started again after stopDuration.
+func (c Client) StartNodeTransition(nodeName, nodeInstanceID string, transition NodeTransitionType, stopDuration time.Duration) (*FaultOperation, error) {
+	params := []queryParamsFunc{
+		withParam("NodeTransitionType", string(transition)),
+		withParam("NodeInstanceId", nodeInstanceID),
+	}
+	if transition == NodeTransitionTypeStop {
+		params = append(params, withParam("StopDurationInSeconds", strconv.FormatInt(durationSeconds(stopDuration), 10)))
+	}
+	return c.startFault(opStartNodeTransition, "Faults/Nodes/"+escapeSegment(nodeName)+"/$/StartTransition/",
+		func(c Client, id OperationID) (OperationState, interface{}, error) {
+			progress, err := c.GetNodeTransitionProgress(nodeName, id)
+			if err != nil {
+				return "", nil, err
+			}
+			return progress.State, progress, nil
+		},
+		params...)
+}
+
+// GetNodeTransitionProgress returns the progress of the
+// StartNodeTransition operation identified by operationID.
+func (c Client) GetNodeTransitionProgress(nodeName string, operationID OperationID) (*NodeTransitionProgress, error) {
+	var progress NodeTransitionProgress
+	err := c.getFaultProgress(opGetNodeTransitionProgress, "Faults/Nodes/"+escapeSegment(nodeName)+"/$/GetTransitionProgress", operationID, &progress)
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// CancelOperation cancels the fault operation identified by
+// operationID. A forced cancel skips rolling back any state
+// the operation has already changed.
+func (c Client) CancelOperation(operationID OperationID, force bool) error {
+	_, err := c.postHTTP(opCancelOperation, "Faults/$/Cancel", nil,
+		withParam("OperationId", string(operationID)),
+		withParam("Force", strconv.FormatBool(force)))
+	return err
+}
+
+// RestartReplica restarts a replica of the partition
+// identified by partitionID on the node named nodeName.
+func (c Client) RestartReplica(nodeName string, partitionID PartitionID, replicaID string) error {
+	_, err := c.postHTTP(opRestartReplica, nodeReplicaPath(nodeName, partitionID, replicaID)+"/$/Restart", nil)
+	return err
+}
+
+// RemoveReplica removes a replica of the partition identified by
+// partitionID from the node named nodeName. A forced removal skips
+// closing the replica gracefully.
+func (c Client) RemoveReplica(nodeName string, partitionID PartitionID, replicaID string, force bool) error {
+	var forceRemove queryParamsFunc = noOp
+	if force {
+		forceRemove = withParam("ForceRemove", "true")
+	}
+	_, err := c.postHTTP(opRemoveReplica, nodeReplicaPath(nodeName, partitionID, replicaID)+"/$/Delete", nil, forceRemove)
+	return err
+}
+
+func (c Client) startFault(op operation, basePath string, progress func(c Client, id OperationID) (OperationState, interface{}, error), paramsFuncs ...queryParamsFunc) (*FaultOperation, error) {
+	id, err := NewOperationID()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.postHTTP(op, basePath, nil, append([]queryParamsFunc{withParam("OperationId", string(id))}, paramsFuncs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FaultOperation{
+		ID:     id,
+		client: c,
+		progress: func(c Client) (OperationState, interface{}, error) {
+			return progress(c, id)
+		},
+	}, nil
+}
+
+func (c Client) getFaultProgress(op operation, basePath string, operationID OperationID, progress interface{}) error {
+	res, err := c.getHTTP(op, basePath, withParam("OperationId", string(operationID)))
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(res, progress)
+	if err != nil {
+		return fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return nil
+}
+
+func partitionFaultsPath(serviceID ServiceID, partitionID PartitionID) string {
+	return "Faults/Services/" + escapeSegment(serviceID.String()) + "/$/GetPartitions/" + escapeSegment(partitionID.String())
+}
+
+func nodeReplicaPath(nodeName string, partitionID PartitionID, replicaID string) string {
+	return "Nodes/" + escapeSegment(nodeName) + "/$/GetPartitions/" + escapeSegment(partitionID.String()) + "/$/GetReplicas/" + escapeSegment(replicaID)
+}
@@ -0,0 +1,199 @@
+package servicefabric
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testServiceID ServiceID = "TestApplication~TestService"
+
+func TestNewOperationID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, err := NewOperationID()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	second, _ := NewOperationID()
+	if !uuid.MatchString(string(first)) {
+		t.Errorf("Got %s, want a version 4 UUID", first)
+	}
+	if first == second {
+		t.Errorf("Got %s twice, want unique operation ids", first)
+	}
+}
+
+func TestFaultOperations(t *testing.T) {
+	faults := newFakeFaults(OperationStateCompleted)
+	server := httptest.NewServer(faults)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	testCases := []struct {
+		desc  string
+		start func() (*FaultOperation, error)
+		query string
+	}{
+		{
+			desc: "DataLoss",
+			start: func() (*FaultOperation, error) {
+				return sfClient.StartDataLoss(testServiceID, testPartitionID, DataLossModePartial)
+			},
+			query: "/Faults/Services/TestApplication~TestService/$/GetPartitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/StartDataLoss?api-version=6.0&OperationId=%s&DataLossMode=PartialDataLoss",
+		},
+		{
+			desc: "QuorumLoss",
+			start: func() (*FaultOperation, error) {
+				return sfClient.StartQuorumLoss(testServiceID, testPartitionID, QuorumLossModeAllReplicas, time.Minute)
+			},
+			query: "/Faults/Services/TestApplication~TestService/$/GetPartitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/StartQuorumLoss?api-version=6.0&OperationId=%s&QuorumLossMode=AllReplicas&QuorumLossDuration=60",
+		},
+		{
+			desc: "PartitionRestart",
+			start: func() (*FaultOperation, error) {
+				return sfClient.StartPartitionRestart(testServiceID, testPartitionID, RestartPartitionModeOnlyActiveSecondaries)
+			},
+			query: "/Faults/Services/TestApplication~TestService/$/GetPartitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/StartRestart?api-version=6.0&OperationId=%s&RestartPartitionMode=OnlyActiveSecondaries",
+		},
+		{
+			desc: "NodeTransition",
+			start: func() (*FaultOperation, error) {
+				return sfClient.StartNodeTransition("_Node_0", "1234", NodeTransitionTypeStop, 2*time.Minute)
+			},
+			query: "/Faults/Nodes/_Node_0/$/StartTransition/?api-version=6.0&OperationId=%s&NodeTransitionType=Stop&NodeInstanceId=1234&StopDurationInSeconds=120",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			operation, err := test.start()
+			if err != nil {
+				t.Fatalf("Exception thrown %v", err)
+			}
+
+			faults.mu.Lock()
+			request := faults.requests[len(faults.requests)-1]
+			faults.mu.Unlock()
+			expected := "POST " + strings.Replace(test.query, "%s", string(operation.ID), 1)
+			if request != expected {
+				t.Errorf("Got %s, want %s", request, expected)
+			}
+
+			if err := operation.Wait(ctx); err != nil {
+				t.Fatalf("Exception thrown %v", err)
+			}
+			state, err := operation.State()
+			if err != nil {
+				t.Fatalf("Exception thrown %v", err)
+			}
+			if state != OperationStateCompleted {
+				t.Errorf("Got %s, want %s", state, OperationStateCompleted)
+			}
+			if operation.LastProgress() == nil {
+				t.Error("Got no progress, want the last progress")
+			}
+		})
+	}
+}
+
+func TestFaultOperationProgress(t *testing.T) {
+	server := httptest.NewServer(newFakeFaults(OperationStateCompleted))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	operation, err := sfClient.StartDataLoss(testServiceID, testPartitionID, DataLossModeFull)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := operation.Wait(context.Background()); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	progress, ok := operation.LastProgress().(*PartitionDataLossProgress)
+	if !ok {
+		t.Fatalf("Got %T, want *PartitionDataLossProgress", operation.LastProgress())
+	}
+	if progress.InvokeDataLossResult.SelectedPartition.PartitionID != string(testPartitionID) {
+		t.Errorf("Got %+v, want partition %s", progress.InvokeDataLossResult, testPartitionID)
+	}
+
+	direct, err := sfClient.GetDataLossProgress(testServiceID, testPartitionID, operation.ID)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if direct.State != OperationStateCompleted {
+		t.Errorf("Got %s, want %s", direct.State, OperationStateCompleted)
+	}
+}
+
+func TestFaultOperationFailures(t *testing.T) {
+	server := httptest.NewServer(newFakeFaults(OperationStateFaulted))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	faulted, err := sfClient.StartPartitionRestart(testServiceID, testPartitionID, RestartPartitionModeAllReplicasOrInstances)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := faulted.Wait(context.Background()); !errors.Is(err, ErrOperationFaulted) {
+		t.Errorf("Got %v, want %v", err, ErrOperationFaulted)
+	}
+
+	cancelled, err := sfClient.StartQuorumLoss(testServiceID, testPartitionID, QuorumLossModeQuorumReplicas, time.Minute)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := cancelled.Cancel(true); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := cancelled.Wait(context.Background()); !errors.Is(err, ErrOperationCancelled) {
+		t.Errorf("Got %v, want %v", err, ErrOperationCancelled)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	running, err := sfClient.StartDataLoss(testServiceID, testPartitionID, DataLossModePartial)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := running.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestReplicaFaults(t *testing.T) {
+	faults := newFakeFaults(OperationStateCompleted)
+	server := httptest.NewServer(faults)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	if err := sfClient.RestartReplica("_Node_0", testPartitionID, "131496928082309293"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if err := sfClient.RemoveReplica("_Node_0", testPartitionID, "131496928082309293", true); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := []string{
+		"POST /Nodes/_Node_0/$/GetPartitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/GetReplicas/131496928082309293/$/Restart?api-version=6.0",
+		"POST /Nodes/_Node_0/$/GetPartitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/GetReplicas/131496928082309293/$/Delete?api-version=6.0&ForceRemove=true",
+	}
+	for i, request := range faults.requests {
+		if request != expected[i] {
+			t.Errorf("Got %s, want %s", request, expected[i])
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package servicefabric
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 func handleApplications(w http.ResponseWriter, r *http.Request) {
@@ -28,7 +33,7 @@ func handleApplications(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			log.Fatal(err)
 		}
-	} else if r.URL.RawQuery == "api-version=1.0&continue=00001234" {
+	} else if r.URL.RawQuery == "api-version=1.0&ContinuationToken=00001234" {
 		w.WriteHeader(http.StatusOK)
 		body, err := ioutil.ReadFile("fixtures/applications_continue.json")
 		if err != nil {
@@ -211,3 +216,568 @@ func handleExtensionB(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 	}
 }
+
+func handleServiceTypes(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ApplicationTypes/TestApplication/$/GetServiceTypes" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.URL.RawQuery == "api-version=1.0&ApplicationTypeVersion=1.0.0" {
+		body, err := ioutil.ReadFile("fixtures/service_types.json")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err = w.Write([]byte(err.Error()))
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(body)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		http.NotFound(w, r)
+	}
+}
+
+func handleServiceManifest(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/ApplicationTypes/TestApplication/$/GetServiceManifest" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.URL.RawQuery == "api-version=1.0&ApplicationTypeVersion=1.0.0&ServiceManifestName=TestPkg" {
+		body, err := ioutil.ReadFile("fixtures/service_manifest.json")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err = w.Write([]byte(err.Error()))
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(body)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		http.NotFound(w, r)
+	}
+}
+
+func handleFixture(fixture string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadFile(fixture)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, err = w.Write([]byte(err.Error()))
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(body)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func handleLabels() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/ApplicationTypes/TestApplicationType/$/GetServiceTypes", handleFixture("fixtures/service_extensions.json"))
+	mux.HandleFunc("/Names/TestApplication/TestService", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/Names/TestApplication/TestService/$/GetProperties", handleFixture("fixtures/properties.json"))
+	return mux
+}
+
+func handleServiceGroup() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/Services/TestApplication~TestGroup/$/GetServiceGroupDescription", handleFixture("fixtures/service_group_description.json"))
+	mux.Handle("/Applications/TestApplication/$/GetServices/TestApplication/TestGroup/$/GetServiceGroupMembers", handleFixture("fixtures/service_group_members.json"))
+	mux.HandleFunc("/Names/TestApplication/TestGroup#Frontend", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/Names/TestApplication/TestGroup#Frontend/$/GetProperties", handleFixture("fixtures/properties.json"))
+	mux.HandleFunc("/Names/TestApplication/TestGroup#Backend", http.NotFound)
+	return mux
+}
+
+func handleReplicasInBuild(readyAfter int) http.Handler {
+	requests := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Applications/TestApplication/$/GetServices/TestApplication/TestService/$/GetPartitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/GetReplicas" {
+			http.NotFound(w, r)
+			return
+		}
+
+		requests++
+		if requests > readyAfter {
+			handleFixture("fixtures/replicas.json")(w, r)
+			return
+		}
+		handleFixture("fixtures/replicas_inbuild.json")(w, r)
+	})
+}
+
+func handlePartitionMembers() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/Applications/TestApplication/$/GetServices/TestApplication/TestService/$/GetPartitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/GetReplicas", handleFixture("fixtures/replicas.json"))
+	mux.Handle("/Applications/TestApplication/$/GetServices/TestApplication/TestService/$/GetPartitions/824091ba-fa32-4e9c-9e9c-71738e018312/$/GetReplicas", handleFixture("fixtures/instances.json"))
+	return mux
+}
+
+func handlePartitionsByID() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/Services/TestApplication~TestService/$/GetPartitions", handleFixture("fixtures/partitions.json"))
+	mux.Handle("/Partitions/bce46a8c-b62d-4996-89dc-7ffc00a96902", handleFixture("fixtures/partition.json"))
+	mux.Handle("/Partitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/GetReplicas", handleFixture("fixtures/replicas.json"))
+	mux.Handle("/Partitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/GetServiceName", handleFixture("fixtures/service_name.json"))
+	return mux
+}
+
+func handleQuery(path, rawQuery, fixture string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path || r.URL.RawQuery != rawQuery {
+			http.NotFound(w, r)
+			return
+		}
+		handleFixture(fixture)(w, r)
+	}
+}
+
+func handleClusterVersion() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/$/GetClusterVersion", handleQuery("/$/GetClusterVersion", "api-version=6.4", "fixtures/cluster_version.json"))
+	mux.Handle("/Names/TestApplication/TestService", handleQuery("/Names/TestApplication/TestService", "api-version=6.0", "fixtures/service_name.json"))
+	mux.Handle("/Names/TestApplication/TestService/$/GetProperties", handleQuery("/Names/TestApplication/TestService/$/GetProperties", "api-version=6.0&IncludeValues=true", "fixtures/properties.json"))
+	return mux
+}
+
+func handleEvents() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/EventsStore/Cluster/Events", handleQuery("/EventsStore/Cluster/Events", "api-version=6.4&StartTimeUtc=2018-04-03T18%3A00%3A00Z&EndTimeUtc=2018-04-04T18%3A00%3A00Z", "fixtures/events.json"))
+	mux.Handle("/EventsStore/Nodes/_Node_0/$/Events", handleQuery("/EventsStore/Nodes/_Node_0/$/Events", "api-version=6.4&StartTimeUtc=2018-04-03T18%3A00%3A00Z&EndTimeUtc=2018-04-04T18%3A00%3A00Z&EventsTypesFilter=NodeDown%2CNodeUp&ExcludeAnalysisEvents=true", "fixtures/events.json"))
+	mux.Handle("/EventsStore/Partitions/bce46a8c-b62d-4996-89dc-7ffc00a96902/$/Events", handleFixture("fixtures/events.json"))
+	return mux
+}
+
+// fakeBackupGateway is a local stand in for the backup and restore
+// service. Backups and restores complete as soon as they are requested.
+type fakeBackupGateway struct {
+	mu          sync.Mutex
+	policies    map[string]BackupPolicyDescription
+	enabled     map[string]string
+	backups     []BackupInfo
+	storage     BackupStorageDescription
+	backup      *BackupProgressInfo
+	restore     *RestoreProgressInfo
+	failRestore bool
+	queries     []string
+}
+
+func newFakeBackupGateway() *fakeBackupGateway {
+	return &fakeBackupGateway{
+		policies: map[string]BackupPolicyDescription{},
+		enabled:  map[string]string{},
+	}
+}
+
+func (g *fakeBackupGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.queries = append(g.queries, r.URL.RawQuery)
+
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	switch {
+	case r.Method == http.MethodPost && path == "BackupRestore/BackupPolicies/$/Create":
+		var policy BackupPolicyDescription
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		g.policies[policy.Name] = policy
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "BackupRestore/BackupPolicies/"):
+		policy, ok := g.policies[strings.TrimPrefix(path, "BackupRestore/BackupPolicies/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, policy)
+
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/$/EnableBackup"):
+		var body struct {
+			BackupPolicyName string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, ok := g.policies[body.BackupPolicyName]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		g.enabled[strings.TrimSuffix(path, "/$/EnableBackup")] = body.BackupPolicyName
+		w.WriteHeader(http.StatusAccepted)
+
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/$/Backup"):
+		var body struct {
+			BackupStorage json.RawMessage
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		storage, err := unmarshalBackupStorage(body.BackupStorage)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		g.storage = storage
+
+		partitionID := strings.TrimSuffix(strings.TrimPrefix(path, "Partitions/"), "/$/Backup")
+		timeStamp := time.Date(2018, 4, 3, 18, 0, len(g.backups), 0, time.UTC)
+		backup := BackupInfo{
+			BackupID:             "backup-" + partitionID,
+			BackupChainID:        "chain-" + partitionID,
+			PartitionInformation: PartitionInformation{ID: partitionID, ServicePartitionKind: "Singleton"},
+			BackupLocation:       "TestApplication/TestService/" + partitionID + "/full.zip",
+			BackupType:           "Full",
+			CreationTimeUtc:      timeStamp,
+		}
+		g.backups = append(g.backups, backup)
+		g.backup = &BackupProgressInfo{
+			BackupState:    BackupStateSuccess,
+			TimeStampUtc:   timeStamp,
+			BackupID:       backup.BackupID,
+			BackupLocation: backup.BackupLocation,
+		}
+		w.WriteHeader(http.StatusAccepted)
+
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/$/GetBackupProgress"):
+		if g.backup == nil {
+			writeJSON(w, BackupProgressInfo{BackupState: BackupStateInvalid})
+			return
+		}
+		writeJSON(w, g.backup)
+
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/$/GetBackups"):
+		items := g.backups
+		if r.URL.Query().Get("Latest") == "true" && len(items) > 0 {
+			items = items[len(items)-1:]
+		}
+		writeJSON(w, BackupInfoPage{Items: items})
+
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/$/Restore"):
+		var restore RestorePartitionDescription
+		if err := json.NewDecoder(r.Body).Decode(&restore); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		g.restore = &RestoreProgressInfo{RestoreState: RestoreStateSuccess, RestoredLsn: "1234"}
+		if g.failRestore {
+			g.restore = &RestoreProgressInfo{
+				RestoreState: RestoreStateFailure,
+				FailureError: &FabricErrorDetail{Code: "FABRIC_E_BACKUP_NOT_FOUND", Message: restore.BackupID},
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/$/GetRestoreProgress"):
+		if g.restore == nil {
+			writeJSON(w, RestoreProgressInfo{RestoreState: RestoreStateInvalid})
+			return
+		}
+		writeJSON(w, g.restore)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(body)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fakeChaos is a local stand in for the Chaos service which
+// keeps running until it is stopped
+type fakeChaos struct {
+	mu       sync.Mutex
+	params   *ChaosParameters
+	status   ChaosStatus
+	stops    int
+	schedule ChaosScheduleDescription
+	// getStatus the status code Chaos is read with, zero for success
+	getStatus int
+	// runPolls the times Chaos is read as running before it stops
+	// itself, zero to keep running until it is stopped
+	runPolls int
+	polls    int
+}
+
+func (f *fakeChaos) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/Tools/Chaos/$/Start":
+		var params ChaosParameters
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.params = &params
+		f.status = ChaosStatusRunning
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/Tools/Chaos/$/Stop":
+		f.status = ChaosStatusStopped
+		f.stops++
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/Tools/Chaos" && f.getStatus != 0:
+		w.WriteHeader(f.getStatus)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/Tools/Chaos":
+		f.polls++
+		if f.runPolls != 0 && f.polls > f.runPolls {
+			f.status = ChaosStatusStopped
+		}
+		writeJSON(w, ChaosInfo{ChaosParameters: f.params, Status: f.status, ScheduleStatus: ChaosScheduleStatusStopped})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/Tools/Chaos/Events":
+		query := r.URL.Query()
+		switch {
+		case query.Get("ContinuationToken") == "" && query.Get("StartTimeUtc") != "" && query.Get("EndTimeUtc") != "":
+			handleFixture("fixtures/chaos_events.json")(w, r)
+		case query.Get("ContinuationToken") == "2" && query.Get("StartTimeUtc") == "":
+			handleFixture("fixtures/chaos_events_continue.json")(w, r)
+		default:
+			http.Error(w, "invalid Chaos events query "+r.URL.RawQuery, http.StatusBadRequest)
+		}
+
+	case r.Method == http.MethodGet && r.URL.Path == "/Tools/Chaos/Schedule":
+		writeJSON(w, f.schedule)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/Tools/Chaos/Schedule":
+		var schedule ChaosScheduleDescription
+		if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if schedule.Version != f.schedule.Version {
+			http.Error(w, "FABRIC_E_CHAOS_SCHEDULE_VERSION_MISMATCH", http.StatusConflict)
+			return
+		}
+		schedule.Version++
+		f.schedule = schedule
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// fakeFaults is a local stand in for the fault analysis service.
+// Operations report Running once and then finish with finalState.
+type fakeFaults struct {
+	mu         sync.Mutex
+	finalState OperationState
+	operations map[string]*fakeFaultOperation
+	requests   []string
+}
+
+type fakeFaultOperation struct {
+	polls int
+	state OperationState
+}
+
+func newFakeFaults(finalState OperationState) *fakeFaults {
+	return &fakeFaults{finalState: finalState, operations: map[string]*fakeFaultOperation{}}
+}
+
+func (f *fakeFaults) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+
+	id := r.URL.Query().Get("OperationId")
+	path := r.URL.EscapedPath()
+	switch {
+	case r.Method == http.MethodPost && strings.Contains(path, "/$/Start"):
+		if id == "" {
+			http.Error(w, "missing OperationId", http.StatusBadRequest)
+			return
+		}
+		f.operations[id] = &fakeFaultOperation{state: OperationStateRunning}
+		w.WriteHeader(http.StatusAccepted)
+
+	case r.Method == http.MethodPost && path == "/Faults/$/Cancel":
+		operation, ok := f.operations[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		operation.state = OperationStateCancelled
+		if r.URL.Query().Get("Force") == "true" {
+			operation.state = OperationStateForceCancelled
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodGet && strings.Contains(path, "/$/Get"):
+		operation, ok := f.operations[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		operation.polls++
+		if operation.polls > 1 && operation.state == OperationStateRunning {
+			operation.state = f.finalState
+		}
+
+		result := PartitionFaultResult{SelectedPartition: SelectedPartition{ServiceName: "fabric:/TestApplication/TestService", PartitionID: string(testPartitionID)}}
+		switch {
+		case strings.HasSuffix(path, "/$/GetDataLossProgress"):
+			writeJSON(w, PartitionDataLossProgress{State: operation.state, InvokeDataLossResult: result})
+		case strings.HasSuffix(path, "/$/GetQuorumLossProgress"):
+			writeJSON(w, PartitionQuorumLossProgress{State: operation.state, InvokeQuorumLossResult: result})
+		case strings.HasSuffix(path, "/$/GetRestartProgress"):
+			writeJSON(w, PartitionRestartProgress{State: operation.state, RestartPartitionResult: result})
+		case strings.HasSuffix(path, "/$/GetTransitionProgress"):
+			writeJSON(w, NodeTransitionProgress{State: operation.state, NodeTransitionResult: NodeTransitionResult{NodeResult: NodeResult{NodeName: "_Node_0", NodeInstanceID: "1234"}}})
+		default:
+			http.NotFound(w, r)
+		}
+
+	case r.Method == http.MethodPost && (strings.HasSuffix(path, "/$/Restart") || strings.HasSuffix(path, "/$/Delete")):
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// fakeRepairManager is a local stand in for the repair manager
+// which checks and increments task versions like the real service
+type fakeRepairManager struct {
+	mu      sync.Mutex
+	tasks   []*RepairTask
+	queries []string
+}
+
+func (f *fakeRepairManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, r.URL.RawQuery)
+
+	if r.Method == http.MethodGet && r.URL.Path == "/$/GetRepairTaskList" {
+		query := r.URL.Query()
+		stateFilter, _ := strconv.Atoi(query.Get("StateFilter"))
+		tasks := []RepairTask{}
+		for _, task := range f.tasks {
+			if !strings.HasPrefix(task.TaskID, query.Get("TaskIdFilter")) {
+				continue
+			}
+			if executor := query.Get("ExecutorFilter"); executor != "" && task.Executor != executor {
+				continue
+			}
+			if stateFilter != 0 && stateFilter&fakeRepairStateFlags[task.State] == 0 {
+				continue
+			}
+			tasks = append(tasks, *task)
+		}
+		writeJSON(w, tasks)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var update RepairTask
+	var body struct {
+		RequestAbort bool
+	}
+	data, _ := ioutil.ReadAll(r.Body)
+	if err := json.Unmarshal(data, &update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_ = json.Unmarshal(data, &body)
+
+	if r.URL.Path == "/$/CreateRepairTask" {
+		update.Version = "1"
+		f.tasks = append(f.tasks, &update)
+		writeJSON(w, repairTaskUpdateInfo{Version: update.Version})
+		return
+	}
+
+	var task *RepairTask
+	for _, t := range f.tasks {
+		if t.TaskID == update.TaskID {
+			task = t
+		}
+	}
+	if task == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if update.Version != "0" && update.Version != task.Version {
+		http.Error(w, "FABRIC_E_SEQUENCE_NUMBER_CHECK_FAILED", http.StatusConflict)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/$/UpdateRepairExecutionState":
+		version := task.Version
+		*task = update
+		task.Version = version
+	case "/$/ForceApproveRepairTask":
+		task.State = RepairTaskStateApproved
+		task.Flags |= RepairTaskFlagForcedApproval
+	case "/$/CancelRepairTask":
+		task.Flags |= RepairTaskFlagCancelRequested
+		if body.RequestAbort {
+			task.Flags |= RepairTaskFlagAbortRequested
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	version, _ := strconv.Atoi(task.Version)
+	task.Version = strconv.Itoa(version + 1)
+	writeJSON(w, repairTaskUpdateInfo{Version: task.Version})
+}
+
+var fakeRepairStateFlags = map[RepairTaskState]int{
+	RepairTaskStateCreated:   1,
+	RepairTaskStateClaimed:   2,
+	RepairTaskStatePreparing: 4,
+	RepairTaskStateApproved:  8,
+	RepairTaskStateExecuting: 16,
+	RepairTaskStateRestoring: 32,
+	RepairTaskStateCompleted: 64,
+}
@@ -0,0 +1,90 @@
+package servicefabric
+
+import (
+	"net/url"
+	"strings"
+)
+
+// fabricScheme prefixes every Service Fabric name
+const fabricScheme = "fabric:/"
+
+// idSeparator replaces the segment separator of a name
+// when it is used as an application or service id
+const idSeparator = "~"
+
+// ApplicationID identifies an application, it is the application
+// name without the fabric:/ scheme and with / replaced by ~
+type ApplicationID string
+
+// ApplicationIDFromName returns the id of the application with the given name
+func ApplicationIDFromName(name string) ApplicationID {
+	return ApplicationID(nameToIDForm(name))
+}
+
+// Name returns the fabric:/ name of the application
+func (id ApplicationID) Name() string {
+	return idToName(string(id))
+}
+
+// String returns the id
+func (id ApplicationID) String() string {
+	return string(id)
+}
+
+// ServiceID identifies a service, it is the service name
+// without the fabric:/ scheme and with / replaced by ~
+type ServiceID string
+
+// ServiceIDFromName returns the id of the service with the given name
+func ServiceIDFromName(name string) ServiceID {
+	return ServiceID(nameToIDForm(name))
+}
+
+// Name returns the fabric:/ name of the service
+func (id ServiceID) Name() string {
+	return idToName(string(id))
+}
+
+// String returns the id
+func (id ServiceID) String() string {
+	return string(id)
+}
+
+// PartitionID identifies a partition, it is the partition's GUID
+type PartitionID string
+
+// String returns the id
+func (id PartitionID) String() string {
+	return string(id)
+}
+
+// NameInfo encapsulates the response model for the
+// name and id of an entity in the Service Fabric API
+type NameInfo struct {
+	ID   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+func nameToIDForm(name string) string {
+	return strings.Replace(strings.TrimPrefix(name, fabricScheme), "/", idSeparator, -1)
+}
+
+func idToName(id string) string {
+	return fabricScheme + strings.Replace(id, idSeparator, "/", -1)
+}
+
+// escapeSegment escapes an id so it can be used as
+// a single segment of a request path
+func escapeSegment(id string) string {
+	return url.PathEscape(id)
+}
+
+// escapePath escapes every segment of a / separated
+// id or name, leaving the separators intact
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
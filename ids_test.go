@@ -0,0 +1,89 @@
+package servicefabric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIDsFromNames(t *testing.T) {
+	appID := ApplicationIDFromName("fabric:/TestApplication/Nested")
+	if appID != "TestApplication~Nested" {
+		t.Errorf("Got %s, want TestApplication~Nested", appID)
+	}
+	if appID.Name() != "fabric:/TestApplication/Nested" {
+		t.Errorf("Got %s, want fabric:/TestApplication/Nested", appID.Name())
+	}
+
+	serviceID := ServiceIDFromName("fabric:/TestApplication/TestService")
+	if serviceID != "TestApplication~TestService" {
+		t.Errorf("Got %s, want TestApplication~TestService", serviceID)
+	}
+	if serviceID.Name() != "fabric:/TestApplication/TestService" {
+		t.Errorf("Got %s, want fabric:/TestApplication/TestService", serviceID.Name())
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	testCases := []struct {
+		actual   string
+		expected string
+	}{
+		{actual: escapeSegment("TestApplication~TestService"), expected: "TestApplication~TestService"},
+		{actual: escapeSegment("Test/Service"), expected: "Test%2FService"},
+		{actual: escapePath("TestApplication/TestGroup#Member"), expected: "TestApplication/TestGroup%23Member"},
+		{actual: escapePath("Test Application/Test?Service"), expected: "Test%20Application/Test%3FService"},
+	}
+
+	for _, test := range testCases {
+		if test.actual != test.expected {
+			t.Errorf("Got %s, want %s", test.actual, test.expected)
+		}
+	}
+}
+
+func TestGetPartitionByID(t *testing.T) {
+	server := httptest.NewServer(handlePartitionsByID())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	partition, err := sfClient.GetPartition("bce46a8c-b62d-4996-89dc-7ffc00a96902")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if partition.PartitionInformation.ID != "bce46a8c-b62d-4996-89dc-7ffc00a96902" || partition.PartitionStatus != "Ready" {
+		t.Errorf("Got %+v", partition)
+	}
+
+	members, err := sfClient.GetPartitionMembersByID("bce46a8c-b62d-4996-89dc-7ffc00a96902")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(members) != 1 || members[0].GetServiceKind() != ServiceKindStateful {
+		t.Errorf("Got %+v, want a single stateful replica", members)
+	}
+
+	serviceName, err := sfClient.GetPartitionServiceName("bce46a8c-b62d-4996-89dc-7ffc00a96902")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if ServiceID(serviceName.ID).Name() != serviceName.Name {
+		t.Errorf("Got id %s, want the id of %s", serviceName.ID, serviceName.Name)
+	}
+}
+
+func TestGetServicePartitions(t *testing.T) {
+	server := httptest.NewServer(handlePartitionsByID())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	partitions, err := sfClient.GetServicePartitions(ServiceIDFromName("fabric:/TestApplication/TestService"))
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(partitions.Items) != 1 {
+		t.Errorf("Got %d partitions, want 1", len(partitions.Items))
+	}
+}
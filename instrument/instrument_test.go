@@ -0,0 +1,183 @@
+package instrument
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+const applicationsResponse = `{"ContinuationToken":"","Items":[{"Id":"TestApplication","Name":"fabric:/TestApplication"}]}`
+
+func newTestServer(headers chan<- http.Header) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if headers != nil {
+			headers <- r.Header
+		}
+		switch r.URL.Path {
+		case "/Applications/":
+			w.Write([]byte(applicationsResponse))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"Error":{"Code":"FABRIC_E_SERVICE_DOES_NOT_EXIST","Message":"not found"}}`))
+		}
+	}))
+}
+
+func newTestClient(server *httptest.Server) *sf.Client {
+	client, _ := sf.NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	return client
+}
+
+func TestMetrics(t *testing.T) {
+	server := newTestServer(nil)
+	defer server.Close()
+
+	metrics := NewMetrics()
+	client := newTestClient(server).WithObserver(metrics)
+	if _, err := client.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if _, err := client.GetServices("TestApplication"); err == nil {
+		t.Fatal("Got nil, want the service lookup to fail")
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, expected := range []string{
+		`sf_client_requests_total{operation="GetApplicationInfoList",template="Applications/",method="GET",code="200"} 1`,
+		`sf_client_requests_total{operation="GetServiceInfoList",template="Applications/{applicationId}/$/GetServices",method="GET",code="404"} 1`,
+		`sf_client_request_duration_seconds_count{operation="GetApplicationInfoList",template="Applications/"} 1`,
+		`sf_client_response_bytes_total{operation="GetApplicationInfoList",template="Applications/"} ` + strconv.Itoa(len(applicationsResponse)),
+		`sf_client_request_bytes_total{operation="GetApplicationInfoList",template="Applications/"} 0`,
+	} {
+		if !strings.Contains(body, expected+"\n") {
+			t.Errorf("Got\n%s\nwant it to contain %q", body, expected)
+		}
+	}
+	if strings.Contains(body, "TestApplication") {
+		t.Errorf("Got\n%s\nwant no ids in the labels", body)
+	}
+}
+
+func TestTracerPropagatesParent(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := newTestServer(headers)
+	defer server.Close()
+
+	var mu sync.Mutex
+	var spans []Span
+	tracer := NewTracer(SpanExporterFunc(func(span Span) {
+		mu.Lock()
+		defer mu.Unlock()
+		spans = append(spans, span)
+	}))
+
+	parent, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	parent.TraceState = "vendor=value"
+	ctx := ContextWithSpanContext(context.Background(), parent)
+
+	client := newTestClient(server).WithObserver(tracer).WithContext(ctx)
+	if _, err := client.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	header := <-headers
+	sent, err := ParseTraceParent(header.Get(TraceParentHeader))
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if sent.TraceID != parent.TraceID || sent.SpanID == parent.SpanID || !sent.Sampled {
+		t.Errorf("Got %+v, want a sampled child of %+v", sent, parent)
+	}
+	if state := header.Get(TraceStateHeader); state != "vendor=value" {
+		t.Errorf("Got %q, want the parent's trace state", state)
+	}
+
+	if len(spans) != 1 {
+		t.Fatalf("Got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET Applications/" || span.SpanContext.SpanID != sent.SpanID || span.Parent.SpanID != parent.SpanID {
+		t.Errorf("Got %+v, want the span of the request", span)
+	}
+	if span.Attributes["servicefabric.operation"] != "GetApplicationInfoList" || span.Attributes["http.response.status_code"] != 200 || span.Err != nil {
+		t.Errorf("Got %+v, want the attributes of a successful request", span.Attributes)
+	}
+}
+
+func TestTracerRecordsFailedRequests(t *testing.T) {
+	server := newTestServer(nil)
+	defer server.Close()
+
+	var spans []Span
+	tracer := NewTracer(SpanExporterFunc(func(span Span) { spans = append(spans, span) }))
+	client := newTestClient(server).WithObserver(tracer)
+	if _, err := client.GetServices("TestApplication"); err == nil {
+		t.Fatal("Got nil, want the service lookup to fail")
+	}
+
+	if len(spans) != 1 || spans[0].Err == nil || spans[0].Attributes["error.type"] != "404" {
+		t.Errorf("Got %+v, want a failed span", spans)
+	}
+	if spans[0].Parent.IsValid() {
+		t.Errorf("Got %+v, want a root span", spans[0].Parent)
+	}
+}
+
+func TestTracerSkipsUnsampledTraces(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := newTestServer(headers)
+	defer server.Close()
+
+	exported := 0
+	tracer := NewTracer(SpanExporterFunc(func(span Span) { exported++ }))
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	client := newTestClient(server).WithObserver(tracer).WithContext(ContextWithSpanContext(context.Background(), parent))
+	if _, err := client.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if traceParent := (<-headers).Get(TraceParentHeader); !strings.HasPrefix(traceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || !strings.HasSuffix(traceParent, "-00") {
+		t.Errorf("Got %q, want the unsampled trace propagated", traceParent)
+	}
+	if exported != 0 {
+		t.Errorf("Got %d spans, want none", exported)
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceParent(invalid); err == nil {
+			t.Errorf("Got nil, want an error parsing %q", invalid)
+		}
+	}
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceParent(traceParent)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if sc.TraceParent() != traceParent {
+		t.Errorf("Got %q, want %q", sc.TraceParent(), traceParent)
+	}
+}
@@ -0,0 +1,97 @@
+// Package instrument provides observers of the requests made by a
+// servicefabric.Client: Metrics records them as Prometheus metrics and
+// Tracer records them as OpenTelemetry client spans, propagating the
+// trace to the cluster with W3C Trace Context headers.
+//
+//	metrics := instrument.NewMetrics()
+//	client = client.WithObserver(metrics)
+//	http.Handle("/metrics", metrics)
+//
+// The package does not depend on OpenTelemetry, so Tracer cannot find
+// an OpenTelemetry span in a request's context. To record requests as
+// children of the caller's span, copy its span context into the
+// context the client is bound to:
+//
+//	sc := trace.SpanContextFromContext(ctx)
+//	ctx = instrument.ContextWithSpanContext(ctx, instrument.SpanContext{
+//		TraceID:    instrument.TraceID(sc.TraceID()),
+//		SpanID:     instrument.SpanID(sc.SpanID()),
+//		Sampled:    sc.IsSampled(),
+//		TraceState: sc.TraceState().String(),
+//	})
+//	apps, err := client.WithContext(ctx).GetApplications()
+package instrument
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	sf "github.com/jjcollinge/servicefabric"
+	"github.com/jjcollinge/servicefabric/internal/promtext"
+)
+
+// Metrics an Observer recording the requests of a client as Prometheus
+// metrics, labelled by operation and URL template rather than by the
+// ids in the request URL
+type Metrics struct {
+	mu            sync.Mutex
+	requests      *promtext.Vec
+	duration      *promtext.HistogramVec
+	requestBytes  *promtext.Vec
+	responseBytes *promtext.Vec
+}
+
+// NewMetrics returns an Observer recording request metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:      promtext.NewVec("operation", "template", "method", "code"),
+		duration:      promtext.NewHistogramVec("operation", "template"),
+		requestBytes:  promtext.NewVec("operation", "template"),
+		responseBytes: promtext.NewVec("operation", "template"),
+	}
+}
+
+// StartRequest returns a function recording the result of the request
+func (m *Metrics) StartRequest(req *http.Request, info sf.RequestInfo) func(result sf.RequestResult) {
+	return func(result sf.RequestResult) {
+		code := "error"
+		if result.StatusCode != 0 {
+			code = strconv.Itoa(result.StatusCode)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.requests.Add(1, info.Operation, info.Template, info.Method, code)
+		m.duration.Observe(result.Duration.Seconds(), info.Operation, info.Template)
+		m.requestBytes.Add(float64(result.RequestBytes), info.Operation, info.Template)
+		m.responseBytes.Add(float64(result.ResponseBytes), info.Operation, info.Template)
+	}
+}
+
+// WriteMetrics writes the metrics in the Prometheus text format,
+// for serving them alongside other metrics
+func (m *Metrics) WriteMetrics(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := promtext.NewWriter(w)
+	p.Vec("sf_client_requests_total", "counter", "Requests made to the cluster by status code, error when no response was received.", m.requests)
+	p.HistogramVec("sf_client_request_duration_seconds", "The time from sending a request until its response was read.", m.duration)
+	p.Vec("sf_client_request_bytes_total", "counter", "The bytes of request bodies sent to the cluster.", m.requestBytes)
+	p.Vec("sf_client_response_bytes_total", "counter", "The bytes of response bodies read from the cluster.", m.responseBytes)
+	return p.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := m.WriteMetrics(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,199 @@
+package instrument
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// W3C Trace Context headers
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// SpanContext identifies a span across process boundaries
+// as carried by the W3C Trace Context headers
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Sampled whether the trace is being recorded
+	Sampled bool
+	// TraceState vendor specific trace state, passed on unchanged
+	TraceState string
+}
+
+// IsValid reports whether both the trace and span ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// TraceParent returns the traceparent header value of sc
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// ParseTraceParent parses a traceparent header value
+func ParseTraceParent(traceParent string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", traceParent)
+	}
+
+	var sc SpanContext
+	var flags [1]byte
+	for _, field := range []struct {
+		hex string
+		dst []byte
+	}{{parts[1], sc.TraceID[:]}, {parts[2], sc.SpanID[:]}, {parts[3], flags[:]}} {
+		if len(field.hex) != 2*len(field.dst) {
+			return SpanContext{}, fmt.Errorf("invalid traceparent %q", traceParent)
+		}
+		if _, err := hex.Decode(field.dst, []byte(field.hex)); err != nil {
+			return SpanContext{}, fmt.Errorf("invalid traceparent %q: %v", traceParent, err)
+		}
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", traceParent)
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc, requests
+// made by a client bound to the context become children of sc. It is
+// the only way a Tracer learns of a parent span, including the span of
+// an OpenTelemetry tracer, see the package documentation.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context carried by ctx
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Span a finished client span for a single request, with attributes
+// named after the OpenTelemetry HTTP client semantic conventions
+type Span struct {
+	// Name the method and URL template of the request
+	Name string
+	// SpanContext identifies the span
+	SpanContext SpanContext
+	// Parent the span the request was made within, invalid for a root span
+	Parent SpanContext
+	Start  time.Time
+	End    time.Time
+	// Attributes such as http.request.method and url.template
+	Attributes map[string]interface{}
+	// Err is set when the request failed or the cluster
+	// responded with an unsuccessful status code
+	Err error
+}
+
+// SpanExporter receives the sampled spans of a Tracer. Spans are
+// passed to an OpenTelemetry SDK by converting them in ExportSpan.
+type SpanExporter interface {
+	ExportSpan(span Span)
+}
+
+// SpanExporterFunc a SpanExporter calling a function
+type SpanExporterFunc func(span Span)
+
+// ExportSpan calls f with span
+func (f SpanExporterFunc) ExportSpan(span Span) {
+	f(span)
+}
+
+// Tracer an Observer recording a client span for each request and
+// propagating its span context to the cluster in the traceparent header.
+// Requests whose context carries a span context set by
+// ContextWithSpanContext are recorded as its children. Any other
+// request, including one made within an OpenTelemetry span that was
+// not copied with ContextWithSpanContext, starts a new trace.
+type Tracer struct {
+	exporter SpanExporter
+}
+
+// NewTracer returns an Observer exporting a span for each request to exporter
+func NewTracer(exporter SpanExporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// StartRequest starts a span for req and sets its trace headers
+func (t *Tracer) StartRequest(req *http.Request, info sf.RequestInfo) func(result sf.RequestResult) {
+	parent, hasParent := SpanContextFromContext(req.Context())
+
+	sc := SpanContext{Sampled: true}
+	if hasParent {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+		sc.TraceState = parent.TraceState
+	} else {
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+
+	req.Header.Set(TraceParentHeader, sc.TraceParent())
+	if sc.TraceState != "" {
+		req.Header.Set(TraceStateHeader, sc.TraceState)
+	}
+	if !sc.Sampled {
+		return nil
+	}
+
+	span := Span{
+		Name:        info.Method + " " + info.Template,
+		SpanContext: sc,
+		Parent:      parent,
+		Start:       time.Now(),
+		Attributes: map[string]interface{}{
+			"http.request.method":     info.Method,
+			"url.template":            info.Template,
+			"server.address":          req.URL.Hostname(),
+			"servicefabric.operation": info.Operation,
+		},
+	}
+	if port := req.URL.Port(); port != "" {
+		span.Attributes["server.port"] = port
+	}
+
+	return func(result sf.RequestResult) {
+		span.End = span.Start.Add(result.Duration)
+		span.Attributes["http.request.body.size"] = result.RequestBytes
+		span.Attributes["http.response.body.size"] = result.ResponseBytes
+		if result.StatusCode != 0 {
+			span.Attributes["http.response.status_code"] = result.StatusCode
+		}
+
+		switch {
+		case result.Err != nil:
+			span.Err = result.Err
+			span.Attributes["error.type"] = fmt.Sprintf("%T", result.Err)
+		case result.StatusCode >= 400:
+			span.Err = errors.New(http.StatusText(result.StatusCode))
+			span.Attributes["error.type"] = fmt.Sprint(result.StatusCode)
+		}
+		t.exporter.ExportSpan(span)
+	}
+}
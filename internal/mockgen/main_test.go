@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestGeneratedMockIsUpToDate(t *testing.T) {
+	src, err := ioutil.ReadFile("../../api.go")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	expected, err := ioutil.ReadFile("../../sfmock/mock.go")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	generated, err := generate(src)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !bytes.Equal(generated, expected) {
+		t.Error("sfmock/mock.go is out of date, run go generate ./sfmock")
+	}
+}
+
+func TestGenerateRejectsMethodsWithoutError(t *testing.T) {
+	src := []byte("package servicefabric\n\ntype API interface {\n\tName() string\n}\n")
+	if _, err := generate(src); err == nil {
+		t.Error("Expected an error generating a method which does not return an error")
+	}
+}
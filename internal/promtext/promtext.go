@@ -0,0 +1,211 @@
+// Package promtext writes metrics in the Prometheus text exposition
+// format, for the packages of this module which serve metrics without
+// depending on the Prometheus client library.
+package promtext
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultBuckets the upper bounds of histogram buckets for latencies in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// labelSeparator joins label values into a key, it cannot appear in valid UTF-8
+const labelSeparator = "\xff"
+
+// Vec the values of a metric keyed by their label values.
+// A Vec without labels holds a single value.
+type Vec struct {
+	labels []string
+	values map[string]float64
+}
+
+// NewVec returns an empty Vec with the label names labels
+func NewVec(labels ...string) *Vec {
+	return &Vec{labels: labels, values: map[string]float64{}}
+}
+
+// Add adds delta to the value with labelValues
+func (v *Vec) Add(delta float64, labelValues ...string) {
+	v.values[strings.Join(labelValues, labelSeparator)] += delta
+}
+
+// Set sets the value with labelValues
+func (v *Vec) Set(value float64, labelValues ...string) {
+	v.values[strings.Join(labelValues, labelSeparator)] = value
+}
+
+func (v *Vec) keys() []string {
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Histogram counts observations into cumulative buckets
+type Histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe adds value to the histogram
+func (h *Histogram) Observe(value float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(DefaultBuckets))
+	}
+	for i, bound := range DefaultBuckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// HistogramVec histograms keyed by their label values
+type HistogramVec struct {
+	labels []string
+	values map[string]*Histogram
+}
+
+// NewHistogramVec returns an empty HistogramVec with the label names labels
+func NewHistogramVec(labels ...string) *HistogramVec {
+	return &HistogramVec{labels: labels, values: map[string]*Histogram{}}
+}
+
+// Observe adds value to the histogram with labelValues
+func (v *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+	h, ok := v.values[key]
+	if !ok {
+		h = &Histogram{}
+		v.values[key] = h
+	}
+	h.Observe(value)
+}
+
+func (v *HistogramVec) keys() []string {
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Writer writes metrics in the text format. The first write error
+// is kept and returned by Flush, later writes are skipped.
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter returns a Writer writing to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Gauge writes a gauge with a single value
+func (m *Writer) Gauge(name, help string, value float64) {
+	m.header(name, "gauge", help)
+	m.sample(name, nil, nil, value)
+}
+
+// Vec writes every value of v as a metric of type kind. The header
+// is written even when v is empty so the metric is always described.
+func (m *Writer) Vec(name, kind, help string, v *Vec) {
+	m.header(name, kind, help)
+	for _, key := range v.keys() {
+		m.sample(name, v.labels, splitKey(key, len(v.labels)), v.values[key])
+	}
+}
+
+// HistogramVec writes every histogram of v
+func (m *Writer) HistogramVec(name, help string, v *HistogramVec) {
+	m.header(name, "histogram", help)
+
+	bucketLabels := append(append([]string(nil), v.labels...), "le")
+	for _, key := range v.keys() {
+		h := v.values[key]
+		labelValues := splitKey(key, len(v.labels))
+		for i, bound := range DefaultBuckets {
+			var count uint64
+			if h.counts != nil {
+				count = h.counts[i]
+			}
+			m.sample(name+"_bucket", bucketLabels, append(labelValues, formatValue(bound)), float64(count))
+		}
+		m.sample(name+"_bucket", bucketLabels, append(labelValues, "+Inf"), float64(h.count))
+		m.sample(name+"_sum", v.labels, labelValues, h.sum)
+		m.sample(name+"_count", v.labels, labelValues, float64(h.count))
+	}
+}
+
+// Flush writes any buffered metrics and returns the first write error
+func (m *Writer) Flush() error {
+	if m.err != nil {
+		return m.err
+	}
+	return m.w.Flush()
+}
+
+func (m *Writer) header(name, kind, help string) {
+	m.write("# HELP ", name, " ", help, "\n# TYPE ", name, " ", kind, "\n")
+}
+
+func (m *Writer) sample(name string, labels []string, labelValues []string, value float64) {
+	m.write(name)
+	if len(labels) > 0 {
+		m.write("{")
+		for i, label := range labels {
+			if i > 0 {
+				m.write(",")
+			}
+			m.write(label, `="`, escapeLabelValue(labelValues[i]), `"`)
+		}
+		m.write("}")
+	}
+	m.write(" ", formatValue(value), "\n")
+}
+
+func (m *Writer) write(parts ...string) {
+	for _, part := range parts {
+		if m.err != nil {
+			return
+		}
+		_, m.err = m.w.WriteString(part)
+	}
+}
+
+// splitKey returns the label values joined into key, a key of a
+// Vec without labels splits into no values rather than one empty value
+func splitKey(key string, labels int) []string {
+	if labels == 0 {
+		return nil
+	}
+	return strings.Split(key, labelSeparator)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,66 @@
+package promtext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriterEscapesLabelValues(t *testing.T) {
+	v := NewVec("name")
+	v.Set(1, "a \"quoted\"\nname\\")
+
+	var buf strings.Builder
+	m := NewWriter(&buf)
+	m.Vec("sf_test", "gauge", "A test metric.", v)
+	if err := m.Flush(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := "# HELP sf_test A test metric.\n# TYPE sf_test gauge\nsf_test{name=\"a \\\"quoted\\\"\\nname\\\\\"} 1\n"
+	if buf.String() != expected {
+		t.Errorf("Got %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestWriterHistogramVec(t *testing.T) {
+	v := NewHistogramVec("call", "method")
+	v.Observe(0.02, "GetApplications", "GET")
+	v.Observe(3, "GetApplications", "GET")
+
+	var buf strings.Builder
+	m := NewWriter(&buf)
+	m.HistogramVec("sf_latency", "A test histogram.", v)
+	if err := m.Flush(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	for _, expected := range []string{
+		"# TYPE sf_latency histogram\n",
+		`sf_latency_bucket{call="GetApplications",method="GET",le="0.01"} 0` + "\n",
+		`sf_latency_bucket{call="GetApplications",method="GET",le="0.025"} 1` + "\n",
+		`sf_latency_bucket{call="GetApplications",method="GET",le="5"} 2` + "\n",
+		`sf_latency_bucket{call="GetApplications",method="GET",le="+Inf"} 2` + "\n",
+		`sf_latency_sum{call="GetApplications",method="GET"} 3.02` + "\n",
+		`sf_latency_count{call="GetApplications",method="GET"} 2` + "\n",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Got\n%s\nwant it to contain %q", buf.String(), expected)
+		}
+	}
+}
+
+func TestWriterVecWithoutLabels(t *testing.T) {
+	v := NewVec()
+	v.Add(2)
+	v.Add(3)
+
+	var buf strings.Builder
+	m := NewWriter(&buf)
+	m.Vec("sf_total", "counter", "A test counter.", v)
+	if err := m.Flush(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "\nsf_total 5\n") {
+		t.Errorf("Got %q, want a single unlabelled value", buf.String())
+	}
+}
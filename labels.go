@@ -0,0 +1,268 @@
+package servicefabric
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LabelSource identifies where a label was read from
+type LabelSource string
+
+// Label sources understood by the LabelResolver
+const (
+	// LabelSourceExtension labels declared in the service manifest
+	// extension, expects extension xml in <Label key="key">value</Label>
+	LabelSourceExtension LabelSource = "Extension"
+	// LabelSourceProperty string properties stored against
+	// the service name in the Naming property manager
+	LabelSourceProperty LabelSource = "Property"
+	// LabelSourceAppParameter parameters of the application the service belongs to
+	LabelSourceAppParameter LabelSource = "AppParameter"
+	// LabelSourceServiceGroupMember string properties stored against
+	// the names of each member of a service group
+	LabelSourceServiceGroupMember LabelSource = "ServiceGroupMember"
+)
+
+// LabelSourceConfig configures a single source of labels
+type LabelSourceConfig struct {
+	// Source the kind of source to read labels from
+	Source LabelSource
+	// Prefix only keys starting with Prefix followed by a period
+	// are kept, with the prefix and period removed.
+	// An empty Prefix keeps all keys unchanged.
+	Prefix string
+	// ExtensionKey the manifest extension name to read labels from,
+	// only used by LabelSourceExtension and defaults to Prefix
+	ExtensionKey string
+}
+
+// LabelProvenance records where a label value came from
+type LabelProvenance struct {
+	// Source the kind of source that supplied the label
+	Source LabelSource
+	// Origin the extension key, service name, application
+	// name or member service name that supplied the label
+	Origin string
+	// Key the key as it appeared in the source, before the prefix was removed
+	Key string
+	// RawValue the value as it appeared in the source, before template expansion
+	RawValue string
+}
+
+// ResolvedLabel a label value and the source that supplied it
+type ResolvedLabel struct {
+	LabelProvenance
+	// Value the label value after template expansion
+	Value string
+	// Shadowed lower precedence sources that also supplied
+	// this label, in the order they were overridden
+	Shadowed []LabelProvenance
+}
+
+// ResolvedLabels the labels resolved for a service keyed by label key
+type ResolvedLabels map[string]ResolvedLabel
+
+// Map returns the resolved labels as a plain dictionary
+func (r ResolvedLabels) Map() map[string]string {
+	labels := make(map[string]string, len(r))
+	for k, v := range r {
+		labels[k] = v.Value
+	}
+	return labels
+}
+
+// Keys returns the resolved label keys in sorted order
+func (r ResolvedLabels) Keys() []string {
+	keys := make([]string, 0, len(r))
+	for k := range r {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LabelResolver merges labels for a service from several sources.
+// Sources are applied in order so a label from a later source
+// overrides the same label from an earlier one.
+type LabelResolver struct {
+	client LabelReader
+	// Sources in increasing order of precedence
+	Sources []LabelSourceConfig
+	// ExpandTemplates replaces ${Name} references in label values
+	// with the value of the application parameter Name
+	ExpandTemplates bool
+}
+
+// NewLabelResolver returns a LabelResolver which mirrors GetServiceLabels,
+// reading labels from the manifest extension named prefix and then from
+// Naming properties, both filtered by and stripped of prefix. The
+// client may be a CachedClient to read properties from its cache.
+func NewLabelResolver(client LabelReader, prefix string) *LabelResolver {
+	return &LabelResolver{
+		client: client,
+		Sources: []LabelSourceConfig{
+			{Source: LabelSourceExtension, Prefix: prefix},
+			{Source: LabelSourceProperty, Prefix: prefix},
+		},
+		ExpandTemplates: true,
+	}
+}
+
+// Resolve returns the merged labels for a service within an application
+func (r *LabelResolver) Resolve(service *ServiceItem, app *ApplicationItem) (ResolvedLabels, error) {
+	return r.resolve(service, app, "")
+}
+
+// ResolveMember returns the merged labels for a single member of a
+// service group, where the service group member source only reads
+// the properties of that member
+func (r *LabelResolver) ResolveMember(service *ServiceItem, app *ApplicationItem, memberName string) (ResolvedLabels, error) {
+	return r.resolve(service, app, memberName)
+}
+
+func (r *LabelResolver) resolve(service *ServiceItem, app *ApplicationItem, memberName string) (ResolvedLabels, error) {
+	labels := ResolvedLabels{}
+	for _, source := range r.Sources {
+		found, err := r.read(source, service, app, memberName)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving %s labels for %s: %v", source.Source, service.Name, err)
+		}
+
+		for _, provenance := range found {
+			key, ok := stripLabelPrefix(provenance.Key, source.Prefix)
+			if !ok {
+				continue
+			}
+
+			label := ResolvedLabel{LabelProvenance: provenance, Value: provenance.RawValue}
+			if existing, exists := labels[key]; exists {
+				label.Shadowed = append(existing.Shadowed, existing.LabelProvenance)
+			}
+			labels[key] = label
+		}
+	}
+
+	if r.ExpandTemplates {
+		parameters := appParameterMap(app)
+		for k, label := range labels {
+			label.Value = expandLabelTemplate(label.Value, parameters)
+			labels[k] = label
+		}
+	}
+
+	return labels, nil
+}
+
+func (r *LabelResolver) read(source LabelSourceConfig, service *ServiceItem, app *ApplicationItem, memberName string) ([]LabelProvenance, error) {
+	switch source.Source {
+	case LabelSourceExtension:
+		extensionKey := source.ExtensionKey
+		if extensionKey == "" {
+			extensionKey = source.Prefix
+		}
+		extensionData := ServiceExtensionLabels{}
+		err := r.client.GetServiceExtension(app.TypeName, app.TypeVersion, service.TypeName, extensionKey, &extensionData)
+		if err != nil {
+			return nil, err
+		}
+		var found []LabelProvenance
+		for _, label := range extensionData.Label {
+			found = append(found, LabelProvenance{Source: LabelSourceExtension, Origin: extensionKey, Key: label.Key, RawValue: label.Value})
+		}
+		return found, nil
+
+	case LabelSourceProperty:
+		return r.readProperties(LabelSourceProperty, service.ID, service.Name)
+
+	case LabelSourceAppParameter:
+		var found []LabelProvenance
+		for _, parameter := range app.Parameters {
+			if parameter == nil {
+				continue
+			}
+			found = append(found, LabelProvenance{Source: LabelSourceAppParameter, Origin: app.Name, Key: parameter.Key, RawValue: parameter.Value})
+		}
+		return found, nil
+
+	case LabelSourceServiceGroupMember:
+		if !service.IsServiceGroup {
+			return nil, nil
+		}
+		description, err := r.client.GetServiceGroupDescription(ServiceID(service.ID))
+		if err != nil {
+			return nil, err
+		}
+		var found []LabelProvenance
+		for _, member := range description.ServiceGroupMemberDescription {
+			if memberName != "" && member.MemberName() != memberName {
+				continue
+			}
+			memberLabels, err := r.readProperties(LabelSourceServiceGroupMember, strings.TrimPrefix(member.ServiceName, fabricScheme), member.ServiceName)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, memberLabels...)
+		}
+		return found, nil
+	}
+
+	return nil, fmt.Errorf("unknown label source %q", source.Source)
+}
+
+func (r *LabelResolver) readProperties(source LabelSource, nameID, origin string) ([]LabelProvenance, error) {
+	exists, properties, err := r.client.GetProperties(nameID)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	found := make([]LabelProvenance, 0, len(keys))
+	for _, k := range keys {
+		found = append(found, LabelProvenance{Source: source, Origin: origin, Key: k, RawValue: properties[k]})
+	}
+	return found, nil
+}
+
+func stripLabelPrefix(key, prefix string) (string, bool) {
+	if prefix == "" {
+		return key, true
+	}
+	prefixPeriod := prefix + "."
+	if !strings.HasPrefix(key, prefixPeriod) {
+		return "", false
+	}
+	return strings.TrimPrefix(key, prefixPeriod), true
+}
+
+func appParameterMap(app *ApplicationItem) map[string]string {
+	parameters := make(map[string]string, len(app.Parameters))
+	for _, parameter := range app.Parameters {
+		if parameter != nil {
+			parameters[parameter.Key] = parameter.Value
+		}
+	}
+	return parameters
+}
+
+var labelTemplatePattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// expandLabelTemplate replaces ${Name} with the application parameter Name,
+// references to unknown parameters are left untouched
+func expandLabelTemplate(value string, parameters map[string]string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	return labelTemplatePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if v, ok := parameters[match[2:len(match)-1]]; ok {
+			return v
+		}
+		return match
+	})
+}
@@ -0,0 +1,110 @@
+package servicefabric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var labelTestService = &ServiceItem{
+	ID:          "TestApplication/TestService",
+	Name:        "fabric:/TestApplication/TestService",
+	ServiceKind: "Stateless",
+	TypeName:    "TestServiceType",
+}
+
+var labelTestApp = &ApplicationItem{
+	ID:   "TestApplication",
+	Name: "fabric:/TestApplication",
+	Parameters: []*AppParameter{
+		{"Host", "example.com"},
+		{"traefik.backend.weight", "5"},
+	},
+	TypeName:    "TestApplicationType",
+	TypeVersion: "1.0.0",
+}
+
+func TestLabelResolverDefaultSources(t *testing.T) {
+	server := httptest.NewServer(handleLabels())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	labels, err := NewLabelResolver(sfClient, "traefik").Resolve(labelTestService, labelTestApp)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := map[string]string{
+		"enable":        "true",
+		"frontend.rule": "Host:example.com",
+	}
+	if !reflect.DeepEqual(labels.Map(), expected) {
+		t.Errorf("Got %+v, want %+v", labels.Map(), expected)
+	}
+
+	rule := labels["frontend.rule"]
+	if rule.Source != LabelSourceProperty || rule.Key != "traefik.frontend.rule" || rule.RawValue != "Host:${Host}" {
+		t.Errorf("Got provenance %+v, want property traefik.frontend.rule", rule.LabelProvenance)
+	}
+	expectedShadowed := []LabelProvenance{
+		{Source: LabelSourceExtension, Origin: "traefik", Key: "traefik.frontend.rule", RawValue: "PathPrefix:/test"},
+	}
+	if !reflect.DeepEqual(rule.Shadowed, expectedShadowed) {
+		t.Errorf("Got shadowed %+v, want %+v", rule.Shadowed, expectedShadowed)
+	}
+}
+
+func TestLabelResolverPrecedence(t *testing.T) {
+	server := httptest.NewServer(handleLabels())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	resolver := NewLabelResolver(sfClient, "traefik")
+	resolver.Sources = []LabelSourceConfig{
+		{Source: LabelSourceProperty, Prefix: "traefik"},
+		{Source: LabelSourceExtension, Prefix: "traefik"},
+		{Source: LabelSourceAppParameter, Prefix: "traefik"},
+	}
+	resolver.ExpandTemplates = false
+
+	labels, err := resolver.Resolve(labelTestService, labelTestApp)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := map[string]string{
+		"enable":         "true",
+		"frontend.rule":  "PathPrefix:/test",
+		"backend.weight": "5",
+	}
+	if !reflect.DeepEqual(labels.Map(), expected) {
+		t.Errorf("Got %+v, want %+v", labels.Map(), expected)
+	}
+	if labels["backend.weight"].Source != LabelSourceAppParameter {
+		t.Errorf("Got source %s, want %s", labels["backend.weight"].Source, LabelSourceAppParameter)
+	}
+}
+
+func TestExpandLabelTemplate(t *testing.T) {
+	parameters := map[string]string{"Host": "example.com", "Port": "80"}
+
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "Host:${Host}", expected: "Host:example.com"},
+		{value: "${Host}:${Port}", expected: "example.com:80"},
+		{value: "${Missing}", expected: "${Missing}"},
+		{value: "no template", expected: "no template"},
+	}
+
+	for _, test := range testCases {
+		actual := expandLabelTemplate(test.value, parameters)
+		if actual != test.expected {
+			t.Errorf("Got %q, want %q", actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package servicefabric
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+)
+
+// redacted replaces sensitive values in log records
+const redacted = "REDACTED"
+
+// WithLogger returns a copy of the client which logs each request,
+// page fetched and continuation token to logger at debug level, as
+// well as cache hits of a CachedClient. Property values and
+// continuation tokens are redacted unless WithUnredactedLogs is also used.
+func (c Client) WithLogger(logger *slog.Logger) *Client {
+	c.logger = logger
+	return &c
+}
+
+// WithUnredactedLogs returns a copy of the client which logs property
+// values and continuation tokens as they are. It is meant for
+// debugging against test clusters only.
+func (c Client) WithUnredactedLogs() *Client {
+	c.unredactedLogs = true
+	return &c
+}
+
+// debugEnabled reports whether the client logs debug records
+func (c Client) debugEnabled() bool {
+	return c.logger != nil && c.logger.Enabled(c.context(), slog.LevelDebug)
+}
+
+func (c Client) debug(msg string, attrs ...slog.Attr) {
+	if !c.debugEnabled() {
+		return
+	}
+	c.logger.LogAttrs(c.context(), slog.LevelDebug, msg, attrs...)
+}
+
+// sensitive returns value to be logged, redacted unless the client logs unredacted values
+func (c Client) sensitive(value string) string {
+	if c.unredactedLogs || value == "" {
+		return value
+	}
+	return redacted
+}
+
+// logURL returns u to be logged, with its continuation token redacted
+func (c Client) logURL(u *url.URL) string {
+	q := u.Query()
+	if q.Get("ContinuationToken") == "" || c.unredactedLogs {
+		return u.String()
+	}
+
+	q.Set("ContinuationToken", redacted)
+	logged := *u
+	logged.RawQuery = q.Encode()
+	return logged.String()
+}
+
+// logPage logs a page of a paged list fetched for op
+func (c Client) logPage(op operation, items int, continuationToken string, attrs ...slog.Attr) {
+	if !c.debugEnabled() {
+		return
+	}
+	c.debug("servicefabric: page fetched", append([]slog.Attr{
+		slog.String("operation", op.name),
+		slog.Int("items", items),
+		slog.Bool("more", continuationToken != ""),
+		slog.String("continuation_token", c.sensitive(continuationToken)),
+	}, attrs...)...)
+}
+
+// logRequest returns a function logging the result of a request
+// described by info to u, or nil if the client does not log requests
+func (c Client) logRequest(ctx context.Context, u *url.URL, info RequestInfo) func(result RequestResult) {
+	if !c.debugEnabled() {
+		return nil
+	}
+
+	loggedURL := c.logURL(u)
+	return func(result RequestResult) {
+		attrs := []slog.Attr{
+			slog.String("operation", info.Operation),
+			slog.String("method", info.Method),
+			slog.String("template", info.Template),
+			slog.String("url", loggedURL),
+			slog.Int("status", result.StatusCode),
+			slog.Int64("request_bytes", result.RequestBytes),
+			slog.Int64("response_bytes", result.ResponseBytes),
+			slog.Duration("duration", result.Duration),
+		}
+		if err := result.Err; err != nil {
+			// The URL, which is already logged, may hold an unredacted token
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "servicefabric: request", attrs...)
+	}
+}
@@ -0,0 +1,115 @@
+package servicefabric
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// logRecords decodes the records written by a JSON slog handler
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func newDebugLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestLoggerLogsRequestsAndPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(handleApplications))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	_, err := sfClient.WithLogger(newDebugLogger(&buf)).GetApplications()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	records := logRecords(t, &buf)
+	var messages []string
+	for _, record := range records {
+		messages = append(messages, record["msg"].(string))
+	}
+	expected := "servicefabric: request,servicefabric: page fetched,servicefabric: request,servicefabric: page fetched"
+	if strings.Join(messages, ",") != expected {
+		t.Fatalf("Got %v, want %s", messages, expected)
+	}
+
+	request := records[2]
+	if request["operation"] != "GetApplicationInfoList" || request["status"] != float64(200) {
+		t.Errorf("Got %+v, want the second page's request", request)
+	}
+	if _, ok := request["attempt"]; ok {
+		t.Errorf("Got %+v, want no attempt as requests are never retried", request)
+	}
+	if request["url"] != server.URL+"/Applications/?ContinuationToken=REDACTED&api-version=1.0" {
+		t.Errorf("Got %v, want the continuation token redacted", request["url"])
+	}
+	page := records[1]
+	if page["items"] != float64(1) || page["more"] != true || page["continuation_token"] != redacted {
+		t.Errorf("Got %+v, want the first page", page)
+	}
+	if strings.Contains(buf.String(), "00001234") {
+		t.Errorf("Got %s, want no continuation tokens", buf.String())
+	}
+}
+
+func TestLoggerRedactsPropertyValues(t *testing.T) {
+	server := httptest.NewServer(handleLabels())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	var buf bytes.Buffer
+	_, _, err := sfClient.WithLogger(newDebugLogger(&buf)).GetProperties("TestApplication/TestService")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !strings.Contains(buf.String(), `"traefik.frontend.rule":"REDACTED"`) || strings.Contains(buf.String(), "Host:${Host}") {
+		t.Errorf("Got %s, want the property value redacted", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"msg":"servicefabric: name looked up","name":"TestApplication/TestService","exists":true`) {
+		t.Errorf("Got %s, want the name lookup", buf.String())
+	}
+
+	buf.Reset()
+	_, _, err = sfClient.WithLogger(newDebugLogger(&buf)).WithUnredactedLogs().GetProperties("TestApplication/TestService")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !strings.Contains(buf.String(), `"traefik.frontend.rule":"Host:${Host}"`) {
+		t.Errorf("Got %s, want the property value", buf.String())
+	}
+}
+
+func TestLoggerOnlyLogsAtDebugLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(handleApplications))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	if _, err := sfClient.WithLogger(logger).GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Got %s, want no records", buf.String())
+	}
+}
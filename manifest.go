@@ -0,0 +1,128 @@
+package servicefabric
+
+import "encoding/xml"
+
+// ServiceManifestResponse encapsulates the response model
+// for GetServiceManifest in the Service Fabric API
+type ServiceManifestResponse struct {
+	Manifest string `json:"Manifest"`
+}
+
+// ServiceManifest provides the structure for deserialising
+// the XML document describing a service manifest
+type ServiceManifest struct {
+	XMLName         xml.Name                `xml:"ServiceManifest"`
+	Name            string                  `xml:"Name,attr"`
+	Version         string                  `xml:"Version,attr"`
+	Description     string                  `xml:"Description"`
+	ServiceTypes    ServiceManifestTypes    `xml:"ServiceTypes"`
+	CodePackages    []CodePackage           `xml:"CodePackage"`
+	ConfigPackages  []ConfigPackage         `xml:"ConfigPackage"`
+	DataPackages    []DataPackage           `xml:"DataPackage"`
+	Resources       ServiceManifestResource `xml:"Resources"`
+	ManifestXMLData string                  `xml:"-"`
+}
+
+// ServiceManifestTypes the service types declared in a manifest
+type ServiceManifestTypes struct {
+	StatelessServiceTypes []ServiceManifestType `xml:"StatelessServiceType"`
+	StatefulServiceTypes  []ServiceManifestType `xml:"StatefulServiceType"`
+}
+
+// ServiceManifestType a service type declared in a manifest
+type ServiceManifestType struct {
+	ServiceTypeName      string                           `xml:"ServiceTypeName,attr"`
+	HasPersistedState    bool                             `xml:"HasPersistedState,attr"`
+	PlacementConstraints string                           `xml:"PlacementConstraints"`
+	Extensions           []ServiceManifestExtension       `xml:"Extensions>Extension"`
+	LoadMetrics          []ServiceManifestLoadMetric      `xml:"LoadMetrics>LoadMetric"`
+	PlacementPolicies    []ServiceManifestPlacementPolicy `xml:"ServicePlacementPolicies>ServicePlacementPolicy"`
+}
+
+// ServiceManifestExtension a named extension on a service type,
+// the inner XML is left for the caller to deserialise
+type ServiceManifestExtension struct {
+	Name     string `xml:"Name,attr"`
+	InnerXML string `xml:",innerxml"`
+}
+
+// ServiceManifestLoadMetric a load metric declared in a manifest
+type ServiceManifestLoadMetric struct {
+	Name                 string `xml:"Name,attr"`
+	Weight               string `xml:"Weight,attr"`
+	PrimaryDefaultLoad   int64  `xml:"PrimaryDefaultLoad,attr"`
+	SecondaryDefaultLoad int64  `xml:"SecondaryDefaultLoad,attr"`
+	DefaultLoad          int64  `xml:"DefaultLoad,attr"`
+}
+
+// ServiceManifestPlacementPolicy a placement policy declared in a manifest
+type ServiceManifestPlacementPolicy struct {
+	Type       string `xml:"Type,attr"`
+	DomainName string `xml:"DomainName,attr"`
+}
+
+// CodePackage a code package declared in a manifest
+type CodePackage struct {
+	Name                 string                 `xml:"Name,attr"`
+	Version              string                 `xml:"Version,attr"`
+	IsShared             bool                   `xml:"IsShared,attr"`
+	SetupEntryPoint      *CodePackageEntryPoint `xml:"SetupEntryPoint"`
+	EntryPoint           CodePackageEntryPoint  `xml:"EntryPoint"`
+	EnvironmentVariables []EnvironmentVariable  `xml:"EnvironmentVariables>EnvironmentVariable"`
+}
+
+// CodePackageEntryPoint the executable or container
+// a code package is started with
+type CodePackageEntryPoint struct {
+	ExeHost       *ExeHost       `xml:"ExeHost"`
+	ContainerHost *ContainerHost `xml:"ContainerHost"`
+}
+
+// ExeHost an executable entry point
+type ExeHost struct {
+	Program       string `xml:"Program"`
+	Arguments     string `xml:"Arguments"`
+	WorkingFolder string `xml:"WorkingFolder"`
+}
+
+// ContainerHost a container entry point
+type ContainerHost struct {
+	ImageName  string `xml:"ImageName"`
+	Commands   string `xml:"Commands"`
+	EntryPoint string `xml:"EntryPoint"`
+}
+
+// EnvironmentVariable an environment variable for a code package
+type EnvironmentVariable struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:"Value,attr"`
+	Type  string `xml:"Type,attr"`
+}
+
+// ConfigPackage a config package declared in a manifest
+type ConfigPackage struct {
+	Name    string `xml:"Name,attr"`
+	Version string `xml:"Version,attr"`
+}
+
+// DataPackage a data package declared in a manifest
+type DataPackage struct {
+	Name    string `xml:"Name,attr"`
+	Version string `xml:"Version,attr"`
+}
+
+// ServiceManifestResource the resources declared in a manifest
+type ServiceManifestResource struct {
+	Endpoints []Endpoint `xml:"Endpoints>Endpoint"`
+}
+
+// Endpoint an endpoint resource declared in a manifest
+type Endpoint struct {
+	Name           string `xml:"Name,attr"`
+	Protocol       string `xml:"Protocol,attr"`
+	Type           string `xml:"Type,attr"`
+	Port           int    `xml:"Port,attr"`
+	URIScheme      string `xml:"UriScheme,attr"`
+	PathSuffix     string `xml:"PathSuffix,attr"`
+	CodePackageRef string `xml:"CodePackageRef,attr"`
+}
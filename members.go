@@ -0,0 +1,148 @@
+package servicefabric
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Service kinds as returned by the Service Fabric API
+const (
+	ServiceKindStateless = "Stateless"
+	ServiceKindStateful  = "Stateful"
+)
+
+// PartitionMember is implemented by both the replicas of a
+// stateful partition and the instances of a stateless partition
+type PartitionMember interface {
+	// GetReplicaData returns the replica or instance id and its shared data
+	GetReplicaData() (string, *ReplicaItemBase)
+	// GetMemberID returns the replica or instance id
+	GetMemberID() uint64
+	// GetServiceKind returns Stateful for replicas and Stateless for instances
+	GetServiceKind() string
+}
+
+// PartitionMemberItemsPage encapsulates the response model for the
+// replicas or instances of a partition in the Service Fabric API
+type PartitionMemberItemsPage struct {
+	ContinuationToken *string           `json:"ContinuationToken"`
+	Items             []json.RawMessage `json:"Items"`
+}
+
+// GetMemberID returns the replica id
+func (m *ReplicaItem) GetMemberID() uint64 {
+	return parseMemberID(m.ID)
+}
+
+// GetServiceKind returns the service kind of the replica
+func (m *ReplicaItem) GetServiceKind() string {
+	return ServiceKindStateful
+}
+
+// GetMemberID returns the instance id
+func (m *InstanceItem) GetMemberID() uint64 {
+	return parseMemberID(m.ID)
+}
+
+// GetServiceKind returns the service kind of the instance
+func (m *InstanceItem) GetServiceKind() string {
+	return ServiceKindStateless
+}
+
+// GetLastInBuildDuration returns how long the replica or
+// instance spent in build, or zero if it is not known
+func (m *ReplicaItemBase) GetLastInBuildDuration() time.Duration {
+	seconds, err := strconv.ParseInt(m.LastInBuildDurationInSeconds, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetPartitionMembers returns all the replicas or instances associated
+// with a Service Fabric partition. Each item is decoded as a ReplicaItem
+// or InstanceItem according to its ServiceKind.
+func (c Client) GetPartitionMembers(appName, serviceName, partitionName string) ([]PartitionMember, error) {
+	return c.GetPartitionMembersWithOptions(appName, serviceName, partitionName, ListOptions{})
+}
+
+// GetPartitionMembersWithOptions returns the replicas or instances
+// associated with a Service Fabric partition selected by opts.
+func (c Client) GetPartitionMembersWithOptions(appName, serviceName, partitionName string, opts ListOptions) ([]PartitionMember, error) {
+	return c.getPartitionMembers(opGetReplicas, replicasPath(appName, serviceName, partitionName), opts)
+}
+
+// GetPartitionMembersByID returns all the replicas or instances
+// of the partition identified by partitionID without knowing
+// its application or service.
+func (c Client) GetPartitionMembersByID(partitionID PartitionID) ([]PartitionMember, error) {
+	return c.getPartitionMembers(opGetPartitionReplicas, "Partitions/"+escapeSegment(partitionID.String())+"/$/GetReplicas", ListOptions{})
+}
+
+func (c Client) getPartitionMembers(op operation, basePath string, opts ListOptions) ([]PartitionMember, error) {
+	var members []PartitionMember
+	var continueToken string
+	for {
+		res, err := c.getHTTP(op, basePath, withContinue(continueToken), opts.queryParams())
+		if err != nil {
+			return nil, err
+		}
+
+		var membersPage PartitionMemberItemsPage
+		err = json.Unmarshal(res, &membersPage)
+		if err != nil {
+			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+		}
+
+		for _, item := range membersPage.Items {
+			member, err := unmarshalPartitionMember(item)
+			if err != nil {
+				return nil, err
+			}
+			if _, base := member.GetReplicaData(); base == nil || opts.HealthStateFilter.Matches(base.HealthState) {
+				members = append(members, member)
+			}
+		}
+
+		continueToken = getString(membersPage.ContinuationToken)
+		c.logPage(op, len(membersPage.Items), continueToken)
+		if continueToken == "" {
+			break
+		}
+	}
+	return members, nil
+}
+
+func unmarshalPartitionMember(raw json.RawMessage) (PartitionMember, error) {
+	var header struct {
+		ServiceKind string `json:"ServiceKind"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+
+	var member PartitionMember
+	switch header.ServiceKind {
+	case ServiceKindStateful:
+		member = &ReplicaItem{}
+	case ServiceKindStateless:
+		member = &InstanceItem{}
+	default:
+		return nil, fmt.Errorf("unknown service kind %q for partition member", header.ServiceKind)
+	}
+
+	if err := json.Unmarshal(raw, member); err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return member, nil
+}
+
+func parseMemberID(id string) uint64 {
+	value, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
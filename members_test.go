@@ -0,0 +1,81 @@
+package servicefabric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetPartitionMembers(t *testing.T) {
+	server := httptest.NewServer(handlePartitionMembers())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	testCases := []struct {
+		desc          string
+		partitionName string
+		kind          string
+		id            uint64
+		inBuild       time.Duration
+	}{
+		{
+			desc:          "Stateful",
+			partitionName: "bce46a8c-b62d-4996-89dc-7ffc00a96902",
+			kind:          ServiceKindStateful,
+			id:            131496928082309293,
+			inBuild:       time.Second,
+		},
+		{
+			desc:          "Stateless",
+			partitionName: "824091ba-fa32-4e9c-9e9c-71738e018312",
+			kind:          ServiceKindStateless,
+			id:            131497042182378182,
+			inBuild:       3 * time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			members, err := sfClient.GetPartitionMembers("TestApplication", "TestApplication/TestService", test.partitionName)
+			if err != nil {
+				t.Fatalf("Exception thrown %v", err)
+			}
+
+			if len(members) != 1 {
+				t.Fatalf("Got %d members, want 1", len(members))
+			}
+			member := members[0]
+
+			if member.GetServiceKind() != test.kind {
+				t.Errorf("Got kind %s, want %s", member.GetServiceKind(), test.kind)
+			}
+			if member.GetMemberID() != test.id {
+				t.Errorf("Got id %d, want %d", member.GetMemberID(), test.id)
+			}
+
+			_, base := member.GetReplicaData()
+			if base.GetLastInBuildDuration() != test.inBuild {
+				t.Errorf("Got in build duration %v, want %v", base.GetLastInBuildDuration(), test.inBuild)
+			}
+		})
+	}
+}
+
+func TestGetPartitionMembersReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(http.NotFound))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	members, err := sfClient.GetPartitionMembers("TestApplication", "TestApplication/TestService", "bce46a8c-b62d-4996-89dc-NonExistent")
+	if err == nil {
+		t.Fatal("Error should have been returned")
+	}
+
+	if members != nil {
+		t.Errorf("Got %+v, want nil", members)
+	}
+}
@@ -0,0 +1,114 @@
+package servicefabric
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestInfo identifies a request made by a Client
+type RequestInfo struct {
+	// Operation the name of the call in the Service Fabric REST
+	// API reference, such as GetApplicationInfoList
+	Operation string
+	// Template the request path with ids replaced by placeholders,
+	// such as Applications/{applicationId}/$/GetServices, which
+	// keeps the number of distinct values low enough for metric labels
+	Template string
+	// Method the HTTP method of the request
+	Method string
+	// Attempt is reserved for counting retries of the request. The
+	// client does not retry requests, so it is always 1.
+	Attempt int
+}
+
+// RequestResult the outcome of a request made by a Client
+type RequestResult struct {
+	// StatusCode the HTTP status code of the response,
+	// zero when no response was received
+	StatusCode int
+	// Err the error sending the request or reading the response.
+	// An unsuccessful status code is not an error here.
+	Err error
+	// RequestBytes the size of the request body
+	RequestBytes int64
+	// ResponseBytes the bytes of the response body read
+	ResponseBytes int64
+	// Duration the time from sending the request until
+	// the response body was read and closed
+	Duration time.Duration
+}
+
+// Observer is notified of every request a Client makes
+type Observer interface {
+	// StartRequest is called before req is sent. It may add headers to
+	// req, such as trace propagation headers, and returns a function
+	// which is called once with the result of the request.
+	StartRequest(req *http.Request, info RequestInfo) func(result RequestResult)
+}
+
+// ObserverFunc an Observer which is only called with the result of each request
+type ObserverFunc func(info RequestInfo, result RequestResult)
+
+// StartRequest returns a function calling f with info and the result
+func (f ObserverFunc) StartRequest(req *http.Request, info RequestInfo) func(result RequestResult) {
+	return func(result RequestResult) { f(info, result) }
+}
+
+// WithObserver returns a copy of the client whose requests are
+// also observed by observer, after any observers already added
+func (c Client) WithObserver(observer Observer) *Client {
+	c.observers = append(append([]Observer(nil), c.observers...), observer)
+	return &c
+}
+
+// startRequest notifies the client's observers that req is about to be
+// sent and returns a function to call with its result, or nil if the
+// client neither observes nor logs requests
+func (c Client) startRequest(req *http.Request, info RequestInfo) func(result RequestResult) {
+	var done []func(RequestResult)
+	for _, observer := range c.observers {
+		if f := observer.StartRequest(req, info); f != nil {
+			done = append(done, f)
+		}
+	}
+	if f := c.logRequest(req.Context(), req.URL, info); f != nil {
+		done = append(done, f)
+	}
+	if len(done) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	return func(result RequestResult) {
+		result.Duration = time.Since(start)
+		for _, f := range done {
+			f(result)
+		}
+	}
+}
+
+// observedBody counts the bytes read from a response body and
+// reports the result of the request when the body is closed
+type observedBody struct {
+	io.ReadCloser
+	result RequestResult
+	done   func(result RequestResult)
+	once   sync.Once
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.result.ResponseBytes += int64(n)
+	if err != nil && err != io.EOF {
+		b.result.Err = err
+	}
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.result) })
+	return err
+}
@@ -0,0 +1,144 @@
+package servicefabric
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// recordingObserver records the info and result of every request
+type recordingObserver struct {
+	mu      sync.Mutex
+	name    string
+	order   *[]string
+	infos   []RequestInfo
+	results []RequestResult
+}
+
+func (o *recordingObserver) StartRequest(req *http.Request, info RequestInfo) func(result RequestResult) {
+	if o.order != nil {
+		*o.order = append(*o.order, o.name)
+	}
+	req.Header.Set("X-Observed-By", o.name)
+	return func(result RequestResult) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.infos = append(o.infos, info)
+		o.results = append(o.results, result)
+	}
+}
+
+func TestObserverReportsPagedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(handleApplications))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	_, err := sfClient.WithObserver(observer).GetApplications()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := []RequestInfo{
+		{Operation: "GetApplicationInfoList", Template: "Applications/", Method: "GET", Attempt: 1},
+		{Operation: "GetApplicationInfoList", Template: "Applications/", Method: "GET", Attempt: 1},
+	}
+	if !reflect.DeepEqual(observer.infos, expected) {
+		t.Errorf("Got %+v, want %+v", observer.infos, expected)
+	}
+
+	page, _ := ioutil.ReadFile("fixtures/applications.json")
+	result := observer.results[0]
+	if result.StatusCode != http.StatusOK || result.ResponseBytes != int64(len(page)) || result.Err != nil || result.Duration <= 0 {
+		t.Errorf("Got %+v, want the first page's result", result)
+	}
+}
+
+func TestObserverReportsRequestBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "6.0", nil)
+	err := sfClient.WithObserver(observer).PutProperty("TestApplication/TestService", "traefik.enable", "true")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if len(observer.results) != 1 || observer.infos[0].Method != "PUT" || observer.results[0].RequestBytes == 0 {
+		t.Errorf("Got %+v %+v, want the request body counted", observer.infos, observer.results)
+	}
+}
+
+func TestObserverReportsFailedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(http.NotFound))
+	url := server.URL
+	server.Close()
+
+	observer := &recordingObserver{}
+	sfClient, _ := NewClient(http.DefaultClient, url, "1.0", nil)
+	_, err := sfClient.WithObserver(observer).GetApplications()
+	if err == nil {
+		t.Fatal("Got nil, want the request to fail")
+	}
+
+	if len(observer.results) != 1 || observer.results[0].Err == nil || observer.results[0].StatusCode != 0 {
+		t.Errorf("Got %+v, want a failed request", observer.results)
+	}
+}
+
+func TestObserverReportsUnreadBodies(t *testing.T) {
+	server := httptest.NewServer(handleLabels())
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	exists, err := sfClient.WithObserver(observer).nameExists("TestApplication/TestService")
+	if err != nil || !exists {
+		t.Fatalf("Got %v %v, want the name to exist", exists, err)
+	}
+
+	if len(observer.results) != 1 || observer.infos[0].Operation != "NameExists" {
+		t.Errorf("Got %+v, want the request reported when its body is closed", observer.infos)
+	}
+}
+
+func TestWithObserverOrder(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("X-Observed-By"))
+		handleApplications(w, r)
+	}))
+	defer server.Close()
+
+	var order []string
+	first := &recordingObserver{name: "first", order: &order}
+	second := &recordingObserver{name: "second", order: &order}
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	observed := sfClient.WithObserver(first)
+	// Adding an observer to a copy leaves the original unchanged
+	observed.WithObserver(second)
+	observed.WithObserver(&recordingObserver{name: "unused", order: &order})
+
+	if _, err := observed.WithObserver(second).GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if _, err := sfClient.GetApplications(); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if expected := []string{"first", "second", "first", "second"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Got %v, want %v", order, expected)
+	}
+	if expected := []string{"second", "second", "", ""}; !reflect.DeepEqual(seen, expected) {
+		t.Errorf("Got %v, want %v", seen, expected)
+	}
+	if len(first.results) != 2 || len(second.results) != 2 {
+		t.Errorf("Got %d and %d results, want only the observed client's requests", len(first.results), len(second.results))
+	}
+}
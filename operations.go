@@ -0,0 +1,363 @@
+package servicefabric
+
+// operation describes a single Service Fabric REST API call
+type operation struct {
+	// name the name of the call in the Service Fabric REST API reference
+	name string
+	// template the request path with ids replaced by placeholders
+	template string
+	// minAPIVersion the oldest API version that supports the call
+	minAPIVersion string
+	// priority orders the call among requests waiting for the
+	// client's rate limit, bulk enumeration is low priority
+	priority Priority
+}
+
+var (
+	opGetApplications = operation{
+		name:          "GetApplicationInfoList",
+		template:      "Applications/",
+		minAPIVersion: "1.0",
+		priority:      PriorityLow,
+	}
+	opGetServices = operation{
+		name:          "GetServiceInfoList",
+		template:      "Applications/{applicationId}/$/GetServices",
+		minAPIVersion: "1.0",
+		priority:      PriorityLow,
+	}
+	opGetPartitions = operation{
+		name:          "GetPartitionInfoList",
+		template:      "Applications/{applicationId}/$/GetServices/{serviceId}/$/GetPartitions/",
+		minAPIVersion: "1.0",
+		priority:      PriorityLow,
+	}
+	opGetServicePartitions = operation{
+		name:          "GetPartitionInfoList",
+		template:      "Services/{serviceId}/$/GetPartitions",
+		minAPIVersion: "1.0",
+		priority:      PriorityLow,
+	}
+	opGetPartition = operation{
+		name:          "GetPartitionInfo",
+		template:      "Partitions/{partitionId}",
+		minAPIVersion: "1.0",
+		priority:      PriorityHigh,
+	}
+	opGetPartitionServiceName = operation{
+		name:          "GetServiceNameInfo",
+		template:      "Partitions/{partitionId}/$/GetServiceName",
+		minAPIVersion: "1.0",
+		priority:      PriorityHigh,
+	}
+	opGetServiceApplicationName = operation{
+		name:          "GetApplicationNameInfo",
+		template:      "Services/{serviceId}/$/GetApplicationName",
+		minAPIVersion: "1.0",
+		priority:      PriorityHigh,
+	}
+	opGetReplicas = operation{
+		name:          "GetReplicaInfoList",
+		template:      "Applications/{applicationId}/$/GetServices/{serviceId}/$/GetPartitions/{partitionId}/$/GetReplicas",
+		minAPIVersion: "1.0",
+		priority:      PriorityLow,
+	}
+	// opGetPrimaryReplicas lists replicas to resolve the primary of a
+	// partition, which must not queue behind topology walks
+	opGetPrimaryReplicas = operation{
+		name:          "GetReplicaInfoList",
+		template:      "Applications/{applicationId}/$/GetServices/{serviceId}/$/GetPartitions/{partitionId}/$/GetReplicas",
+		minAPIVersion: "1.0",
+		priority:      PriorityHigh,
+	}
+	opGetPartitionReplicas = operation{
+		name:          "GetReplicaInfoList",
+		template:      "Partitions/{partitionId}/$/GetReplicas",
+		minAPIVersion: "1.0",
+		priority:      PriorityLow,
+	}
+	opGetServiceTypes = operation{
+		name:          "GetServiceTypeInfoList",
+		template:      "ApplicationTypes/{applicationTypeName}/$/GetServiceTypes",
+		minAPIVersion: "1.0",
+	}
+	opGetServiceManifest = operation{
+		name:          "GetServiceManifest",
+		template:      "ApplicationTypes/{applicationTypeName}/$/GetServiceManifest",
+		minAPIVersion: "1.0",
+	}
+	opGetProperties = operation{
+		name:          "GetPropertyInfoList",
+		template:      "Names/{nameId}/$/GetProperties",
+		minAPIVersion: "6.0",
+		priority:      PriorityLow,
+	}
+	opNameExists = operation{
+		name:          "NameExists",
+		template:      "Names/{nameId}",
+		minAPIVersion: "6.0",
+		priority:      PriorityHigh,
+	}
+	opGetServiceGroupMembers = operation{
+		name:          "GetServiceGroupMembers",
+		template:      "Applications/{applicationId}/$/GetServices/{serviceId}/$/GetServiceGroupMembers",
+		minAPIVersion: "1.0",
+		priority:      PriorityLow,
+	}
+	opGetServiceGroupDescription = operation{
+		name:          "GetServiceGroupDescription",
+		template:      "Services/{serviceId}/$/GetServiceGroupDescription",
+		minAPIVersion: "1.0",
+	}
+	opGetClusterVersion = operation{
+		name:          "GetClusterVersion",
+		template:      "$/GetClusterVersion",
+		minAPIVersion: "6.4",
+		priority:      PriorityHigh,
+	}
+	opGetClusterEvents = operation{
+		name:          "GetClusterEventList",
+		template:      "EventsStore/Cluster/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetNodesEvents = operation{
+		name:          "GetNodesEventList",
+		template:      "EventsStore/Nodes/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetNodeEvents = operation{
+		name:          "GetNodeEventList",
+		template:      "EventsStore/Nodes/{nodeName}/$/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetApplicationsEvents = operation{
+		name:          "GetApplicationsEventList",
+		template:      "EventsStore/Applications/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetApplicationEvents = operation{
+		name:          "GetApplicationEventList",
+		template:      "EventsStore/Applications/{applicationId}/$/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetServicesEvents = operation{
+		name:          "GetServicesEventList",
+		template:      "EventsStore/Services/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetServiceEvents = operation{
+		name:          "GetServiceEventList",
+		template:      "EventsStore/Services/{serviceId}/$/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetPartitionsEvents = operation{
+		name:          "GetPartitionsEventList",
+		template:      "EventsStore/Partitions/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetPartitionEvents = operation{
+		name:          "GetPartitionEventList",
+		template:      "EventsStore/Partitions/{partitionId}/$/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetPartitionReplicasEvents = operation{
+		name:          "GetPartitionReplicasEventList",
+		template:      "EventsStore/Partitions/{partitionId}/$/Replicas/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opGetPartitionReplicaEvents = operation{
+		name:          "GetPartitionReplicaEventList",
+		template:      "EventsStore/Partitions/{partitionId}/$/Replicas/{replicaId}/$/Events",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opCreateBackupPolicy = operation{
+		name:          "CreateBackupPolicy",
+		template:      "BackupRestore/BackupPolicies/$/Create",
+		minAPIVersion: "6.4",
+	}
+	opGetBackupPolicy = operation{
+		name:          "GetBackupPolicyByName",
+		template:      "BackupRestore/BackupPolicies/{backupPolicyName}",
+		minAPIVersion: "6.4",
+	}
+	opEnableApplicationBackup = operation{
+		name:          "EnableApplicationBackup",
+		template:      "Applications/{applicationId}/$/EnableBackup",
+		minAPIVersion: "6.4",
+	}
+	opEnableServiceBackup = operation{
+		name:          "EnableServiceBackup",
+		template:      "Services/{serviceId}/$/EnableBackup",
+		minAPIVersion: "6.4",
+	}
+	opEnablePartitionBackup = operation{
+		name:          "EnablePartitionBackup",
+		template:      "Partitions/{partitionId}/$/EnableBackup",
+		minAPIVersion: "6.4",
+	}
+	opGetPartitionBackupList = operation{
+		name:          "GetPartitionBackupList",
+		template:      "Partitions/{partitionId}/$/GetBackups",
+		minAPIVersion: "6.4",
+		priority:      PriorityLow,
+	}
+	opBackupPartition = operation{
+		name:          "BackupPartition",
+		template:      "Partitions/{partitionId}/$/Backup",
+		minAPIVersion: "6.4",
+	}
+	opGetPartitionBackupProgress = operation{
+		name:          "GetPartitionBackupProgress",
+		template:      "Partitions/{partitionId}/$/GetBackupProgress",
+		minAPIVersion: "6.4",
+	}
+	opRestorePartition = operation{
+		name:          "RestorePartition",
+		template:      "Partitions/{partitionId}/$/Restore",
+		minAPIVersion: "6.4",
+	}
+	opGetPartitionRestoreProgress = operation{
+		name:          "GetPartitionRestoreProgress",
+		template:      "Partitions/{partitionId}/$/GetRestoreProgress",
+		minAPIVersion: "6.4",
+	}
+	opStartChaos = operation{
+		name:          "StartChaos",
+		template:      "Tools/Chaos/$/Start",
+		minAPIVersion: "6.2",
+	}
+	opStopChaos = operation{
+		name:          "StopChaos",
+		template:      "Tools/Chaos/$/Stop",
+		minAPIVersion: "6.2",
+	}
+	opGetChaos = operation{
+		name:          "GetChaos",
+		template:      "Tools/Chaos",
+		minAPIVersion: "6.2",
+	}
+	opGetChaosEvents = operation{
+		name:          "GetChaosEvents",
+		template:      "Tools/Chaos/Events",
+		minAPIVersion: "6.2",
+		priority:      PriorityLow,
+	}
+	opGetChaosSchedule = operation{
+		name:          "GetChaosSchedule",
+		template:      "Tools/Chaos/Schedule",
+		minAPIVersion: "6.2",
+	}
+	opPostChaosSchedule = operation{
+		name:          "PostChaosSchedule",
+		template:      "Tools/Chaos/Schedule",
+		minAPIVersion: "6.2",
+	}
+	opStartDataLoss = operation{
+		name:          "StartDataLoss",
+		template:      "Faults/Services/{serviceId}/$/GetPartitions/{partitionId}/$/StartDataLoss",
+		minAPIVersion: "6.0",
+	}
+	opGetDataLossProgress = operation{
+		name:          "GetDataLossProgress",
+		template:      "Faults/Services/{serviceId}/$/GetPartitions/{partitionId}/$/GetDataLossProgress",
+		minAPIVersion: "6.0",
+	}
+	opStartQuorumLoss = operation{
+		name:          "StartQuorumLoss",
+		template:      "Faults/Services/{serviceId}/$/GetPartitions/{partitionId}/$/StartQuorumLoss",
+		minAPIVersion: "6.0",
+	}
+	opGetQuorumLossProgress = operation{
+		name:          "GetQuorumLossProgress",
+		template:      "Faults/Services/{serviceId}/$/GetPartitions/{partitionId}/$/GetQuorumLossProgress",
+		minAPIVersion: "6.0",
+	}
+	opStartPartitionRestart = operation{
+		name:          "StartPartitionRestart",
+		template:      "Faults/Services/{serviceId}/$/GetPartitions/{partitionId}/$/StartRestart",
+		minAPIVersion: "6.0",
+	}
+	opGetPartitionRestartProgress = operation{
+		name:          "GetPartitionRestartProgress",
+		template:      "Faults/Services/{serviceId}/$/GetPartitions/{partitionId}/$/GetRestartProgress",
+		minAPIVersion: "6.0",
+	}
+	opStartNodeTransition = operation{
+		name:          "StartNodeTransition",
+		template:      "Faults/Nodes/{nodeName}/$/StartTransition/",
+		minAPIVersion: "6.0",
+	}
+	opGetNodeTransitionProgress = operation{
+		name:          "GetNodeTransitionProgress",
+		template:      "Faults/Nodes/{nodeName}/$/GetTransitionProgress",
+		minAPIVersion: "6.0",
+	}
+	opCancelOperation = operation{
+		name:          "CancelOperation",
+		template:      "Faults/$/Cancel",
+		minAPIVersion: "6.0",
+	}
+	opRestartReplica = operation{
+		name:          "RestartReplica",
+		template:      "Nodes/{nodeName}/$/GetPartitions/{partitionId}/$/GetReplicas/{replicaId}/$/Restart",
+		minAPIVersion: "6.0",
+	}
+	opRemoveReplica = operation{
+		name:          "RemoveReplica",
+		template:      "Nodes/{nodeName}/$/GetPartitions/{partitionId}/$/GetReplicas/{replicaId}/$/Delete",
+		minAPIVersion: "6.0",
+	}
+	opCreateRepairTask = operation{
+		name:          "CreateRepairTask",
+		template:      "$/CreateRepairTask",
+		minAPIVersion: "6.0",
+	}
+	opCancelRepairTask = operation{
+		name:          "CancelRepairTask",
+		template:      "$/CancelRepairTask",
+		minAPIVersion: "6.0",
+	}
+	opForceApproveRepairTask = operation{
+		name:          "ForceApproveRepairTask",
+		template:      "$/ForceApproveRepairTask",
+		minAPIVersion: "6.0",
+	}
+	opUpdateRepairExecutionState = operation{
+		name:          "UpdateRepairExecutionState",
+		template:      "$/UpdateRepairExecutionState",
+		minAPIVersion: "6.0",
+	}
+	opDeleteRepairTask = operation{
+		name:          "DeleteRepairTask",
+		template:      "$/DeleteRepairTask",
+		minAPIVersion: "6.0",
+	}
+	opGetRepairTaskList = operation{
+		name:          "GetRepairTaskList",
+		template:      "$/GetRepairTaskList",
+		minAPIVersion: "6.0",
+		priority:      PriorityLow,
+	}
+	opGetProperty = operation{
+		name:          "GetPropertyInfo",
+		template:      "Names/{nameId}/$/GetProperty",
+		minAPIVersion: "6.0",
+		priority:      PriorityHigh,
+	}
+	opPutProperty = operation{
+		name:          "PutProperty",
+		template:      "Names/{nameId}/$/GetProperty",
+		minAPIVersion: "6.0",
+	}
+)
@@ -0,0 +1,123 @@
+package servicefabric
+
+import (
+	"strconv"
+	"time"
+)
+
+// HealthStateFilter flags selecting entities by health state,
+// values can be combined with a bitwise OR
+type HealthStateFilter int
+
+// Health state filters as defined by the Service Fabric API
+const (
+	HealthStateFilterDefault HealthStateFilter = 0
+	HealthStateFilterNone    HealthStateFilter = 1
+	HealthStateFilterOk      HealthStateFilter = 2
+	HealthStateFilterWarning HealthStateFilter = 4
+	HealthStateFilterError   HealthStateFilter = 8
+	HealthStateFilterAll     HealthStateFilter = 65535
+)
+
+// Matches reports whether an entity in healthState is selected by the filter
+func (f HealthStateFilter) Matches(healthState string) bool {
+	switch f {
+	case HealthStateFilterDefault, HealthStateFilterAll:
+		return true
+	}
+
+	switch healthState {
+	case "Ok":
+		return f&HealthStateFilterOk != 0
+	case "Warning":
+		return f&HealthStateFilterWarning != 0
+	case "Error":
+		return f&HealthStateFilterError != 0
+	}
+	return false
+}
+
+// ApplicationDefinitionKindFilter flags selecting applications by
+// how they were defined, values can be combined with a bitwise OR
+type ApplicationDefinitionKindFilter int
+
+// Application definition kind filters as defined by the Service Fabric API
+const (
+	ApplicationDefinitionKindFilterDefault                             ApplicationDefinitionKindFilter = 0
+	ApplicationDefinitionKindFilterServiceFabricApplicationDescription ApplicationDefinitionKindFilter = 1
+	ApplicationDefinitionKindFilterCompose                             ApplicationDefinitionKindFilter = 2
+	ApplicationDefinitionKindFilterAll                                 ApplicationDefinitionKindFilter = 65535
+)
+
+// ListOptions are accepted by every list call
+type ListOptions struct {
+	// MaxResults the maximum number of results returned per page,
+	// zero leaves the page size up to the cluster
+	MaxResults int64
+	// Timeout the server side timeout for each request,
+	// zero leaves the timeout up to the cluster
+	Timeout time.Duration
+	// HealthStateFilter selects results by health state. The list
+	// endpoints do not accept a health filter so it is applied to the
+	// results once they are returned.
+	HealthStateFilter HealthStateFilter
+}
+
+func (o ListOptions) queryParams() queryParamsFunc {
+	var params []queryParamsFunc
+	if o.MaxResults > 0 {
+		params = append(params, withParam("MaxResults", strconv.FormatInt(o.MaxResults, 10)))
+	}
+	if o.Timeout > 0 {
+		params = append(params, withTimeout(o.Timeout))
+	}
+	return withParams(params...)
+}
+
+// ApplicationsOptions filters the results of GetApplicationsWithOptions
+type ApplicationsOptions struct {
+	ListOptions
+	// ApplicationTypeName only return applications of this type
+	ApplicationTypeName string
+	// ApplicationDefinitionKindFilter only return applications defined this way
+	ApplicationDefinitionKindFilter ApplicationDefinitionKindFilter
+	// ExcludeApplicationParameters omit the Parameters of each application
+	ExcludeApplicationParameters bool
+}
+
+func (o ApplicationsOptions) queryParams() queryParamsFunc {
+	var params []queryParamsFunc
+	if o.ApplicationTypeName != "" {
+		params = append(params, withParam("ApplicationTypeName", o.ApplicationTypeName))
+	}
+	if o.ApplicationDefinitionKindFilter != ApplicationDefinitionKindFilterDefault {
+		params = append(params, withParam("ApplicationDefinitionKindFilter", strconv.Itoa(int(o.ApplicationDefinitionKindFilter))))
+	}
+	if o.ExcludeApplicationParameters {
+		params = append(params, withParam("ExcludeApplicationParameters", "true"))
+	}
+	return withParams(append(params, o.ListOptions.queryParams())...)
+}
+
+// ServicesOptions filters the results of GetServicesWithOptions
+type ServicesOptions struct {
+	ListOptions
+	// ServiceTypeName only return services of this type
+	ServiceTypeName string
+}
+
+func (o ServicesOptions) queryParams() queryParamsFunc {
+	var params []queryParamsFunc
+	if o.ServiceTypeName != "" {
+		params = append(params, withParam("ServiceTypeName", o.ServiceTypeName))
+	}
+	return withParams(append(params, o.ListOptions.queryParams())...)
+}
+
+func withTimeout(timeout time.Duration) queryParamsFunc {
+	seconds := int64(timeout / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return withParam("timeout", strconv.FormatInt(seconds, 10))
+}
@@ -0,0 +1,94 @@
+package servicefabric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetApplicationsWithOptions(t *testing.T) {
+	server := httptest.NewServer(handleQuery(
+		"/Applications/",
+		"api-version=1.0&ApplicationTypeName=Test+Type%2Bv1&ApplicationDefinitionKindFilter=2&ExcludeApplicationParameters=true&MaxResults=10&timeout=30",
+		"fixtures/applications_continue.json"))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	actual, err := sfClient.GetApplicationsWithOptions(ApplicationsOptions{
+		ListOptions: ListOptions{
+			MaxResults: 10,
+			Timeout:    30 * time.Second,
+		},
+		ApplicationTypeName:             "Test Type+v1",
+		ApplicationDefinitionKindFilter: ApplicationDefinitionKindFilterCompose,
+		ExcludeApplicationParameters:    true,
+	})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if len(actual.Items) != 1 {
+		t.Errorf("Got %d applications, want 1", len(actual.Items))
+	}
+}
+
+func TestGetServicesWithOptions(t *testing.T) {
+	server := httptest.NewServer(handleQuery(
+		"/Applications/TestApplication/$/GetServices",
+		"api-version=1.0&ServiceTypeName=TestServiceType",
+		"fixtures/services.json"))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	testCases := []struct {
+		desc     string
+		filter   HealthStateFilter
+		expected int
+	}{
+		{desc: "Default", filter: HealthStateFilterDefault, expected: 1},
+		{desc: "Ok", filter: HealthStateFilterOk, expected: 1},
+		{desc: "Warning or Error", filter: HealthStateFilterWarning | HealthStateFilterError, expected: 0},
+	}
+
+	for _, test := range testCases {
+		actual, err := sfClient.GetServicesWithOptions("TestApplication", ServicesOptions{
+			ListOptions:     ListOptions{HealthStateFilter: test.filter},
+			ServiceTypeName: "TestServiceType",
+		})
+		if err != nil {
+			t.Fatalf("%s: Exception thrown %v", test.desc, err)
+		}
+
+		if len(actual.Items) != test.expected {
+			t.Errorf("%s: Got %d services, want %d", test.desc, len(actual.Items), test.expected)
+		}
+	}
+}
+
+func TestGetApplicationsFetchesNextPage(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		handleApplications(w, r)
+	}))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	actual, err := sfClient.GetApplications()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := []string{"api-version=1.0", "api-version=1.0&ContinuationToken=00001234"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Errorf("Got %v, want %v", queries, expected)
+	}
+	if len(actual.Items) != 2 {
+		t.Errorf("Got %d applications, want both pages", len(actual.Items))
+	}
+}
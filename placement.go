@@ -0,0 +1,189 @@
+package servicefabric
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Placement policy types as returned in the Type
+// field of a service placement policy description
+const (
+	PlacementPolicyInvalidDomain             = "InvalidDomain"
+	PlacementPolicyRequireDomain             = "RequireDomain"
+	PlacementPolicyPreferPrimaryDomain       = "PreferPrimaryDomain"
+	PlacementPolicyRequireDomainDistribution = "RequireDomainDistribution"
+	PlacementPolicyNonPartiallyPlaceService  = "NonPartiallyPlaceService"
+)
+
+// ServicePlacementPolicyDescription is implemented by
+// every placement policy variant a service type can declare
+type ServicePlacementPolicyDescription interface {
+	PolicyType() string
+}
+
+// ServicePlacementInvalidDomainPolicyDescription describes a fault
+// or upgrade domain that should not be used for placement
+type ServicePlacementInvalidDomainPolicyDescription struct {
+	DomainName string `json:"DomainName"`
+}
+
+// PolicyType returns the placement policy type
+func (ServicePlacementInvalidDomainPolicyDescription) PolicyType() string {
+	return PlacementPolicyInvalidDomain
+}
+
+// ServicePlacementRequiredDomainPolicyDescription describes a domain
+// all replicas of the service must be placed in
+type ServicePlacementRequiredDomainPolicyDescription struct {
+	DomainName string `json:"DomainName"`
+}
+
+// PolicyType returns the placement policy type
+func (ServicePlacementRequiredDomainPolicyDescription) PolicyType() string {
+	return PlacementPolicyRequireDomain
+}
+
+// ServicePlacementPreferPrimaryDomainPolicyDescription describes the
+// domain the primary replica should preferably be placed in
+type ServicePlacementPreferPrimaryDomainPolicyDescription struct {
+	DomainName string `json:"DomainName"`
+}
+
+// PolicyType returns the placement policy type
+func (ServicePlacementPreferPrimaryDomainPolicyDescription) PolicyType() string {
+	return PlacementPolicyPreferPrimaryDomain
+}
+
+// ServicePlacementRequireDomainDistributionPolicyDescription describes a
+// domain across which no two replicas of a partition may share a location
+type ServicePlacementRequireDomainDistributionPolicyDescription struct {
+	DomainName string `json:"DomainName"`
+}
+
+// PolicyType returns the placement policy type
+func (ServicePlacementRequireDomainDistributionPolicyDescription) PolicyType() string {
+	return PlacementPolicyRequireDomainDistribution
+}
+
+// ServicePlacementNonPartiallyPlaceServicePolicyDescription requires
+// that either all or none of a service's replicas are placed
+type ServicePlacementNonPartiallyPlaceServicePolicyDescription struct{}
+
+// PolicyType returns the placement policy type
+func (ServicePlacementNonPartiallyPlaceServicePolicyDescription) PolicyType() string {
+	return PlacementPolicyNonPartiallyPlaceService
+}
+
+// ServicePlacementUnknownPolicyDescription holds a placement policy
+// whose type is not known to this client so it is not lost on decode
+type ServicePlacementUnknownPolicyDescription struct {
+	Type string
+	Raw  json.RawMessage
+}
+
+// PolicyType returns the placement policy type
+func (p ServicePlacementUnknownPolicyDescription) PolicyType() string {
+	return p.Type
+}
+
+// ServicePlacementPolicyDescriptions is a list of placement
+// policies decoded into their concrete types by Type
+type ServicePlacementPolicyDescriptions []ServicePlacementPolicyDescription
+
+// UnmarshalJSON decodes each policy based on its Type field
+func (p *ServicePlacementPolicyDescriptions) UnmarshalJSON(data []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+
+	policies := make(ServicePlacementPolicyDescriptions, 0, len(raws))
+	for _, raw := range raws {
+		policy, err := unmarshalPlacementPolicy(raw)
+		if err != nil {
+			return err
+		}
+		policies = append(policies, policy)
+	}
+	*p = policies
+	return nil
+}
+
+// MarshalJSON encodes each policy along with its Type field
+func (p ServicePlacementPolicyDescriptions) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+
+	raws := make([]json.RawMessage, 0, len(p))
+	for _, policy := range p {
+		if unknown, ok := policy.(ServicePlacementUnknownPolicyDescription); ok {
+			raws = append(raws, unknown.Raw)
+			continue
+		}
+
+		raw, err := json.Marshal(struct {
+			Type       string `json:"Type"`
+			DomainName string `json:"DomainName,omitempty"`
+		}{policy.PolicyType(), placementPolicyDomain(policy)})
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, raw)
+	}
+	return json.Marshal(raws)
+}
+
+func unmarshalPlacementPolicy(raw json.RawMessage) (ServicePlacementPolicyDescription, error) {
+	var header struct {
+		Type string `json:"Type"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("could not deserialise placement policy: %+v", err)
+	}
+
+	var (
+		policy ServicePlacementPolicyDescription
+		err    error
+	)
+	switch header.Type {
+	case PlacementPolicyInvalidDomain:
+		var v ServicePlacementInvalidDomainPolicyDescription
+		err = json.Unmarshal(raw, &v)
+		policy = v
+	case PlacementPolicyRequireDomain:
+		var v ServicePlacementRequiredDomainPolicyDescription
+		err = json.Unmarshal(raw, &v)
+		policy = v
+	case PlacementPolicyPreferPrimaryDomain:
+		var v ServicePlacementPreferPrimaryDomainPolicyDescription
+		err = json.Unmarshal(raw, &v)
+		policy = v
+	case PlacementPolicyRequireDomainDistribution:
+		var v ServicePlacementRequireDomainDistributionPolicyDescription
+		err = json.Unmarshal(raw, &v)
+		policy = v
+	case PlacementPolicyNonPartiallyPlaceService:
+		policy = ServicePlacementNonPartiallyPlaceServicePolicyDescription{}
+	default:
+		policy = ServicePlacementUnknownPolicyDescription{Type: header.Type, Raw: raw}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise %s placement policy: %+v", header.Type, err)
+	}
+	return policy, nil
+}
+
+func placementPolicyDomain(policy ServicePlacementPolicyDescription) string {
+	switch v := policy.(type) {
+	case ServicePlacementInvalidDomainPolicyDescription:
+		return v.DomainName
+	case ServicePlacementRequiredDomainPolicyDescription:
+		return v.DomainName
+	case ServicePlacementPreferPrimaryDomainPolicyDescription:
+		return v.DomainName
+	case ServicePlacementRequireDomainDistributionPolicyDescription:
+		return v.DomainName
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package servicefabric
+
+import (
+	"context"
+	"time"
+)
+
+// pollUntil calls check until it reports done or fails, waiting
+// between calls for an interval doubling from initial up to max.
+// If ctx is done first its error is returned.
+func pollUntil(ctx context.Context, initial, max time.Duration, check func() (bool, error)) error {
+	interval := initial
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > max {
+			interval = max
+		}
+	}
+}
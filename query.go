@@ -1,6 +1,58 @@
 package servicefabric
 
-type queryParamsFunc func(params []string) []string
+import (
+	"net/url"
+	"strings"
+)
+
+// queryParam a single name and value in a query string
+type queryParam struct {
+	name  string
+	value string
+}
+
+// query an ordered list of query parameters. Unlike url.Values
+// parameters are encoded in the order they were added and the
+// same name may be added more than once.
+type query struct {
+	params []queryParam
+}
+
+// add appends a parameter, keeping any existing parameters of the same name
+func (q *query) add(name, value string) {
+	q.params = append(q.params, queryParam{name: name, value: value})
+}
+
+// has reports whether a parameter with the given name has been added
+func (q *query) has(name string) bool {
+	for _, param := range q.params {
+		if param.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the value of the first parameter with the given name
+func (q *query) get(name string) string {
+	for _, param := range q.params {
+		if param.name == name {
+			return param.value
+		}
+	}
+	return ""
+}
+
+// encode returns the URL encoded query string
+func (q *query) encode() string {
+	encoded := make([]string, 0, len(q.params))
+	for _, param := range q.params {
+		encoded = append(encoded, url.QueryEscape(param.name)+"="+url.QueryEscape(param.value))
+	}
+	return strings.Join(encoded, "&")
+}
+
+type queryParamsFunc func(q *query)
 
 func withContinue(token string) queryParamsFunc {
 	if len(token) == 0 {
@@ -10,11 +62,17 @@ func withContinue(token string) queryParamsFunc {
 }
 
 func withParam(name, value string) queryParamsFunc {
-	return func(params []string) []string {
-		return append(params, name+"="+value)
+	return func(q *query) {
+		q.add(name, value)
 	}
 }
 
-func noOp(params []string) []string {
-	return params
+func withParams(paramsFuncs ...queryParamsFunc) queryParamsFunc {
+	return func(q *query) {
+		for _, paramsFunc := range paramsFuncs {
+			paramsFunc(q)
+		}
+	}
 }
+
+func noOp(q *query) {}
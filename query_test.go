@@ -0,0 +1,82 @@
+package servicefabric
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestQueryEncode(t *testing.T) {
+	q := &query{}
+	withParams(
+		withParam("api-version", "1.0"),
+		withContinue("a+b&c=d"),
+		withContinue(""),
+		withParam("Name", "with space"),
+		withParam("Name", "repeated"),
+	)(q)
+
+	expected := "api-version=1.0&ContinuationToken=a%2Bb%26c%3Dd&Name=with+space&Name=repeated"
+	if actual := q.encode(); actual != expected {
+		t.Errorf("Got %s, want %s", actual, expected)
+	}
+
+	if q.get("Name") != "with space" {
+		t.Errorf("Got %s, want the first value of Name", q.get("Name"))
+	}
+}
+
+func TestGetURL(t *testing.T) {
+	sfClient, _ := NewClient(http.DefaultClient, "http://localhost:19080/", "6.0", nil)
+
+	actual, err := sfClient.getURL(opGetProperties, "Names/"+escapePath("TestApplication/TestGroup#Member")+"/$/GetProperties", withParam("IncludeValues", "true"))
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := "http://localhost:19080/Names/TestApplication/TestGroup%23Member/$/GetProperties?api-version=6.0&IncludeValues=true"
+	if actual != expected {
+		t.Errorf("Got %s, want %s", actual, expected)
+	}
+}
+
+func TestGetURLTimeoutFromContext(t *testing.T) {
+	sfClient, _ := NewClient(http.DefaultClient, "http://localhost:19080", "6.0", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+	defer cancel()
+
+	testCases := []struct {
+		desc     string
+		params   []queryParamsFunc
+		expected int64
+	}{
+		{desc: "From deadline", expected: 89},
+		{desc: "Explicit timeout wins", params: []queryParamsFunc{withTimeout(5 * time.Second)}, expected: 5},
+	}
+
+	for _, test := range testCases {
+		actual, err := sfClient.WithContext(ctx).getURL(opGetApplications, "Applications/", test.params...)
+		if err != nil {
+			t.Fatalf("%s: Exception thrown %v", test.desc, err)
+		}
+
+		u, _ := url.Parse(actual)
+		timeouts := u.Query()["timeout"]
+		if len(timeouts) != 1 {
+			t.Fatalf("%s: Got timeouts %v, want exactly one", test.desc, timeouts)
+		}
+		timeout, _ := strconv.ParseInt(timeouts[0], 10, 64)
+		if timeout < test.expected || timeout > test.expected+1 {
+			t.Errorf("%s: Got timeout %d, want %d", test.desc, timeout, test.expected)
+		}
+	}
+
+	withoutDeadline, _ := sfClient.getURL(opGetApplications, "Applications/")
+	if withoutDeadline != "http://localhost:19080/Applications/?api-version=6.0" {
+		t.Errorf("Got %s, want no timeout without a deadline", withoutDeadline)
+	}
+}
@@ -0,0 +1,209 @@
+package servicefabric
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// Priority orders requests waiting for a client's rate limit,
+// waiting requests of a higher priority are sent first
+type Priority int
+
+// Request priorities. List calls which walk the cluster's topology are
+// low priority, so that lookups such as NameExists, GetClusterVersion or
+// GetPrimary made while a walk is under way are not queued behind it.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// RateLimit limits the requests a client sends to the cluster
+type RateLimit struct {
+	// RequestsPerSecond the rate requests are sent at,
+	// zero does not limit the rate
+	RequestsPerSecond float64
+	// Burst the number of requests which may be sent at once before
+	// the rate applies, values below one are treated as one
+	Burst int
+	// MaxInFlight the number of requests which may be in flight at
+	// once, zero does not limit them. A request is in flight until its
+	// response body is closed.
+	MaxInFlight int
+	// Priorities overrides the priority of calls by their name in the
+	// Service Fabric REST API reference, such as GetApplicationInfoList.
+	// An override applies to every use of the call, such as both the
+	// replica lists of GetReplicas and those GetPrimary resolves.
+	Priorities map[string]Priority
+}
+
+// WithRateLimit returns a copy of the client whose requests are limited
+// by limit. The limit is shared by every goroutine using the returned
+// client and by the copies made from it, such as with WithContext.
+func (c Client) WithRateLimit(limit RateLimit) *Client {
+	c.limiter = newLimiter(limit)
+	return &c
+}
+
+// limiter a token bucket and semaphore granting requests in priority order
+type limiter struct {
+	limit RateLimit
+
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	inFlight int
+	waiting  []*waiter
+	seq      uint64
+	timer    *time.Timer
+}
+
+// waiter a request waiting for the limiter
+type waiter struct {
+	priority Priority
+	seq      uint64
+	granted  bool
+	ready    chan struct{}
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &limiter{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// priority returns the priority of op
+func (l *limiter) priority(op operation) Priority {
+	if p, ok := l.limit.Priorities[op.name]; ok {
+		return p
+	}
+	return op.priority
+}
+
+// acquire waits until a request for op may be sent. It returns a
+// function releasing the request's in flight slot, or ctx's error
+// if ctx is done first.
+func (l *limiter) acquire(ctx context.Context, op operation) (func(), error) {
+	l.mu.Lock()
+	l.seq++
+	w := &waiter{priority: l.priority(op), seq: l.seq, ready: make(chan struct{})}
+	l.waiting = append(l.waiting, w)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.release, nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// Granted as ctx was done, hand the slot to the next request
+		l.inFlight--
+		l.dispatch()
+	} else {
+		l.remove(w)
+	}
+	return nil, ctx.Err()
+}
+
+// release frees the in flight slot of a request
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.dispatch()
+}
+
+// dispatch grants waiting requests, highest priority first, for as
+// long as there are tokens and in flight slots for them. When it runs
+// out of tokens it schedules itself for when the next one is added.
+// l.mu must be held.
+func (l *limiter) dispatch() {
+	now := time.Now()
+	if rate := l.limit.RequestsPerSecond; rate > 0 {
+		l.tokens = math.Min(float64(l.limit.Burst), l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+	l.last = now
+
+	for len(l.waiting) > 0 {
+		if l.limit.MaxInFlight > 0 && l.inFlight >= l.limit.MaxInFlight {
+			return
+		}
+		if l.limit.RequestsPerSecond > 0 && l.tokens < 1 {
+			l.schedule(time.Duration((1 - l.tokens) / l.limit.RequestsPerSecond * float64(time.Second)))
+			return
+		}
+
+		w := l.next()
+		l.remove(w)
+		if l.limit.RequestsPerSecond > 0 {
+			l.tokens--
+		}
+		l.inFlight++
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// schedule calls dispatch after wait, unless already scheduled
+func (l *limiter) schedule(wait time.Duration) {
+	if l.timer != nil {
+		return
+	}
+	l.timer = time.AfterFunc(wait, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.timer = nil
+		l.dispatch()
+	})
+}
+
+// next returns the waiting request of the highest priority
+// which has waited longest
+func (l *limiter) next() *waiter {
+	next := l.waiting[0]
+	for _, w := range l.waiting[1:] {
+		if w.priority > next.priority || (w.priority == next.priority && w.seq < next.seq) {
+			next = w
+		}
+	}
+	return next
+}
+
+func (l *limiter) remove(w *waiter) {
+	for i, waiting := range l.waiting {
+		if waiting == w {
+			l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+// waitForLimit waits for the client's rate limit before a request for op
+// is sent and returns a function to call once the request is done, or nil
+// if the client's requests are not limited
+func (c Client) waitForLimit(op operation) (func(), error) {
+	if c.limiter == nil {
+		return nil, nil
+	}
+
+	start := time.Now()
+	release, err := c.limiter.acquire(c.context(), op)
+	if err != nil {
+		return nil, err
+	}
+	if wait := time.Since(start); wait >= time.Millisecond {
+		c.debug("servicefabric: request delayed by rate limit",
+			slog.String("operation", op.name),
+			slog.Int("priority", int(c.limiter.priority(op))),
+			slog.Duration("wait", wait))
+	}
+	return release, nil
+}
@@ -0,0 +1,257 @@
+package servicefabric
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newLimitedServer returns a server answering application and replica lists
+// and cluster version lookups, recording the path of each request it receives. Requests
+// block until release is closed, if it is not nil.
+func newLimitedServer(release <-chan struct{}) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if release != nil {
+			<-release
+		}
+
+		switch {
+		case r.URL.Path == "/$/GetClusterVersion":
+			w.Write([]byte(`{"Version":"6.4.617.9590"}`))
+		case strings.HasSuffix(r.URL.Path, "/$/GetReplicas"):
+			handleFixture("fixtures/replicas.json")(w, r)
+		default:
+			w.Write([]byte(`{"ContinuationToken":"","Items":[]}`))
+		}
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+// waitForWaiting waits until n requests are waiting for l
+func waitForWaiting(t *testing.T, l *limiter, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		l.mu.Lock()
+		waiting := len(l.waiting)
+		l.mu.Unlock()
+		if waiting == n {
+			return
+		}
+	}
+	t.Fatalf("Timed out waiting for %d waiting requests", n)
+}
+
+func TestRateLimitMaxInFlight(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{"ContinuationToken":"","Items":[]}`))
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	limited := sfClient.WithRateLimit(RateLimit{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Copies of the client share its limit
+			if _, err := limited.WithContext(context.Background()).GetApplications(); err != nil {
+				t.Errorf("Exception thrown %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxInFlight != 2 {
+		t.Errorf("Got %d requests in flight, want 2", maxInFlight)
+	}
+	if limited.limiter.inFlight != 0 {
+		t.Errorf("Got %d in flight slots taken, want all released", limited.limiter.inFlight)
+	}
+}
+
+func TestRateLimitRequestsPerSecond(t *testing.T) {
+	server, paths := newLimitedServer(nil)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	limited := sfClient.WithRateLimit(RateLimit{RequestsPerSecond: 50, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := limited.GetApplications(); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+	}
+
+	// Two requests are sent at once, the other three wait 20ms each
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("Got 5 requests in %v, want at least 60ms", elapsed)
+	}
+	if len(paths()) != 5 {
+		t.Errorf("Got %d requests, want 5", len(paths()))
+	}
+}
+
+func TestRateLimitPriority(t *testing.T) {
+	release := make(chan struct{})
+	server, paths := newLimitedServer(release)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "6.4", nil)
+	limited := sfClient.WithRateLimit(RateLimit{MaxInFlight: 1})
+
+	var wg sync.WaitGroup
+	call := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				t.Errorf("Exception thrown %v", err)
+			}
+		}()
+	}
+	getApplications := func() error {
+		_, err := limited.GetApplications()
+		return err
+	}
+
+	// The first enumeration takes the only slot, the second
+	// waits and is overtaken by the cluster version lookup
+	call(getApplications)
+	waitForWaiting(t, limited.limiter, 0)
+	call(getApplications)
+	waitForWaiting(t, limited.limiter, 1)
+	call(func() error {
+		_, err := limited.GetClusterVersion()
+		return err
+	})
+	waitForWaiting(t, limited.limiter, 2)
+	close(release)
+	wg.Wait()
+
+	expected := []string{"/Applications/", "/$/GetClusterVersion", "/Applications/"}
+	if !reflect.DeepEqual(paths(), expected) {
+		t.Errorf("Got %v, want %v", paths(), expected)
+	}
+}
+
+func TestRateLimitPriorityResolvesPrimary(t *testing.T) {
+	release := make(chan struct{})
+	server, paths := newLimitedServer(release)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	limited := sfClient.WithRateLimit(RateLimit{MaxInFlight: 1})
+
+	var wg sync.WaitGroup
+	call := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				t.Errorf("Exception thrown %v", err)
+			}
+		}()
+	}
+	getReplicas := func() error {
+		_, err := limited.GetReplicas("TestApplication", "TestApplication/TestService", "walk")
+		return err
+	}
+
+	// Resolving the primary overtakes the replica list of a topology walk
+	call(getReplicas)
+	waitForWaiting(t, limited.limiter, 0)
+	call(getReplicas)
+	waitForWaiting(t, limited.limiter, 1)
+	call(func() error {
+		_, err := limited.GetPrimary(context.Background(), "TestApplication", "TestApplication/TestService", "resolve")
+		return err
+	})
+	waitForWaiting(t, limited.limiter, 2)
+	close(release)
+	wg.Wait()
+
+	walk := "/Applications/TestApplication/$/GetServices/TestApplication/TestService/$/GetPartitions/walk/$/GetReplicas"
+	resolve := "/Applications/TestApplication/$/GetServices/TestApplication/TestService/$/GetPartitions/resolve/$/GetReplicas"
+	expected := []string{walk, resolve, walk}
+	if !reflect.DeepEqual(paths(), expected) {
+		t.Errorf("Got %v, want %v", paths(), expected)
+	}
+}
+
+func TestRateLimitPriorityOverride(t *testing.T) {
+	l := newLimiter(RateLimit{Priorities: map[string]Priority{"GetApplicationInfoList": PriorityHigh}})
+	if p := l.priority(opGetApplications); p != PriorityHigh {
+		t.Errorf("Got %v, want %v", p, PriorityHigh)
+	}
+	if p := l.priority(opGetServices); p != PriorityLow {
+		t.Errorf("Got %v, want %v", p, PriorityLow)
+	}
+	if p := l.priority(opPutProperty); p != PriorityNormal {
+		t.Errorf("Got %v, want %v", p, PriorityNormal)
+	}
+}
+
+func TestRateLimitContextDone(t *testing.T) {
+	release := make(chan struct{})
+	server, _ := newLimitedServer(release)
+	defer server.Close()
+	defer close(release)
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+	limited := sfClient.WithRateLimit(RateLimit{MaxInFlight: 1})
+
+	go limited.GetApplications()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		limited.limiter.mu.Lock()
+		inFlight := limited.limiter.inFlight
+		limited.limiter.mu.Unlock()
+		if inFlight == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the first request")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := limited.WithContext(ctx).GetApplications()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Got %v, want the context's error", err)
+	}
+	waitForWaiting(t, limited.limiter, 0)
+}
@@ -0,0 +1,267 @@
+package servicefabric
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RepairTaskState the state of a repair task
+type RepairTaskState string
+
+// Repair task states as returned by the Service Fabric API
+const (
+	RepairTaskStateInvalid   RepairTaskState = "Invalid"
+	RepairTaskStateCreated   RepairTaskState = "Created"
+	RepairTaskStateClaimed   RepairTaskState = "Claimed"
+	RepairTaskStatePreparing RepairTaskState = "Preparing"
+	RepairTaskStateApproved  RepairTaskState = "Approved"
+	RepairTaskStateExecuting RepairTaskState = "Executing"
+	RepairTaskStateRestoring RepairTaskState = "Restoring"
+	RepairTaskStateCompleted RepairTaskState = "Completed"
+)
+
+// RepairTaskStateFilter flags selecting repair tasks by
+// state, values can be combined with a bitwise OR
+type RepairTaskStateFilter int
+
+// Repair task state filters as defined by the Service Fabric API
+const (
+	RepairTaskStateFilterDefault        RepairTaskStateFilter = 0
+	RepairTaskStateFilterCreated        RepairTaskStateFilter = 1
+	RepairTaskStateFilterClaimed        RepairTaskStateFilter = 2
+	RepairTaskStateFilterPreparing      RepairTaskStateFilter = 4
+	RepairTaskStateFilterApproved       RepairTaskStateFilter = 8
+	RepairTaskStateFilterExecuting      RepairTaskStateFilter = 16
+	RepairTaskStateFilterReadyToExecute RepairTaskStateFilter = 24
+	RepairTaskStateFilterRestoring      RepairTaskStateFilter = 32
+	RepairTaskStateFilterActive         RepairTaskStateFilter = 63
+	RepairTaskStateFilterCompleted      RepairTaskStateFilter = 64
+	RepairTaskStateFilterAll            RepairTaskStateFilter = 127
+)
+
+// Repair task flags as defined by the Service Fabric API
+const (
+	RepairTaskFlagCancelRequested = 1
+	RepairTaskFlagAbortRequested  = 2
+	RepairTaskFlagForcedApproval  = 4
+)
+
+// RepairTaskResult the result of a completed repair task
+type RepairTaskResult string
+
+// Repair task results as returned by the Service Fabric API
+const (
+	RepairTaskResultInvalid     RepairTaskResult = "Invalid"
+	RepairTaskResultSucceeded   RepairTaskResult = "Succeeded"
+	RepairTaskResultCancelled   RepairTaskResult = "Cancelled"
+	RepairTaskResultInterrupted RepairTaskResult = "Interrupted"
+	RepairTaskResultFailed      RepairTaskResult = "Failed"
+	RepairTaskResultPending     RepairTaskResult = "Pending"
+)
+
+// NodeImpactLevel the impact of a repair on a node
+type NodeImpactLevel string
+
+// Node impact levels as defined by the Service Fabric API
+const (
+	NodeImpactLevelInvalid    NodeImpactLevel = "Invalid"
+	NodeImpactLevelNone       NodeImpactLevel = "None"
+	NodeImpactLevelRestart    NodeImpactLevel = "Restart"
+	NodeImpactLevelRemoveData NodeImpactLevel = "RemoveData"
+	NodeImpactLevelRemoveNode NodeImpactLevel = "RemoveNode"
+)
+
+// RepairKindNode the kind of repair targets and impacts which list nodes
+const RepairKindNode = "Node"
+
+// RepairTargetDescription the entities a repair task targets
+type RepairTargetDescription struct {
+	// Kind Node is the only kind defined by Service Fabric
+	Kind      string   `json:"Kind"`
+	NodeNames []string `json:"NodeNames,omitempty"`
+}
+
+// NodeRepairTarget returns a target listing nodeNames
+func NodeRepairTarget(nodeNames ...string) *RepairTargetDescription {
+	return &RepairTargetDescription{Kind: RepairKindNode, NodeNames: nodeNames}
+}
+
+// NodeImpact the impact of a repair on a single node
+type NodeImpact struct {
+	NodeName    string          `json:"NodeName"`
+	ImpactLevel NodeImpactLevel `json:"ImpactLevel,omitempty"`
+}
+
+// RepairImpactDescription the expected impact of a repair,
+// which the cluster prepares for before approving the repair
+type RepairImpactDescription struct {
+	// Kind Node is the only kind defined by Service Fabric
+	Kind           string       `json:"Kind"`
+	NodeImpactList []NodeImpact `json:"NodeImpactList,omitempty"`
+}
+
+// NodeRepairImpact returns an impact of level on each of nodeNames
+func NodeRepairImpact(level NodeImpactLevel, nodeNames ...string) *RepairImpactDescription {
+	impact := &RepairImpactDescription{Kind: RepairKindNode}
+	for _, nodeName := range nodeNames {
+		impact.NodeImpactList = append(impact.NodeImpactList, NodeImpact{NodeName: nodeName, ImpactLevel: level})
+	}
+	return impact
+}
+
+// RepairTaskHistory records when a repair task entered each state
+type RepairTaskHistory struct {
+	CreatedUtcTimestamp                   time.Time `json:"CreatedUtcTimestamp"`
+	ClaimedUtcTimestamp                   time.Time `json:"ClaimedUtcTimestamp"`
+	PreparingUtcTimestamp                 time.Time `json:"PreparingUtcTimestamp"`
+	ApprovedUtcTimestamp                  time.Time `json:"ApprovedUtcTimestamp"`
+	ExecutingUtcTimestamp                 time.Time `json:"ExecutingUtcTimestamp"`
+	RestoringUtcTimestamp                 time.Time `json:"RestoringUtcTimestamp"`
+	CompletedUtcTimestamp                 time.Time `json:"CompletedUtcTimestamp"`
+	PreparingHealthCheckStartUtcTimestamp time.Time `json:"PreparingHealthCheckStartUtcTimestamp"`
+	PreparingHealthCheckEndUtcTimestamp   time.Time `json:"PreparingHealthCheckEndUtcTimestamp"`
+	RestoringHealthCheckStartUtcTimestamp time.Time `json:"RestoringHealthCheckStartUtcTimestamp"`
+	RestoringHealthCheckEndUtcTimestamp   time.Time `json:"RestoringHealthCheckEndUtcTimestamp"`
+}
+
+// RepairTask a repair requested by a repair executor or an operator
+type RepairTask struct {
+	TaskID string `json:"TaskId"`
+	// Version the current version of the task, zero skips
+	// the version check when the task is updated
+	Version       string                   `json:"Version,omitempty"`
+	Description   string                   `json:"Description,omitempty"`
+	State         RepairTaskState          `json:"State"`
+	Flags         int                      `json:"Flags,omitempty"`
+	Action        string                   `json:"Action"`
+	Target        *RepairTargetDescription `json:"Target,omitempty"`
+	Executor      string                   `json:"Executor,omitempty"`
+	ExecutorData  string                   `json:"ExecutorData,omitempty"`
+	Impact        *RepairImpactDescription `json:"Impact,omitempty"`
+	ResultStatus  RepairTaskResult         `json:"ResultStatus,omitempty"`
+	ResultCode    int64                    `json:"ResultCode,omitempty"`
+	ResultDetails string                   `json:"ResultDetails,omitempty"`
+	History       *RepairTaskHistory       `json:"History,omitempty"`
+	// PreparingHealthCheckState and RestoringHealthCheckState are
+	// only reported by the cluster
+	PreparingHealthCheckState   string `json:"PreparingHealthCheckState,omitempty"`
+	RestoringHealthCheckState   string `json:"RestoringHealthCheckState,omitempty"`
+	PerformPreparingHealthCheck bool   `json:"PerformPreparingHealthCheck,omitempty"`
+	PerformRestoringHealthCheck bool   `json:"PerformRestoringHealthCheck,omitempty"`
+}
+
+// HasFlag reports whether flag is set on the task
+func (t *RepairTask) HasFlag(flag int) bool {
+	return t.Flags&flag != 0
+}
+
+// RepairTaskListOptions selects the tasks returned by GetRepairTaskList
+type RepairTaskListOptions struct {
+	// TaskIDPrefix only return tasks whose id starts with TaskIDPrefix
+	TaskIDPrefix string
+	// StateFilter only return tasks in the selected states
+	StateFilter RepairTaskStateFilter
+	// Executor only return tasks claimed by Executor
+	Executor string
+}
+
+func (o RepairTaskListOptions) queryParams() queryParamsFunc {
+	var params []queryParamsFunc
+	if o.TaskIDPrefix != "" {
+		params = append(params, withParam("TaskIdFilter", o.TaskIDPrefix))
+	}
+	if o.StateFilter != RepairTaskStateFilterDefault {
+		params = append(params, withParam("StateFilter", strconv.Itoa(int(o.StateFilter))))
+	}
+	if o.Executor != "" {
+		params = append(params, withParam("ExecutorFilter", o.Executor))
+	}
+	return withParams(params...)
+}
+
+// repairTaskUpdateInfo the new version of an updated repair task
+type repairTaskUpdateInfo struct {
+	Version string `json:"Version"`
+}
+
+// CreateRepairTask creates a repair task and returns its version.
+// Tasks created outside a repair executor must be in the Created state,
+// which is used when State is empty.
+func (c Client) CreateRepairTask(task RepairTask) (string, error) {
+	if task.State == "" {
+		task.State = RepairTaskStateCreated
+	}
+	return c.updateRepairTask(opCreateRepairTask, "$/CreateRepairTask", task)
+}
+
+// CancelRepairTask requests the cancellation of a repair task and
+// returns its new version. A task that is already executing is only
+// cancelled when requestAbort is set. A version of "0" skips the
+// version check.
+func (c Client) CancelRepairTask(taskID, version string, requestAbort bool) (string, error) {
+	body := struct {
+		TaskID       string `json:"TaskId"`
+		Version      string `json:"Version"`
+		RequestAbort bool   `json:"RequestAbort"`
+	}{taskID, version, requestAbort}
+	return c.updateRepairTask(opCancelRepairTask, "$/CancelRepairTask", body)
+}
+
+// ForceApproveRepairTask approves a repair task without waiting for
+// the cluster to prepare for its impact and returns its new version.
+// A version of "0" skips the version check.
+func (c Client) ForceApproveRepairTask(taskID, version string) (string, error) {
+	body := struct {
+		TaskID  string `json:"TaskId"`
+		Version string `json:"Version"`
+	}{taskID, version}
+	return c.updateRepairTask(opForceApproveRepairTask, "$/ForceApproveRepairTask", body)
+}
+
+// UpdateRepairExecutionState updates the state of a repair task on
+// behalf of its repair executor and returns its new version.
+func (c Client) UpdateRepairExecutionState(task RepairTask) (string, error) {
+	return c.updateRepairTask(opUpdateRepairExecutionState, "$/UpdateRepairExecutionState", task)
+}
+
+// DeleteRepairTask deletes a completed repair task.
+// A version of "0" skips the version check.
+func (c Client) DeleteRepairTask(taskID, version string) error {
+	body := struct {
+		TaskID  string `json:"TaskId"`
+		Version string `json:"Version"`
+	}{taskID, version}
+	_, err := c.postHTTP(opDeleteRepairTask, "$/DeleteRepairTask", body)
+	return err
+}
+
+// GetRepairTaskList returns the repair tasks selected by opts.
+func (c Client) GetRepairTaskList(opts RepairTaskListOptions) ([]RepairTask, error) {
+	res, err := c.getHTTP(opGetRepairTaskList, "$/GetRepairTaskList", opts.queryParams())
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []RepairTask
+	err = json.Unmarshal(res, &tasks)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return tasks, nil
+}
+
+func (c Client) updateRepairTask(op operation, basePath string, body interface{}) (string, error) {
+	res, err := c.postHTTP(op, basePath, body)
+	if err != nil {
+		return "", err
+	}
+
+	var updateInfo repairTaskUpdateInfo
+	err = json.Unmarshal(res, &updateInfo)
+	if err != nil {
+		return "", fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return updateInfo.Version, nil
+}
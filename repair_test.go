@@ -0,0 +1,85 @@
+package servicefabric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRepairTaskExecutor(t *testing.T) {
+	manager := &fakeRepairManager{}
+	server := httptest.NewServer(manager)
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	version, err := sfClient.CreateRepairTask(RepairTask{
+		TaskID:      "Patch/_Node_0",
+		Description: "Monthly patching",
+		Action:      "Patch",
+		Target:      NodeRepairTarget("_Node_0"),
+	})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if version != "1" {
+		t.Errorf("Got version %s, want 1", version)
+	}
+
+	created, err := sfClient.GetRepairTaskList(RepairTaskListOptions{TaskIDPrefix: "Patch/", StateFilter: RepairTaskStateFilterCreated})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(created) != 1 || created[0].State != RepairTaskStateCreated {
+		t.Fatalf("Got %+v, want the created task", created)
+	}
+	if manager.queries[1] != "api-version=6.0&TaskIdFilter=Patch%2F&StateFilter=1" {
+		t.Errorf("Got %s, want the task id and state filters", manager.queries[1])
+	}
+
+	task := created[0]
+	task.State = RepairTaskStateClaimed
+	task.Executor = "patcher"
+	task.Impact = NodeRepairImpact(NodeImpactLevelRestart, "_Node_0")
+	version, err = sfClient.UpdateRepairExecutionState(task)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if _, err := sfClient.UpdateRepairExecutionState(task); err == nil {
+		t.Error("Expected an error updating a stale task version")
+	}
+
+	version, err = sfClient.ForceApproveRepairTask(task.TaskID, version)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	claimed, err := sfClient.GetRepairTaskList(RepairTaskListOptions{Executor: "patcher", StateFilter: RepairTaskStateFilterReadyToExecute})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("Got %+v, want the approved task", claimed)
+	}
+	approved := claimed[0]
+	if approved.Version != version || !approved.HasFlag(RepairTaskFlagForcedApproval) {
+		t.Errorf("Got %+v, want a force approved task at version %s", approved, version)
+	}
+	expectedImpact := &RepairImpactDescription{Kind: RepairKindNode, NodeImpactList: []NodeImpact{{NodeName: "_Node_0", ImpactLevel: NodeImpactLevelRestart}}}
+	if !reflect.DeepEqual(approved.Impact, expectedImpact) {
+		t.Errorf("Got %+v, want %+v", approved.Impact, expectedImpact)
+	}
+
+	if _, err := sfClient.CancelRepairTask(approved.TaskID, "0", true); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	cancelled, err := sfClient.GetRepairTaskList(RepairTaskListOptions{TaskIDPrefix: approved.TaskID})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !cancelled[0].HasFlag(RepairTaskFlagCancelRequested) || !cancelled[0].HasFlag(RepairTaskFlagAbortRequested) {
+		t.Errorf("Got flags %d, want cancel and abort requested", cancelled[0].Flags)
+	}
+}
@@ -0,0 +1,169 @@
+package servicefabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ReplicaRole the role of a replica of a stateful service
+type ReplicaRole string
+
+// Replica roles as returned by the Service Fabric API
+const (
+	ReplicaRoleUnknown         ReplicaRole = "Unknown"
+	ReplicaRoleNone            ReplicaRole = "None"
+	ReplicaRolePrimary         ReplicaRole = "Primary"
+	ReplicaRoleIdleSecondary   ReplicaRole = "IdleSecondary"
+	ReplicaRoleActiveSecondary ReplicaRole = "ActiveSecondary"
+)
+
+// ReplicaStatus the status of a replica or instance
+type ReplicaStatus string
+
+// Replica statuses as returned by the Service Fabric API
+const (
+	ReplicaStatusInvalid ReplicaStatus = "Invalid"
+	ReplicaStatusInBuild ReplicaStatus = "InBuild"
+	ReplicaStatusStandby ReplicaStatus = "Standby"
+	ReplicaStatusReady   ReplicaStatus = "Ready"
+	ReplicaStatusDown    ReplicaStatus = "Down"
+	ReplicaStatusDropped ReplicaStatus = "Dropped"
+)
+
+// ErrNoReadyPrimary is returned by GetPrimary when no ready
+// primary replica was found before the context was done
+var ErrNoReadyPrimary = errors.New("no ready primary replica found")
+
+const (
+	primaryPollInitialInterval = 100 * time.Millisecond
+	primaryPollMaxInterval     = 2 * time.Second
+)
+
+// ReplicaFilter reports whether a replica should be kept
+type ReplicaFilter func(replica *ReplicaItemBase) bool
+
+// IsPrimary reports whether the replica is a ready primary
+func IsPrimary(replica *ReplicaItemBase) bool {
+	return replica.ReplicaRole == ReplicaRolePrimary && replica.ReplicaStatus == ReplicaStatusReady
+}
+
+// IsReadableSecondary reports whether the replica is a
+// ready active secondary which can serve reads
+func IsReadableSecondary(replica *ReplicaItemBase) bool {
+	return replica.ReplicaRole == ReplicaRoleActiveSecondary && replica.ReplicaStatus == ReplicaStatusReady
+}
+
+// IsReady reports whether the replica or instance is ready
+func IsReady(replica *ReplicaItemBase) bool {
+	return replica.ReplicaStatus == ReplicaStatusReady
+}
+
+// FilterReplicas returns the replicas accepted by every filter
+func FilterReplicas(replicas []ReplicaItem, filters ...ReplicaFilter) []ReplicaItem {
+	filtered := []ReplicaItem{}
+	for _, replica := range replicas {
+		if replica.ReplicaItemBase != nil && matchesAll(replica.ReplicaItemBase, filters) {
+			filtered = append(filtered, replica)
+		}
+	}
+	return filtered
+}
+
+// FilterInstances returns the instances accepted by every filter
+func FilterInstances(instances []InstanceItem, filters ...ReplicaFilter) []InstanceItem {
+	filtered := []InstanceItem{}
+	for _, instance := range instances {
+		if instance.ReplicaItemBase != nil && matchesAll(instance.ReplicaItemBase, filters) {
+			filtered = append(filtered, instance)
+		}
+	}
+	return filtered
+}
+
+func matchesAll(replica *ReplicaItemBase, filters []ReplicaFilter) bool {
+	for _, filter := range filters {
+		if !filter(replica) {
+			return false
+		}
+	}
+	return true
+}
+
+// Primary returns the ready primary replica of the page, if any
+func (p *ReplicaItemsPage) Primary() (*ReplicaItem, bool) {
+	primaries := FilterReplicas(p.Items, IsPrimary)
+	if len(primaries) == 0 {
+		return nil, false
+	}
+	return &primaries[0], true
+}
+
+// ReadableSecondaries returns the ready active secondary replicas of the page
+func (p *ReplicaItemsPage) ReadableSecondaries() []ReplicaItem {
+	return FilterReplicas(p.Items, IsReadableSecondary)
+}
+
+// Ready returns the ready replicas of the page
+func (p *ReplicaItemsPage) Ready() []ReplicaItem {
+	return FilterReplicas(p.Items, IsReady)
+}
+
+// GetPrimary returns the ready primary replica of a stateful partition.
+// While the partition is reconfiguring or its primary is still being
+// built, or the gateway fails during the failover, the replicas are
+// polled again until a ready primary is found or ctx is done, in which
+// case ErrNoReadyPrimary is returned. A partition which cannot be
+// found or read fails at once. The replicas are requested at high
+// priority, ahead of list calls waiting for the client's rate limit.
+func (c Client) GetPrimary(ctx context.Context, appName, serviceName, partitionName string) (*ReplicaItem, error) {
+	var primary *ReplicaItem
+	var lastErr error
+	err := pollUntil(ctx, primaryPollInitialInterval, primaryPollMaxInterval, func() (bool, error) {
+		replicas, err := c.WithContext(ctx).getReplicas(opGetPrimaryReplicas, appName, serviceName, partitionName, ListOptions{})
+		if err != nil {
+			// A request cancelled with ctx is reported below
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			if reconfiguring(err) {
+				lastErr = err
+				return false, nil
+			}
+			return false, err
+		}
+
+		var ok bool
+		primary, ok = replicas.Primary()
+		return ok, nil
+	})
+	if err != nil {
+		if ctx.Err() != nil && lastErr != nil {
+			return nil, fmt.Errorf("%w for partition %s: %v, last error: %v", ErrNoReadyPrimary, partitionName, ctx.Err(), lastErr)
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w for partition %s: %v", ErrNoReadyPrimary, partitionName, ctx.Err())
+		}
+		return nil, err
+	}
+	return primary, nil
+}
+
+// reconfiguring reports whether err is one the gateway returns while
+// a partition fails over, such as 503 or FABRIC_E_ error responses or
+// a connection failure, rather than a request which cannot succeed
+func reconfiguring(err error) bool {
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		switch responseErr.StatusCode {
+		case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			return false
+		}
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,132 @@
+package servicefabric
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFilterReplicas(t *testing.T) {
+	replicas := []ReplicaItem{
+		{ReplicaItemBase: &ReplicaItemBase{ReplicaRole: ReplicaRolePrimary, ReplicaStatus: ReplicaStatusInBuild}, ID: "1"},
+		{ReplicaItemBase: &ReplicaItemBase{ReplicaRole: ReplicaRolePrimary, ReplicaStatus: ReplicaStatusReady}, ID: "2"},
+		{ReplicaItemBase: &ReplicaItemBase{ReplicaRole: ReplicaRoleActiveSecondary, ReplicaStatus: ReplicaStatusReady}, ID: "3"},
+		{ReplicaItemBase: &ReplicaItemBase{ReplicaRole: ReplicaRoleIdleSecondary, ReplicaStatus: ReplicaStatusReady}, ID: "4"},
+		{ID: "5"},
+	}
+
+	testCases := []struct {
+		desc     string
+		filters  []ReplicaFilter
+		expected []string
+	}{
+		{desc: "No filters", expected: []string{"1", "2", "3", "4"}},
+		{desc: "Primary", filters: []ReplicaFilter{IsPrimary}, expected: []string{"2"}},
+		{desc: "Readable secondaries", filters: []ReplicaFilter{IsReadableSecondary}, expected: []string{"3"}},
+		{desc: "Ready", filters: []ReplicaFilter{IsReady}, expected: []string{"2", "3", "4"}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			actual := FilterReplicas(replicas, test.filters...)
+			if len(actual) != len(test.expected) {
+				t.Fatalf("Got %d replicas, want %d", len(actual), len(test.expected))
+			}
+			for i, replica := range actual {
+				if replica.ID != test.expected[i] {
+					t.Errorf("Got replica %s, want %s", replica.ID, test.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetPrimaryWaitsForReadyPrimary(t *testing.T) {
+	server := httptest.NewServer(handleReplicasInBuild(2))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	primary, err := sfClient.GetPrimary(ctx, "TestApplication", "TestApplication/TestService", "bce46a8c-b62d-4996-89dc-7ffc00a96902")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if primary.ID != "131496928082309293" || primary.ReplicaStatus != ReplicaStatusReady {
+		t.Errorf("Got %+v, want ready primary 131496928082309293", primary)
+	}
+}
+
+func TestGetPrimaryReturnsErrorAfterDeadline(t *testing.T) {
+	server := httptest.NewServer(handleReplicasInBuild(1000))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	primary, err := sfClient.GetPrimary(ctx, "TestApplication", "TestApplication/TestService", "bce46a8c-b62d-4996-89dc-7ffc00a96902")
+	if !errors.Is(err, ErrNoReadyPrimary) {
+		t.Fatalf("Got error %v, want %v", err, ErrNoReadyPrimary)
+	}
+
+	if primary != nil {
+		t.Errorf("Got %+v, want nil", primary)
+	}
+}
+
+func TestGetPrimaryRetriesDuringFailover(t *testing.T) {
+	replicas := handleReplicasInBuild(0)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			http.Error(w, `{"Error":{"Code":"FABRIC_E_SERVICE_OFFLINE","Message":"Service is offline"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		replicas.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	primary, err := sfClient.GetPrimary(ctx, "TestApplication", "TestApplication/TestService", "bce46a8c-b62d-4996-89dc-7ffc00a96902")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if primary.ID != "131496928082309293" || requests != 3 {
+		t.Errorf("Got %+v after %d requests, want ready primary 131496928082309293", primary, requests)
+	}
+}
+
+func TestGetPrimaryFailsForMissingPartition(t *testing.T) {
+	server := httptest.NewServer(handleReplicasInBuild(0))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := sfClient.GetPrimary(ctx, "TestApplication", "TestApplication/TestService", "missing")
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) || responseErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Got %v, want the not found error", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("Got a deadline exceeded, want GetPrimary to fail at once")
+	}
+}
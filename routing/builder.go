@@ -0,0 +1,230 @@
+package routing
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// Label keys read from each service, relative to the label prefix
+const (
+	LabelEnable                 = "enable"
+	LabelFrontendRule           = "frontend.rule"
+	LabelFrontendPriority       = "frontend.priority"
+	LabelFrontendPassHostHeader = "frontend.passHostHeader"
+	LabelFrontendEntryPoints    = "frontend.entryPoints"
+	LabelBackendWeight          = "backend.weight"
+	LabelBackendEndpoint        = "backend.endpoint"
+	LabelBackendReplicas        = "backend.replicas"
+	LabelLoadBalancerMethod     = "backend.loadbalancer.method"
+	LabelStickiness             = "backend.loadbalancer.stickiness"
+	LabelStickinessCookieName   = "backend.loadbalancer.stickiness.cookieName"
+	LabelHealthCheckPath        = "backend.healthcheck.path"
+	LabelHealthCheckInterval    = "backend.healthcheck.interval"
+	LabelHealthCheckPort        = "backend.healthcheck.port"
+)
+
+const defaultWeight = 1
+
+// Options controls how routes are built
+type Options struct {
+	// ExposedByDefault routes services which do not set the enable label
+	ExposedByDefault bool
+	// DefaultReplicaMode the replicas of stateful services to route to
+	// when the service does not set the backend.replicas label,
+	// defaults to ReplicaModePrimaryOnly as does an unknown mode
+	DefaultReplicaMode ReplicaMode
+}
+
+// Build returns the routes for services. Labels with values that
+// cannot be parsed fall back to their defaults. The result is sorted
+// so that building the same topology twice gives identical output.
+func Build(services []Service, opts Options) *Configuration {
+	config := &Configuration{
+		Frontends: []Frontend{},
+		Backends:  []Backend{},
+	}
+
+	for _, service := range services {
+		if !isEnabled(service, opts) {
+			continue
+		}
+
+		backend := buildBackend(service, opts)
+		config.Backends = append(config.Backends, backend)
+
+		if rule := service.Labels[LabelFrontendRule]; rule != "" {
+			config.Frontends = append(config.Frontends, Frontend{
+				Name:           "frontend-" + service.Name,
+				Backend:        backend.Name,
+				Rule:           rule,
+				Priority:       getInt(service.Labels, LabelFrontendPriority, 0),
+				PassHostHeader: getBool(service.Labels, LabelFrontendPassHostHeader, true),
+				EntryPoints:    getList(service.Labels, LabelFrontendEntryPoints),
+			})
+		}
+	}
+
+	sort.Slice(config.Frontends, func(i, j int) bool {
+		return config.Frontends[i].Name < config.Frontends[j].Name
+	})
+	sort.Slice(config.Backends, func(i, j int) bool {
+		return config.Backends[i].Name < config.Backends[j].Name
+	})
+	return config
+}
+
+func isEnabled(service Service, opts Options) bool {
+	if service.ServiceStatus != "" && service.ServiceStatus != "Active" {
+		return false
+	}
+	return getBool(service.Labels, LabelEnable, opts.ExposedByDefault)
+}
+
+func buildBackend(service Service, opts Options) Backend {
+	backend := Backend{
+		Name:               service.Name,
+		Servers:            []Server{},
+		LoadBalancerMethod: service.Labels[LabelLoadBalancerMethod],
+		ApplicationName:    service.Application.Name,
+		ServiceName:        service.Name,
+		ServiceKind:        service.ServiceKind,
+	}
+
+	if path := service.Labels[LabelHealthCheckPath]; path != "" {
+		backend.HealthCheck = &HealthCheck{
+			Path:     path,
+			Interval: service.Labels[LabelHealthCheckInterval],
+			Port:     getInt(service.Labels, LabelHealthCheckPort, 0),
+		}
+	}
+
+	if getBool(service.Labels, LabelStickiness, false) {
+		backend.Sticky = &StickySession{CookieName: service.Labels[LabelStickinessCookieName]}
+	}
+
+	if service.ServiceKind == sf.ServiceKindStateful {
+		backend.StatefulReplicaMode = replicaMode(service, opts)
+	}
+
+	weight := getInt(service.Labels, LabelBackendWeight, defaultWeight)
+	endpointName, hasEndpointName := service.Labels[LabelBackendEndpoint]
+
+	for _, partition := range service.Partitions {
+		for _, replica := range partition.Replicas {
+			if !isRoutable(replica, backend.StatefulReplicaMode) {
+				continue
+			}
+
+			serverURL, ok := selectEndpoint(replica, endpointName, hasEndpointName)
+			if !ok {
+				continue
+			}
+
+			backend.Servers = append(backend.Servers, Server{
+				URL:         serverURL,
+				Weight:      weight,
+				NodeName:    replica.NodeName,
+				PartitionID: partition.PartitionInformation.ID,
+				ReplicaID:   replica.ID,
+				ReplicaRole: string(replica.ReplicaRole),
+			})
+		}
+	}
+
+	sort.Slice(backend.Servers, func(i, j int) bool {
+		if backend.Servers[i].URL != backend.Servers[j].URL {
+			return backend.Servers[i].URL < backend.Servers[j].URL
+		}
+		return backend.Servers[i].ReplicaID < backend.Servers[j].ReplicaID
+	})
+	return backend
+}
+
+func replicaMode(service Service, opts Options) ReplicaMode {
+	switch ReplicaMode(service.Labels[LabelBackendReplicas]) {
+	case ReplicaModePrimaryOnly:
+		return ReplicaModePrimaryOnly
+	case ReplicaModeSecondaryAllowed:
+		return ReplicaModeSecondaryAllowed
+	}
+	if opts.DefaultReplicaMode == ReplicaModeSecondaryAllowed {
+		return ReplicaModeSecondaryAllowed
+	}
+	return ReplicaModePrimaryOnly
+}
+
+// isRoutable reports whether a replica should receive traffic,
+// mode is empty for stateless services
+func isRoutable(replica Replica, mode ReplicaMode) bool {
+	if !sf.IsReady(&replica.ReplicaItemBase) {
+		return false
+	}
+
+	switch mode {
+	case "":
+		return true
+	case ReplicaModeSecondaryAllowed:
+		return sf.IsPrimary(&replica.ReplicaItemBase) || sf.IsReadableSecondary(&replica.ReplicaItemBase)
+	}
+	return sf.IsPrimary(&replica.ReplicaItemBase)
+}
+
+// selectEndpoint returns the named endpoint of the replica, or the first
+// endpoint by name when no name is given. Only http and https endpoints
+// can be routed to.
+func selectEndpoint(replica Replica, name string, hasName bool) (string, bool) {
+	endpoints, err := replica.GetEndpoints()
+	if err != nil || len(endpoints) == 0 {
+		return "", false
+	}
+
+	if !hasName {
+		names := make([]string, 0, len(endpoints))
+		for n := range endpoints {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		name = names[0]
+	}
+
+	endpoint, ok := endpoints[name]
+	if !ok {
+		return "", false
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return endpoint, true
+}
+
+func getBool(labels map[string]string, key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(labels[key])
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getInt(labels map[string]string, key string, defaultValue int) int {
+	value, err := strconv.Atoi(labels[key])
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getList(labels map[string]string, key string) []string {
+	var list []string
+	for _, item := range strings.Split(labels[key], ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,200 @@
+package routing
+
+import (
+	"reflect"
+	"testing"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+func replica(id string, role sf.ReplicaRole, status sf.ReplicaStatus, address string) Replica {
+	return Replica{
+		ID: id,
+		ReplicaItemBase: sf.ReplicaItemBase{
+			Address:       address,
+			NodeName:      "_Node_" + id,
+			ReplicaRole:   role,
+			ReplicaStatus: status,
+		},
+	}
+}
+
+func statefulService(labels map[string]string) Service {
+	return Service{
+		ServiceItem: sf.ServiceItem{
+			Name:          "fabric:/TestApplication/Stateful",
+			ServiceKind:   "Stateful",
+			ServiceStatus: "Active",
+		},
+		Application: sf.ApplicationItem{Name: "fabric:/TestApplication"},
+		Labels:      labels,
+		Partitions: []Partition{
+			{
+				PartitionItem: sf.PartitionItem{PartitionInformation: sf.PartitionInformation{ID: "p1"}},
+				Replicas: []Replica{
+					replica("1", "Primary", "Ready", `{"Endpoints":{"":"http://10.0.0.1:8080"}}`),
+					replica("2", "ActiveSecondary", "Ready", `{"Endpoints":{"":"http://10.0.0.2:8080"}}`),
+					replica("3", "IdleSecondary", "InBuild", `{"Endpoints":{"":"http://10.0.0.3:8080"}}`),
+				},
+			},
+		},
+	}
+}
+
+func TestBuildStateless(t *testing.T) {
+	services := []Service{
+		{
+			ServiceItem: sf.ServiceItem{
+				Name:          "fabric:/TestApplication/Web",
+				ServiceKind:   "Stateless",
+				ServiceStatus: "Active",
+			},
+			Application: sf.ApplicationItem{Name: "fabric:/TestApplication"},
+			Labels: map[string]string{
+				LabelEnable:               "true",
+				LabelFrontendRule:         "PathPrefix:/web",
+				LabelFrontendEntryPoints:  "http, https",
+				LabelBackendWeight:        "10",
+				LabelBackendEndpoint:      "web",
+				LabelHealthCheckPath:      "/health",
+				LabelStickiness:           "true",
+				LabelStickinessCookieName: "affinity",
+			},
+			Partitions: []Partition{
+				{
+					PartitionItem: sf.PartitionItem{PartitionInformation: sf.PartitionInformation{ID: "p1"}},
+					Replicas: []Replica{
+						replica("2", "", "Ready", `{"Endpoints":{"web":"http://10.0.0.2:80","rpc":"tcp://10.0.0.2:81"}}`),
+						replica("1", "", "Ready", `{"Endpoints":{"web":"http://10.0.0.1:80"}}`),
+						replica("3", "", "Ready", `{"Endpoints":{"rpc":"tcp://10.0.0.3:81"}}`),
+					},
+				},
+			},
+		},
+		{
+			ServiceItem: sf.ServiceItem{Name: "fabric:/TestApplication/Hidden", ServiceKind: "Stateless"},
+		},
+	}
+
+	expected := &Configuration{
+		Frontends: []Frontend{
+			{
+				Name:           "frontend-fabric:/TestApplication/Web",
+				Backend:        "fabric:/TestApplication/Web",
+				Rule:           "PathPrefix:/web",
+				PassHostHeader: true,
+				EntryPoints:    []string{"http", "https"},
+			},
+		},
+		Backends: []Backend{
+			{
+				Name: "fabric:/TestApplication/Web",
+				Servers: []Server{
+					{URL: "http://10.0.0.1:80", Weight: 10, NodeName: "_Node_1", PartitionID: "p1", ReplicaID: "1"},
+					{URL: "http://10.0.0.2:80", Weight: 10, NodeName: "_Node_2", PartitionID: "p1", ReplicaID: "2"},
+				},
+				HealthCheck:     &HealthCheck{Path: "/health"},
+				Sticky:          &StickySession{CookieName: "affinity"},
+				ApplicationName: "fabric:/TestApplication",
+				ServiceName:     "fabric:/TestApplication/Web",
+				ServiceKind:     "Stateless",
+			},
+		},
+	}
+
+	actual := Build(services, Options{})
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Got %+v, want %+v", actual, expected)
+	}
+}
+
+func TestBuildStatefulReplicaMode(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		labels   map[string]string
+		opts     Options
+		expected []string
+	}{
+		{
+			desc:     "Primary only by default",
+			opts:     Options{ExposedByDefault: true},
+			expected: []string{"http://10.0.0.1:8080"},
+		},
+		{
+			desc:     "Secondary allowed by option",
+			opts:     Options{ExposedByDefault: true, DefaultReplicaMode: ReplicaModeSecondaryAllowed},
+			expected: []string{"http://10.0.0.1:8080", "http://10.0.0.2:8080"},
+		},
+		{
+			desc:     "Unknown option is primary only",
+			opts:     Options{ExposedByDefault: true, DefaultReplicaMode: "secondaries"},
+			expected: []string{"http://10.0.0.1:8080"},
+		},
+		{
+			desc:     "Label overrides option",
+			labels:   map[string]string{LabelBackendReplicas: "primary"},
+			opts:     Options{ExposedByDefault: true, DefaultReplicaMode: ReplicaModeSecondaryAllowed},
+			expected: []string{"http://10.0.0.1:8080"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := Build([]Service{statefulService(test.labels)}, test.opts)
+			if len(config.Backends) != 1 {
+				t.Fatalf("Got %d backends, want 1", len(config.Backends))
+			}
+
+			var actual []string
+			for _, server := range config.Backends[0].Servers {
+				actual = append(actual, server.URL)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Got %v, want %v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestBuildIsStable(t *testing.T) {
+	a := statefulService(map[string]string{LabelFrontendRule: "Host:a"})
+	a.Name = "fabric:/A/Service"
+	b := statefulService(map[string]string{LabelFrontendRule: "Host:b"})
+	b.Name = "fabric:/B/Service"
+
+	first := Build([]Service{b, a}, Options{ExposedByDefault: true})
+	second := Build([]Service{a, b}, Options{ExposedByDefault: true})
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Got %+v and %+v, want identical configurations", first, second)
+	}
+	if first.Backends[0].Name != "fabric:/A/Service" {
+		t.Errorf("Got first backend %s, want fabric:/A/Service", first.Backends[0].Name)
+	}
+}
+
+func TestNarrowToMember(t *testing.T) {
+	partitions := []Partition{
+		{
+			PartitionItem: sf.PartitionItem{PartitionInformation: sf.PartitionInformation{ID: "p1"}},
+			Replicas: []Replica{
+				replica("1", "", "Ready", `#Frontend%%{"Endpoints":{"":"http://10.0.0.1:80"}}#Backend%%{"Endpoints":{"":"http://10.0.0.1:81"}}`),
+			},
+		},
+	}
+
+	actual, err := narrowToMember(partitions, "Backend")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := `{"Endpoints":{"":"http://10.0.0.1:81"}}`
+	if actual[0].Replicas[0].Address != expected {
+		t.Errorf("Got %s, want %s", actual[0].Replicas[0].Address, expected)
+	}
+	if partitions[0].Replicas[0].Address == expected {
+		t.Error("Original partitions should not have been modified")
+	}
+}
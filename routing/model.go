@@ -0,0 +1,67 @@
+// Package routing turns Service Fabric topology and service
+// labels into a reverse-proxy neutral set of routes
+package routing
+
+// Configuration the complete set of routes for a cluster,
+// frontends and backends are sorted by name
+type Configuration struct {
+	Frontends []Frontend `json:"frontends"`
+	Backends  []Backend  `json:"backends"`
+}
+
+// Frontend matches incoming requests and forwards them to a backend
+type Frontend struct {
+	Name           string   `json:"name"`
+	Backend        string   `json:"backend"`
+	Rule           string   `json:"rule"`
+	Priority       int      `json:"priority,omitempty"`
+	PassHostHeader bool     `json:"passHostHeader"`
+	EntryPoints    []string `json:"entryPoints,omitempty"`
+}
+
+// Backend a set of servers requests are balanced across,
+// servers are sorted by URL
+type Backend struct {
+	Name                string         `json:"name"`
+	Servers             []Server       `json:"servers"`
+	LoadBalancerMethod  string         `json:"loadBalancerMethod,omitempty"`
+	HealthCheck         *HealthCheck   `json:"healthCheck,omitempty"`
+	Sticky              *StickySession `json:"sticky,omitempty"`
+	ApplicationName     string         `json:"applicationName"`
+	ServiceName         string         `json:"serviceName"`
+	ServiceKind         string         `json:"serviceKind"`
+	StatefulReplicaMode ReplicaMode    `json:"statefulReplicaMode,omitempty"`
+}
+
+// Server a single replica or instance endpoint
+type Server struct {
+	URL         string `json:"url"`
+	Weight      int    `json:"weight"`
+	NodeName    string `json:"nodeName"`
+	PartitionID string `json:"partitionId"`
+	ReplicaID   string `json:"replicaId"`
+	ReplicaRole string `json:"replicaRole,omitempty"`
+}
+
+// HealthCheck the health check a proxy should run against each server
+type HealthCheck struct {
+	Path     string `json:"path"`
+	Interval string `json:"interval,omitempty"`
+	Port     int    `json:"port,omitempty"`
+}
+
+// StickySession enables cookie based session affinity
+type StickySession struct {
+	CookieName string `json:"cookieName,omitempty"`
+}
+
+// ReplicaMode selects which replicas of a stateful service receive traffic
+type ReplicaMode string
+
+// Replica modes for stateful services
+const (
+	// ReplicaModePrimaryOnly only route to primary replicas
+	ReplicaModePrimaryOnly ReplicaMode = "primary"
+	// ReplicaModeSecondaryAllowed route to primary and active secondary replicas
+	ReplicaModeSecondaryAllowed ReplicaMode = "secondary-allowed"
+)
@@ -0,0 +1,164 @@
+package routing
+
+import (
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// Service a service along with its labels and
+// the partitions and replicas backing it
+type Service struct {
+	sf.ServiceItem
+	Application sf.ApplicationItem
+	Labels      map[string]string
+	Partitions  []Partition
+}
+
+// Partition a partition and its replicas or instances
+type Partition struct {
+	sf.PartitionItem
+	Replicas []Replica
+}
+
+// Replica a replica or instance of a partition
+type Replica struct {
+	sf.ReplicaItemBase
+	ID string
+}
+
+// Discover walks every application, service, partition and replica in
+// the cluster and resolves each service's labels using prefix.
+// Each member of a service group is returned as its own service.
+func Discover(client *sf.Client, prefix string) ([]Service, error) {
+	apps, err := client.GetApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []Service
+	for _, app := range apps.Items {
+		app := app
+		serviceItems, err := client.GetServices(app.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		resolver := sf.NewLabelResolver(client, prefix)
+		for _, item := range serviceItems.Items {
+			item := item
+			partitions, err := discoverPartitions(client, app.ID, item)
+			if err != nil {
+				return nil, err
+			}
+
+			if item.IsServiceGroup {
+				members, err := discoverServiceGroupMembers(client, resolver, app, item, partitions)
+				if err != nil {
+					return nil, err
+				}
+				services = append(services, members...)
+				continue
+			}
+
+			labels, err := resolver.Resolve(&item, &app)
+			if err != nil {
+				return nil, err
+			}
+
+			services = append(services, Service{
+				ServiceItem: item,
+				Application: app,
+				Labels:      labels.Map(),
+				Partitions:  partitions,
+			})
+		}
+	}
+	return services, nil
+}
+
+// discoverServiceGroupMembers returns a service for each member of a
+// service group, with each replica's address narrowed to the member's
+func discoverServiceGroupMembers(client *sf.Client, resolver *sf.LabelResolver, app sf.ApplicationItem, group sf.ServiceItem, partitions []Partition) ([]Service, error) {
+	description, err := client.GetServiceGroupDescription(sf.ServiceID(group.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]Service, 0, len(description.ServiceGroupMemberDescription))
+	for _, member := range description.ServiceGroupMemberDescription {
+		memberName := member.MemberName()
+		labels, err := resolver.ResolveMember(&group, &app, memberName)
+		if err != nil {
+			return nil, err
+		}
+
+		memberItem := group
+		memberItem.Name = member.ServiceName
+		memberItem.TypeName = member.ServiceTypeName
+
+		memberPartitions, err := narrowToMember(partitions, memberName)
+		if err != nil {
+			return nil, err
+		}
+
+		services = append(services, Service{
+			ServiceItem: memberItem,
+			Application: app,
+			Labels:      labels.Map(),
+			Partitions:  memberPartitions,
+		})
+	}
+	return services, nil
+}
+
+func narrowToMember(partitions []Partition, memberName string) ([]Partition, error) {
+	narrowed := make([]Partition, 0, len(partitions))
+	for _, partition := range partitions {
+		memberPartition := Partition{PartitionItem: partition.PartitionItem}
+		for _, replica := range partition.Replicas {
+			if !sf.IsServiceGroupAddress(replica.Address) {
+				continue
+			}
+			addresses, err := sf.ParseServiceGroupAddress(replica.Address)
+			if err != nil {
+				return nil, err
+			}
+			replica.Address = addresses[memberName]
+			memberPartition.Replicas = append(memberPartition.Replicas, replica)
+		}
+		narrowed = append(narrowed, memberPartition)
+	}
+	return narrowed, nil
+}
+
+func discoverPartitions(client *sf.Client, appID string, service sf.ServiceItem) ([]Partition, error) {
+	partitionItems, err := client.GetPartitions(appID, service.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]Partition, 0, len(partitionItems.Items))
+	for _, item := range partitionItems.Items {
+		partition := Partition{PartitionItem: item}
+		partitionID := item.PartitionInformation.ID
+
+		members, err := client.GetPartitionMembers(appID, service.ID, partitionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			id, base := member.GetReplicaData()
+			partition.Replicas = append(partition.Replicas, newReplica(id, base))
+		}
+
+		partitions = append(partitions, partition)
+	}
+	return partitions, nil
+}
+
+func newReplica(id string, base *sf.ReplicaItemBase) Replica {
+	replica := Replica{ID: id}
+	if base != nil {
+		replica.ReplicaItemBase = *base
+	}
+	return replica
+}
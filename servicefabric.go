@@ -2,14 +2,20 @@
 package servicefabric
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 )
 
 // DefaultAPIVersion is a default Service Fabric REST API version
@@ -24,6 +30,19 @@ type Client struct {
 	apiVersion string
 	// httpClient HTTP client
 	httpClient *http.Client
+	// ctx context applied to every request made by the client
+	ctx context.Context
+	// clusterAPIVersion newest API version supported by the
+	// cluster, empty until negotiated by NegotiateAPIVersion
+	clusterAPIVersion string
+	// observers notified of every request made by the client
+	observers []Observer
+	// logger receives debug records of the client's requests, nil to not log
+	logger *slog.Logger
+	// unredactedLogs logs sensitive values rather than redacting them
+	unredactedLogs bool
+	// limiter limits the requests sent, shared by copies of the client, nil to not limit them
+	limiter *limiter
 }
 
 // NewClient returns a new provider client that can query the
@@ -49,13 +68,34 @@ func NewClient(httpClient *http.Client, endpoint, apiVersion string, tlsConfig *
 	}, nil
 }
 
+// WithContext returns a copy of the client whose requests are
+// bound to ctx. When ctx has a deadline, each request also asks
+// the cluster to time out at the same deadline.
+func (c Client) WithContext(ctx context.Context) *Client {
+	c.ctx = ctx
+	return &c
+}
+
+func (c Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 // GetApplications returns all the registered applications
 // within the Service Fabric cluster.
 func (c Client) GetApplications() (*ApplicationItemsPage, error) {
+	return c.GetApplicationsWithOptions(ApplicationsOptions{})
+}
+
+// GetApplicationsWithOptions returns the registered applications
+// within the Service Fabric cluster selected by opts.
+func (c Client) GetApplicationsWithOptions(opts ApplicationsOptions) (*ApplicationItemsPage, error) {
 	var aggregateAppItemsPages ApplicationItemsPage
 	var continueToken string
 	for {
-		res, err := c.getHTTP("Applications/", withContinue(continueToken))
+		res, err := c.getHTTP(opGetApplications, "Applications/", withContinue(continueToken), opts.queryParams())
 		if err != nil {
 			return nil, err
 		}
@@ -66,9 +106,14 @@ func (c Client) GetApplications() (*ApplicationItemsPage, error) {
 			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
 		}
 
-		aggregateAppItemsPages.Items = append(aggregateAppItemsPages.Items, appItemsPage.Items...)
+		for _, app := range appItemsPage.Items {
+			if opts.HealthStateFilter.Matches(app.HealthState) {
+				aggregateAppItemsPages.Items = append(aggregateAppItemsPages.Items, app)
+			}
+		}
 
 		continueToken = getString(appItemsPage.ContinuationToken)
+		c.logPage(opGetApplications, len(appItemsPage.Items), continueToken)
 		if continueToken == "" {
 			break
 		}
@@ -79,10 +124,16 @@ func (c Client) GetApplications() (*ApplicationItemsPage, error) {
 // GetServices returns all the services associated
 // with a Service Fabric application.
 func (c Client) GetServices(appName string) (*ServiceItemsPage, error) {
+	return c.GetServicesWithOptions(appName, ServicesOptions{})
+}
+
+// GetServicesWithOptions returns the services associated
+// with a Service Fabric application selected by opts.
+func (c Client) GetServicesWithOptions(appName string, opts ServicesOptions) (*ServiceItemsPage, error) {
 	var aggregateServiceItemsPages ServiceItemsPage
 	var continueToken string
 	for {
-		res, err := c.getHTTP("Applications/"+appName+"/$/GetServices", withContinue(continueToken))
+		res, err := c.getHTTP(opGetServices, "Applications/"+escapePath(appName)+"/$/GetServices", withContinue(continueToken), opts.queryParams())
 		if err != nil {
 			return nil, err
 		}
@@ -93,9 +144,14 @@ func (c Client) GetServices(appName string) (*ServiceItemsPage, error) {
 			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
 		}
 
-		aggregateServiceItemsPages.Items = append(aggregateServiceItemsPages.Items, servicesItemsPage.Items...)
+		for _, service := range servicesItemsPage.Items {
+			if opts.HealthStateFilter.Matches(service.HealthState) {
+				aggregateServiceItemsPages.Items = append(aggregateServiceItemsPages.Items, service)
+			}
+		}
 
 		continueToken = getString(servicesItemsPage.ContinuationToken)
+		c.logPage(opGetServices, len(servicesItemsPage.Items), continueToken)
 		if continueToken == "" {
 			break
 		}
@@ -106,11 +162,67 @@ func (c Client) GetServices(appName string) (*ServiceItemsPage, error) {
 // GetPartitions returns all the partitions associated
 // with a Service Fabric service.
 func (c Client) GetPartitions(appName, serviceName string) (*PartitionItemsPage, error) {
+	return c.GetPartitionsWithOptions(appName, serviceName, ListOptions{})
+}
+
+// GetPartitionsWithOptions returns the partitions associated
+// with a Service Fabric service selected by opts.
+func (c Client) GetPartitionsWithOptions(appName, serviceName string, opts ListOptions) (*PartitionItemsPage, error) {
+	return c.getPartitions(opGetPartitions, "Applications/"+escapePath(appName)+"/$/GetServices/"+escapePath(serviceName)+"/$/GetPartitions/", opts)
+}
+
+// GetServicePartitions returns all the partitions associated
+// with a Service Fabric service without knowing its application.
+func (c Client) GetServicePartitions(serviceID ServiceID) (*PartitionItemsPage, error) {
+	return c.getPartitions(opGetServicePartitions, "Services/"+escapeSegment(serviceID.String())+"/$/GetPartitions", ListOptions{})
+}
+
+// GetPartition returns the partition identified by partitionID.
+func (c Client) GetPartition(partitionID PartitionID) (*PartitionItem, error) {
+	res, err := c.getHTTP(opGetPartition, "Partitions/"+escapeSegment(partitionID.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	var partition PartitionItem
+	err = json.Unmarshal(res, &partition)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &partition, nil
+}
+
+// GetPartitionServiceName returns the name and id of the
+// service the partition identified by partitionID belongs to.
+func (c Client) GetPartitionServiceName(partitionID PartitionID) (*NameInfo, error) {
+	return c.getNameInfo(opGetPartitionServiceName, "Partitions/"+escapeSegment(partitionID.String())+"/$/GetServiceName")
+}
+
+// GetServiceApplicationName returns the name and id of the
+// application the service identified by serviceID belongs to.
+func (c Client) GetServiceApplicationName(serviceID ServiceID) (*NameInfo, error) {
+	return c.getNameInfo(opGetServiceApplicationName, "Services/"+escapeSegment(serviceID.String())+"/$/GetApplicationName")
+}
+
+func (c Client) getNameInfo(op operation, basePath string) (*NameInfo, error) {
+	res, err := c.getHTTP(op, basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameInfo NameInfo
+	err = json.Unmarshal(res, &nameInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &nameInfo, nil
+}
+
+func (c Client) getPartitions(op operation, basePath string, opts ListOptions) (*PartitionItemsPage, error) {
 	var aggregatePartitionItemsPages PartitionItemsPage
 	var continueToken string
 	for {
-		basePath := "Applications/" + appName + "/$/GetServices/" + serviceName + "/$/GetPartitions/"
-		res, err := c.getHTTP(basePath, withContinue(continueToken))
+		res, err := c.getHTTP(op, basePath, withContinue(continueToken), opts.queryParams())
 		if err != nil {
 			return nil, err
 		}
@@ -121,9 +233,14 @@ func (c Client) GetPartitions(appName, serviceName string) (*PartitionItemsPage,
 			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
 		}
 
-		aggregatePartitionItemsPages.Items = append(aggregatePartitionItemsPages.Items, partitionsItemsPage.Items...)
+		for _, partition := range partitionsItemsPage.Items {
+			if opts.HealthStateFilter.Matches(partition.HealthState) {
+				aggregatePartitionItemsPages.Items = append(aggregatePartitionItemsPages.Items, partition)
+			}
+		}
 
 		continueToken = getString(partitionsItemsPage.ContinuationToken)
+		c.logPage(op, len(partitionsItemsPage.Items), continueToken)
 		if continueToken == "" {
 			break
 		}
@@ -134,11 +251,16 @@ func (c Client) GetPartitions(appName, serviceName string) (*PartitionItemsPage,
 // GetInstances returns all the instances associated
 // with a stateless Service Fabric partition.
 func (c Client) GetInstances(appName, serviceName, partitionName string) (*InstanceItemsPage, error) {
+	return c.GetInstancesWithOptions(appName, serviceName, partitionName, ListOptions{})
+}
+
+// GetInstancesWithOptions returns the instances associated
+// with a stateless Service Fabric partition selected by opts.
+func (c Client) GetInstancesWithOptions(appName, serviceName, partitionName string, opts ListOptions) (*InstanceItemsPage, error) {
 	var aggregateInstanceItemsPages InstanceItemsPage
 	var continueToken string
 	for {
-		basePath := "Applications/" + appName + "/$/GetServices/" + serviceName + "/$/GetPartitions/" + partitionName + "/$/GetReplicas"
-		res, err := c.getHTTP(basePath, withContinue(continueToken))
+		res, err := c.getHTTP(opGetReplicas, replicasPath(appName, serviceName, partitionName), withContinue(continueToken), opts.queryParams())
 		if err != nil {
 			return nil, err
 		}
@@ -149,9 +271,14 @@ func (c Client) GetInstances(appName, serviceName, partitionName string) (*Insta
 			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
 		}
 
-		aggregateInstanceItemsPages.Items = append(aggregateInstanceItemsPages.Items, instanceItemsPage.Items...)
+		for _, instance := range instanceItemsPage.Items {
+			if instance.ReplicaItemBase == nil || opts.HealthStateFilter.Matches(instance.HealthState) {
+				aggregateInstanceItemsPages.Items = append(aggregateInstanceItemsPages.Items, instance)
+			}
+		}
 
 		continueToken = getString(instanceItemsPage.ContinuationToken)
+		c.logPage(opGetReplicas, len(instanceItemsPage.Items), continueToken)
 		if continueToken == "" {
 			break
 		}
@@ -162,11 +289,21 @@ func (c Client) GetInstances(appName, serviceName, partitionName string) (*Insta
 // GetReplicas returns all the replicas associated
 // with a stateful Service Fabric partition.
 func (c Client) GetReplicas(appName, serviceName, partitionName string) (*ReplicaItemsPage, error) {
+	return c.GetReplicasWithOptions(appName, serviceName, partitionName, ListOptions{})
+}
+
+// GetReplicasWithOptions returns the replicas associated
+// with a stateful Service Fabric partition selected by opts.
+func (c Client) GetReplicasWithOptions(appName, serviceName, partitionName string, opts ListOptions) (*ReplicaItemsPage, error) {
+	return c.getReplicas(opGetReplicas, appName, serviceName, partitionName, opts)
+}
+
+// getReplicas returns the replicas of a partition, requested as op
+func (c Client) getReplicas(op operation, appName, serviceName, partitionName string, opts ListOptions) (*ReplicaItemsPage, error) {
 	var aggregateReplicaItemsPages ReplicaItemsPage
 	var continueToken string
 	for {
-		basePath := "Applications/" + appName + "/$/GetServices/" + serviceName + "/$/GetPartitions/" + partitionName + "/$/GetReplicas"
-		res, err := c.getHTTP(basePath, withContinue(continueToken))
+		res, err := c.getHTTP(op, replicasPath(appName, serviceName, partitionName), withContinue(continueToken), opts.queryParams())
 		if err != nil {
 			return nil, err
 		}
@@ -177,9 +314,14 @@ func (c Client) GetReplicas(appName, serviceName, partitionName string) (*Replic
 			return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
 		}
 
-		aggregateReplicaItemsPages.Items = append(aggregateReplicaItemsPages.Items, replicasItemsPage.Items...)
+		for _, replica := range replicasItemsPage.Items {
+			if replica.ReplicaItemBase == nil || opts.HealthStateFilter.Matches(replica.HealthState) {
+				aggregateReplicaItemsPages.Items = append(aggregateReplicaItemsPages.Items, replica)
+			}
+		}
 
 		continueToken = getString(replicasItemsPage.ContinuationToken)
+		c.logPage(op, len(replicasItemsPage.Items), continueToken)
 		if continueToken == "" {
 			break
 		}
@@ -187,20 +329,30 @@ func (c Client) GetReplicas(appName, serviceName, partitionName string) (*Replic
 	return &aggregateReplicaItemsPages, nil
 }
 
-// GetServiceExtension returns all the extensions specified
-// in a Service's manifest file. If the XML schema does not
-// map to the provided interface, the default type interface will
-// be returned.
-func (c Client) GetServiceExtension(appType, applicationVersion, serviceTypeName, extensionKey string, response interface{}) error {
-	res, err := c.getHTTP("ApplicationTypes/"+appType+"/$/GetServiceTypes", withParam("ApplicationTypeVersion", applicationVersion))
+// GetServiceTypes returns the service types declared
+// by a version of a Service Fabric application type.
+func (c Client) GetServiceTypes(appType, applicationVersion string) ([]ServiceType, error) {
+	res, err := c.getHTTP(opGetServiceTypes, "ApplicationTypes/"+escapeSegment(appType)+"/$/GetServiceTypes", withParam("ApplicationTypeVersion", applicationVersion))
 	if err != nil {
-		return fmt.Errorf("error requesting service extensions: %v", err)
+		return nil, err
 	}
 
 	var serviceTypes []ServiceType
 	err = json.Unmarshal(res, &serviceTypes)
 	if err != nil {
-		return fmt.Errorf("could not deserialise JSON response: %+v", err)
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return serviceTypes, nil
+}
+
+// GetServiceExtension returns all the extensions specified
+// in a Service's manifest file. If the XML schema does not
+// map to the provided interface, the default type interface will
+// be returned.
+func (c Client) GetServiceExtension(appType, applicationVersion, serviceTypeName, extensionKey string, response interface{}) error {
+	serviceTypes, err := c.GetServiceTypes(appType, applicationVersion)
+	if err != nil {
+		return fmt.Errorf("error requesting service extensions: %w", err)
 	}
 
 	for _, serviceTypeInfo := range serviceTypes {
@@ -219,6 +371,33 @@ func (c Client) GetServiceExtension(appType, applicationVersion, serviceTypeName
 	return nil
 }
 
+// GetServiceManifest returns the service manifest of a
+// service manifest name within an application type version.
+// The raw XML is kept in ManifestXMLData.
+func (c Client) GetServiceManifest(appType, applicationVersion, serviceManifestName string) (*ServiceManifest, error) {
+	res, err := c.getHTTP(opGetServiceManifest, "ApplicationTypes/"+escapeSegment(appType)+"/$/GetServiceManifest",
+		withParam("ApplicationTypeVersion", applicationVersion),
+		withParam("ServiceManifestName", serviceManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("error requesting service manifest: %w", err)
+	}
+
+	var manifestResponse ServiceManifestResponse
+	err = json.Unmarshal(res, &manifestResponse)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+
+	var manifest ServiceManifest
+	err = xml.Unmarshal([]byte(manifestResponse.Manifest), &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise service manifest XML: %+v", err)
+	}
+	manifest.ManifestXMLData = manifestResponse.Manifest
+
+	return &manifest, nil
+}
+
 // GetServiceExtensionMap returns all the extension xml specified
 // in a Service's manifest file into (which must conform to ServiceExtensionLabels)
 // a map[string]string
@@ -257,7 +436,7 @@ func (c Client) GetProperties(name string) (bool, map[string]string, error) {
 
 	var continueToken string
 	for {
-		res, err := c.getHTTP("Names/"+name+"/$/GetProperties", withContinue(continueToken), withParam("IncludeValues", "true"))
+		res, err := c.getHTTP(opGetProperties, "Names/"+escapePath(name)+"/$/GetProperties", withContinue(continueToken), withParam("IncludeValues", "true"))
 		if err != nil {
 			return false, nil, err
 		}
@@ -268,14 +447,20 @@ func (c Client) GetProperties(name string) (bool, map[string]string, error) {
 			return false, nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
 		}
 
+		var logged []interface{}
+		logging := c.debugEnabled()
 		for _, property := range propertiesListPage.Properties {
 			if property.Value.Kind != "String" {
 				continue
 			}
 			properties[property.Name] = property.Value.Data
+			if logging {
+				logged = append(logged, slog.String(property.Name, c.sensitive(property.Value.Data)))
+			}
 		}
 
 		continueToken = propertiesListPage.ContinuationToken
+		c.logPage(opGetProperties, len(propertiesListPage.Properties), continueToken, slog.Group("properties", logged...))
 		if continueToken == "" {
 			break
 		}
@@ -284,13 +469,46 @@ func (c Client) GetProperties(name string) (bool, map[string]string, error) {
 	return true, properties, nil
 }
 
+// GetProperty returns a single property of a name, including
+// its value whatever its kind.
+func (c Client) GetProperty(name, propertyName string) (*Property, error) {
+	res, err := c.getHTTP(opGetProperty, "Names/"+escapePath(name)+"/$/GetProperty", withParam("PropertyName", propertyName))
+	if err != nil {
+		return nil, err
+	}
+
+	var property Property
+	err = json.Unmarshal(res, &property)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &property, nil
+}
+
+// PutProperty sets a string property of a name, creating
+// or replacing the property.
+func (c Client) PutProperty(name, propertyName, value string) error {
+	description := struct {
+		PropertyName string    `json:"PropertyName"`
+		Value        PropValue `json:"Value"`
+	}{propertyName, PropValue{Kind: "String", Data: value}}
+	_, err := c.putHTTP(opPutProperty, "Names/"+escapePath(name)+"/$/GetProperty", description)
+	return err
+}
+
 // GetServiceLabels add labels from service manifest extensions and properties manager
 // expects extension xml in <Label key="key">value</Label>
 //
-// Deprecated: Use GetProperties and GetServiceExtensionMap instead.
+// Deprecated: Use LabelResolver which merges labels from the
+// manifest extension, properties and application parameters instead.
 func (c Client) GetServiceLabels(service *ServiceItem, app *ApplicationItem, prefix string) (map[string]string, error) {
+	return serviceLabels(c, service, app, prefix)
+}
+
+// serviceLabels reads the labels of GetServiceLabels through client
+func serviceLabels(client LabelReader, service *ServiceItem, app *ApplicationItem, prefix string) (map[string]string, error) {
 	extensionData := ServiceExtensionLabels{}
-	err := c.GetServiceExtension(app.TypeName, app.TypeVersion, service.TypeName, prefix, &extensionData)
+	err := client.GetServiceExtension(app.TypeName, app.TypeVersion, service.TypeName, prefix, &extensionData)
 	if err != nil {
 		return nil, err
 	}
@@ -307,7 +525,7 @@ func (c Client) GetServiceLabels(service *ServiceItem, app *ApplicationItem, pre
 		}
 	}
 
-	exists, properties, err := c.GetProperties(service.ID)
+	exists, properties, err := client.GetProperties(service.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -325,27 +543,104 @@ func (c Client) GetServiceLabels(service *ServiceItem, app *ApplicationItem, pre
 }
 
 func (c Client) nameExists(propertyName string) (bool, error) {
-	res, err := c.getHTTPRaw("Names/" + propertyName)
+	res, err := c.getHTTPRaw(opNameExists, "Names/"+escapePath(propertyName))
 	// Get http will return error for any non 200 response code.
 	if err != nil {
 		return false, err
 	}
+	if res.Body != nil {
+		res.Body.Close()
+	}
 
-	return res.StatusCode == http.StatusOK, nil
+	exists := res.StatusCode == http.StatusOK
+	c.debug("servicefabric: name looked up", slog.String("name", propertyName), slog.Bool("exists", exists))
+	return exists, nil
 }
 
-func (c Client) getHTTP(basePath string, paramsFuncs ...queryParamsFunc) ([]byte, error) {
-	if c.httpClient == nil {
-		return nil, errors.New("invalid http client provided")
+func (c Client) getHTTP(op operation, basePath string, paramsFuncs ...queryParamsFunc) ([]byte, error) {
+	res, err := c.getHTTPRaw(op, basePath, paramsFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return readResponse(res, func(statusCode int) bool { return statusCode == http.StatusOK })
+}
+
+// postHTTP posts body, encoded as JSON unless nil, and
+// returns the response body of any successful status code
+func (c Client) postHTTP(op operation, basePath string, body interface{}, paramsFuncs ...queryParamsFunc) ([]byte, error) {
+	return c.sendHTTP(op, http.MethodPost, basePath, body, paramsFuncs...)
+}
+
+// putHTTP puts body, encoded as JSON unless nil, and
+// returns the response body of any successful status code
+func (c Client) putHTTP(op operation, basePath string, body interface{}, paramsFuncs ...queryParamsFunc) ([]byte, error) {
+	return c.sendHTTP(op, http.MethodPut, basePath, body, paramsFuncs...)
+}
+
+func (c Client) sendHTTP(op operation, method, basePath string, body interface{}, paramsFuncs ...queryParamsFunc) ([]byte, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("could not serialise JSON request: %+v", err)
+		}
 	}
 
-	url := c.getURL(basePath, paramsFuncs...)
-	res, err := c.httpClient.Get(url)
+	res, err := c.doHTTP(op, method, basePath, data, paramsFuncs...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Service Fabric server %+v on %s", err, url)
+		return nil, err
+	}
+	return readResponse(res, func(statusCode int) bool { return statusCode >= 200 && statusCode < 300 })
+}
+
+// ResponseError is returned when Service Fabric responds
+// with an unsuccessful status code
+type ResponseError struct {
+	// StatusCode the HTTP status code of the response
+	StatusCode int
+	// Status the HTTP status line of the response
+	Status string
+	// URL the request URL
+	URL string
+	// Code the Service Fabric error code, such as
+	// FABRIC_E_APPLICATION_NOT_FOUND, when the response has one
+	Code string
+	// Message the Service Fabric error message, when the response has one
+	Message string
+}
+
+func (e *ResponseError) Error() string {
+	msg := "Service Fabric responded with error code " + e.Status
+	if e.URL != "" {
+		msg += " to request " + e.URL
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Service Fabric responded with error code %s to request %s with body %v", res.Status, url, res.Body)
+	switch {
+	case e.Code != "" && e.Message != "":
+		msg += ": " + e.Code + ": " + e.Message
+	case e.Code != "" || e.Message != "":
+		msg += ": " + e.Code + e.Message
+	}
+	return msg
+}
+
+// maxErrorBodySize limits how much of an error response is read
+const maxErrorBodySize = 64 * 1024
+
+func readResponse(res *http.Response, success func(statusCode int) bool) ([]byte, error) {
+	if !success(res.StatusCode) {
+		responseErr := &ResponseError{StatusCode: res.StatusCode, Status: res.Status, URL: res.Request.URL.String()}
+		if res.Body != nil {
+			var errorBody struct {
+				Error FabricErrorDetail `json:"Error"`
+			}
+			if body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBodySize)); err == nil && json.Unmarshal(body, &errorBody) == nil {
+				responseErr.Code = errorBody.Error.Code
+				responseErr.Message = errorBody.Error.Message
+			}
+			res.Body.Close()
+		}
+		return nil, responseErr
 	}
 
 	if res.Body == nil {
@@ -360,28 +655,95 @@ func (c Client) getHTTP(basePath string, paramsFuncs ...queryParamsFunc) ([]byte
 	return body, nil
 }
 
-func (c Client) getHTTPRaw(basePath string) (*http.Response, error) {
+func (c Client) getHTTPRaw(op operation, basePath string, paramsFuncs ...queryParamsFunc) (*http.Response, error) {
+	return c.doHTTP(op, http.MethodGet, basePath, nil, paramsFuncs...)
+}
+
+func (c Client) doHTTP(op operation, method, basePath string, body []byte, paramsFuncs ...queryParamsFunc) (*http.Response, error) {
 	if c.httpClient == nil {
-		return nil, fmt.Errorf("invalid http client provided")
+		return nil, errors.New("invalid http client provided")
 	}
 
-	url := c.getURL(basePath)
+	url, err := c.getURL(op, basePath, paramsFuncs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %v", url, err)
+	}
+	req = req.WithContext(c.context())
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	res, err := c.httpClient.Get(url)
+	release, err := c.waitForLimit(op)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Service Fabric server %+v on %s", err, url)
+		return nil, fmt.Errorf("gave up waiting for the rate limit to request %s: %w", url, err)
+	}
+	done := c.startRequest(req, RequestInfo{Operation: op.name, Template: op.template, Method: method, Attempt: 1})
+	if release != nil {
+		observed := done
+		done = func(result RequestResult) {
+			release()
+			if observed != nil {
+				observed(result)
+			}
+		}
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		if done != nil {
+			done(RequestResult{Err: err, RequestBytes: int64(len(body))})
+		}
+		return nil, fmt.Errorf("failed to connect to Service Fabric server %w on %s", err, url)
+	}
+	if done != nil {
+		res.Body = &observedBody{
+			ReadCloser: res.Body,
+			result:     RequestResult{StatusCode: res.StatusCode, RequestBytes: int64(len(body))},
+			done:       done,
+		}
 	}
 	return res, nil
 }
 
-func (c Client) getURL(basePath string, paramsFuncs ...queryParamsFunc) string {
-	params := []string{"api-version=" + c.apiVersion}
+// getURL returns the request URL for basePath, which must already
+// be escaped, using the API version chosen for op. When the client's context has a deadline and no
+// explicit timeout was given, the server side timeout is set so the
+// cluster gives up on the request when the caller does.
+func (c Client) getURL(op operation, basePath string, paramsFuncs ...queryParamsFunc) (string, error) {
+	u, err := url.Parse(strings.TrimRight(c.endpoint, "/") + "/" + basePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid request URL for %s: %v", basePath, err)
+	}
+
+	apiVersion, err := c.apiVersionFor(op)
+	if err != nil {
+		return "", err
+	}
 
+	q := &query{}
+	q.add("api-version", apiVersion)
 	for _, paramsFunc := range paramsFuncs {
-		params = paramsFunc(params)
+		paramsFunc(q)
 	}
 
-	return fmt.Sprintf("%s/%s?%s", c.endpoint, basePath, strings.Join(params, "&"))
+	if deadline, ok := c.context().Deadline(); ok && !q.has("timeout") {
+		withTimeout(time.Until(deadline))(q)
+	}
+
+	u.RawQuery = q.encode()
+	return u.String(), nil
+}
+
+func replicasPath(appName, serviceName, partitionName string) string {
+	return "Applications/" + escapePath(appName) + "/$/GetServices/" + escapePath(serviceName) + "/$/GetPartitions/" + escapeSegment(partitionName) + "/$/GetReplicas"
 }
 
 func getString(str *string) string {
@@ -1,7 +1,10 @@
 package servicefabric
 
 import (
+	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -556,3 +559,195 @@ type WrongType struct {
 	Prop1 string `json:"Prop1"`
 	Prop2 string `json:"Prop2"`
 }
+
+func TestGetServiceTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(handleServiceTypes))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	actual, err := sfClient.GetServiceTypes("TestApplication", "1.0.0")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if len(actual) != 1 {
+		t.Fatalf("Got %d service types, want 1", len(actual))
+	}
+	description := actual[0].ServiceTypeDescription
+
+	expectedMetrics := []ServiceLoadMetricDescription{
+		{
+			Name:                 "MemoryInMb",
+			Weight:               "High",
+			PrimaryDefaultLoad:   20,
+			SecondaryDefaultLoad: 10,
+		},
+	}
+	if !reflect.DeepEqual(description.LoadMetrics, expectedMetrics) {
+		t.Errorf("Got %+v, want %+v", description.LoadMetrics, expectedMetrics)
+	}
+
+	expectedPolicies := ServicePlacementPolicyDescriptions{
+		ServicePlacementInvalidDomainPolicyDescription{DomainName: "fd:/dc1"},
+		ServicePlacementRequiredDomainPolicyDescription{DomainName: "fd:/dc2"},
+		ServicePlacementPreferPrimaryDomainPolicyDescription{DomainName: "fd:/dc3"},
+		ServicePlacementRequireDomainDistributionPolicyDescription{DomainName: "fd:/dc4"},
+		ServicePlacementNonPartiallyPlaceServicePolicyDescription{},
+	}
+	if !reflect.DeepEqual(description.ServicePlacementPolicies, expectedPolicies) {
+		t.Errorf("Got %+v, want %+v", description.ServicePlacementPolicies, expectedPolicies)
+	}
+}
+
+func TestServicePlacementPoliciesRoundTrip(t *testing.T) {
+	input := `[{"Type":"RequireDomain","DomainName":"fd:/dc1"},{"Type":"SomethingNew","Value":1}]`
+
+	var policies ServicePlacementPolicyDescriptions
+	if err := json.Unmarshal([]byte(input), &policies); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	unknown, ok := policies[1].(ServicePlacementUnknownPolicyDescription)
+	if !ok || unknown.PolicyType() != "SomethingNew" {
+		t.Errorf("Got %+v, want unknown policy of type SomethingNew", policies[1])
+	}
+
+	output, err := json.Marshal(policies)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if string(output) != input {
+		t.Errorf("Got %s, want %s", output, input)
+	}
+}
+
+func TestGetServiceManifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(handleServiceManifest))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	manifest, err := sfClient.GetServiceManifest("TestApplication", "1.0.0", "TestPkg")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if manifest.Name != "TestPkg" || manifest.Version != "1.0.0" {
+		t.Errorf("Got manifest %s %s, want TestPkg 1.0.0", manifest.Name, manifest.Version)
+	}
+
+	serviceTypes := manifest.ServiceTypes.StatefulServiceTypes
+	if len(serviceTypes) != 1 || serviceTypes[0].ServiceTypeName != "Test" || !serviceTypes[0].HasPersistedState {
+		t.Errorf("Got service types %+v", manifest.ServiceTypes)
+	}
+	if len(serviceTypes[0].Extensions) != 1 || serviceTypes[0].Extensions[0].Name != "Traefik" {
+		t.Errorf("Got extensions %+v", serviceTypes[0].Extensions)
+	}
+
+	if len(manifest.CodePackages) != 1 || manifest.CodePackages[0].EntryPoint.ExeHost.Program != "Test.exe" {
+		t.Errorf("Got code packages %+v", manifest.CodePackages)
+	}
+	expectedVariables := []EnvironmentVariable{{Name: "LOG_LEVEL", Value: "debug"}}
+	if !reflect.DeepEqual(manifest.CodePackages[0].EnvironmentVariables, expectedVariables) {
+		t.Errorf("Got %+v, want %+v", manifest.CodePackages[0].EnvironmentVariables, expectedVariables)
+	}
+
+	expectedConfig := []ConfigPackage{{Name: "Config", Version: "1.0.0"}}
+	if !reflect.DeepEqual(manifest.ConfigPackages, expectedConfig) {
+		t.Errorf("Got %+v, want %+v", manifest.ConfigPackages, expectedConfig)
+	}
+	expectedData := []DataPackage{{Name: "Data", Version: "1.0.1"}}
+	if !reflect.DeepEqual(manifest.DataPackages, expectedData) {
+		t.Errorf("Got %+v, want %+v", manifest.DataPackages, expectedData)
+	}
+
+	expectedEndpoints := []Endpoint{
+		{Name: "ServiceEndpoint", Protocol: "http", Type: "Input", Port: 8080},
+		{Name: "ReplicatorEndpoint"},
+	}
+	if !reflect.DeepEqual(manifest.Resources.Endpoints, expectedEndpoints) {
+		t.Errorf("Got %+v, want %+v", manifest.Resources.Endpoints, expectedEndpoints)
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"Error":{"Code":"FABRIC_E_APPLICATION_NOT_FOUND","Message":"Application not found"}}`))
+	}))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	_, err := sfClient.GetServices("TestApplicationNonExistent")
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) {
+		t.Fatalf("Got %v, want a ResponseError", err)
+	}
+	if responseErr.StatusCode != http.StatusNotFound || responseErr.Code != "FABRIC_E_APPLICATION_NOT_FOUND" || responseErr.Message != "Application not found" {
+		t.Errorf("Got %+v, want the not found error", responseErr)
+	}
+	expected := "Service Fabric responded with error code 404 Not Found to request " + server.URL +
+		"/Applications/TestApplicationNonExistent/$/GetServices?api-version=1.0: FABRIC_E_APPLICATION_NOT_FOUND: Application not found"
+	if err.Error() != expected {
+		t.Errorf("Got %q, want %q", err.Error(), expected)
+	}
+
+	for _, test := range []struct {
+		err      ResponseError
+		expected string
+	}{
+		{ResponseError{Status: "500 Internal Server Error", URL: "http://localhost/"}, "Service Fabric responded with error code 500 Internal Server Error to request http://localhost/"},
+		{ResponseError{Status: "404 Not Found", Code: "FABRIC_E_NAME_DOES_NOT_EXIST"}, "Service Fabric responded with error code 404 Not Found: FABRIC_E_NAME_DOES_NOT_EXIST"},
+		{ResponseError{Status: "404 Not Found", Message: "name does not exist"}, "Service Fabric responded with error code 404 Not Found: name does not exist"},
+	} {
+		if msg := test.err.Error(); msg != test.expected {
+			t.Errorf("Got %q, want %q", msg, test.expected)
+		}
+	}
+}
+
+func TestPutAndGetProperty(t *testing.T) {
+	var stored PropValue
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Names/TestApplication/TestService/$/GetProperty" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var description struct {
+				PropertyName string
+				Value        PropValue
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &description); err != nil || description.PropertyName != "traefik.enable" {
+				http.Error(w, "bad property description", http.StatusBadRequest)
+				return
+			}
+			stored = description.Value
+		case http.MethodGet:
+			if r.URL.RawQuery != "api-version=6.0&PropertyName=traefik.enable" {
+				http.NotFound(w, r)
+				return
+			}
+			writeJSON(w, Property{Name: "traefik.enable", Value: stored})
+		}
+	}))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	if err := sfClient.PutProperty("TestApplication/TestService", "traefik.enable", "true"); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	property, err := sfClient.GetProperty("TestApplication/TestService", "traefik.enable")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	expected := PropValue{Kind: "String", Data: "true"}
+	if property.Value != expected {
+		t.Errorf("Got %+v, want %+v", property.Value, expected)
+	}
+}
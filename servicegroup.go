@@ -0,0 +1,158 @@
+package servicefabric
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// serviceGroupMemberSeparator separates the service group
+// name from the member name in a member's service name
+const serviceGroupMemberSeparator = "#"
+
+// serviceGroupAddressSeparator separates a member's
+// name from its address in a service group replica address
+const serviceGroupAddressSeparator = "%%"
+
+// ServiceGroupMemberDescription describes a member of a service group
+type ServiceGroupMemberDescription struct {
+	ServiceName        string                         `json:"ServiceName"`
+	ServiceTypeName    string                         `json:"ServiceTypeName"`
+	InitializationData []byte                         `json:"InitializationData"`
+	ServiceLoadMetrics []ServiceLoadMetricDescription `json:"ServiceLoadMetrics"`
+}
+
+// MemberName returns the name of the member within its service group
+func (m ServiceGroupMemberDescription) MemberName() string {
+	_, member := SplitServiceGroupMemberName(m.ServiceName)
+	return member
+}
+
+// ServiceGroupMembers encapsulates the response model for
+// the members of a service group in the Service Fabric API
+type ServiceGroupMembers struct {
+	ServiceName                   string                          `json:"ServiceName"`
+	ServiceGroupMemberDescription []ServiceGroupMemberDescription `json:"ServiceGroupMemberDescription"`
+}
+
+// ServiceGroupDescription encapsulates the response model for
+// the description of a service group in the Service Fabric API
+type ServiceGroupDescription struct {
+	ServiceKind                   string                          `json:"ServiceKind"`
+	ApplicationName               string                          `json:"ApplicationName"`
+	ServiceName                   string                          `json:"ServiceName"`
+	ServiceTypeName               string                          `json:"ServiceTypeName"`
+	InitializationData            []byte                          `json:"InitializationData"`
+	PlacementConstraints          string                          `json:"PlacementConstraints"`
+	HasPersistedState             bool                            `json:"HasPersistedState"`
+	TargetReplicaSetSize          int64                           `json:"TargetReplicaSetSize"`
+	MinReplicaSetSize             int64                           `json:"MinReplicaSetSize"`
+	InstanceCount                 int64                           `json:"InstanceCount"`
+	IsDefaultMoveCostSpecified    bool                            `json:"IsDefaultMoveCostSpecified"`
+	ServiceGroupMemberDescription []ServiceGroupMemberDescription `json:"ServiceGroupMemberDescription"`
+}
+
+// GetServiceGroupMembers returns the members of a service group
+// within a Service Fabric application.
+func (c Client) GetServiceGroupMembers(appName, serviceName string) (*ServiceGroupMembers, error) {
+	res, err := c.getHTTP(opGetServiceGroupMembers, "Applications/"+escapePath(appName)+"/$/GetServices/"+escapePath(serviceName)+"/$/GetServiceGroupMembers")
+	if err != nil {
+		return nil, err
+	}
+
+	var members []ServiceGroupMembers
+	err = json.Unmarshal(res, &members)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+
+	if len(members) == 0 {
+		return &ServiceGroupMembers{}, nil
+	}
+	return &members[0], nil
+}
+
+// GetServiceGroupDescription returns the description
+// of the service group identified by serviceID.
+func (c Client) GetServiceGroupDescription(serviceID ServiceID) (*ServiceGroupDescription, error) {
+	res, err := c.getHTTP(opGetServiceGroupDescription, "Services/"+escapeSegment(serviceID.String())+"/$/GetServiceGroupDescription")
+	if err != nil {
+		return nil, err
+	}
+
+	var description ServiceGroupDescription
+	err = json.Unmarshal(res, &description)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &description, nil
+}
+
+// SplitServiceGroupMemberName splits a member service name such as
+// fabric:/app/group#member into the group and member names
+func SplitServiceGroupMemberName(serviceName string) (string, string) {
+	i := strings.LastIndex(serviceName, serviceGroupMemberSeparator)
+	if i < 0 {
+		return serviceName, ""
+	}
+	return serviceName[:i], serviceName[i+len(serviceGroupMemberSeparator):]
+}
+
+// ParseServiceGroupAddress splits the multiplexed address published
+// by a service group replica into the address of each member, keyed
+// by member name. The address takes the form
+// #member1%%{"Endpoints":{...}}#member2%%{"Endpoints":{...}}
+func ParseServiceGroupAddress(address string) (map[string]string, error) {
+	members := map[string]string{}
+	rest := address
+	for rest != "" {
+		if !strings.HasPrefix(rest, serviceGroupMemberSeparator) {
+			return nil, fmt.Errorf("invalid service group address %q: expected %q", address, serviceGroupMemberSeparator)
+		}
+		rest = rest[len(serviceGroupMemberSeparator):]
+
+		i := strings.Index(rest, serviceGroupAddressSeparator)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid service group address %q: missing %q", address, serviceGroupAddressSeparator)
+		}
+		member := rest[:i]
+		rest = rest[i+len(serviceGroupAddressSeparator):]
+
+		// Member addresses are JSON documents which may themselves contain
+		// the separators, so read exactly one value to find where it ends.
+		decoder := json.NewDecoder(strings.NewReader(rest))
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid service group address %q for member %q: %+v", address, member, err)
+		}
+		members[member] = string(bytes.TrimSpace(raw))
+		rest = strings.TrimSpace(rest[decoder.InputOffset():])
+	}
+	return members, nil
+}
+
+// IsServiceGroupAddress reports whether an address
+// is the multiplexed address of a service group replica
+func IsServiceGroupAddress(address string) bool {
+	return strings.HasPrefix(address, serviceGroupMemberSeparator) && strings.Contains(address, serviceGroupAddressSeparator)
+}
+
+// GetMemberEndpoints returns the named endpoints published by
+// each member of a service group replica, keyed by member name
+func (m *ReplicaItemBase) GetMemberEndpoints() (map[string]map[string]string, error) {
+	addresses, err := ParseServiceGroupAddress(m.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make(map[string]map[string]string, len(addresses))
+	for member, address := range addresses {
+		memberEndpoints, err := ParseReplicaAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[member] = memberEndpoints
+	}
+	return endpoints, nil
+}
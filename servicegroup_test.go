@@ -0,0 +1,127 @@
+package servicefabric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetServiceGroupMembers(t *testing.T) {
+	server := httptest.NewServer(handleServiceGroup())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	actual, err := sfClient.GetServiceGroupMembers("TestApplication", "TestApplication/TestGroup")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if actual.ServiceName != "fabric:/TestApplication/TestGroup" {
+		t.Errorf("Got service name %s, want fabric:/TestApplication/TestGroup", actual.ServiceName)
+	}
+
+	var members []string
+	for _, member := range actual.ServiceGroupMemberDescription {
+		members = append(members, member.MemberName())
+	}
+	expected := []string{"Frontend", "Backend"}
+	if !reflect.DeepEqual(members, expected) {
+		t.Errorf("Got %v, want %v", members, expected)
+	}
+}
+
+func TestGetServiceGroupDescription(t *testing.T) {
+	server := httptest.NewServer(handleServiceGroup())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	actual, err := sfClient.GetServiceGroupDescription(ServiceID("TestApplication~TestGroup"))
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	if actual.ServiceTypeName != "TestGroupType" || actual.InstanceCount != -1 {
+		t.Errorf("Got %+v", actual)
+	}
+	if len(actual.ServiceGroupMemberDescription) != 2 {
+		t.Errorf("Got %d members, want 2", len(actual.ServiceGroupMemberDescription))
+	}
+}
+
+func TestParseServiceGroupAddress(t *testing.T) {
+	address := `#Frontend%%{"Endpoints":{"":"http://localhost:8080/#anchor"}}#Backend%%{"Endpoints":{"":"localhost:9000+p1"}}`
+
+	actual, err := ParseServiceGroupAddress(address)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	expected := map[string]string{
+		"Frontend": `{"Endpoints":{"":"http://localhost:8080/#anchor"}}`,
+		"Backend":  `{"Endpoints":{"":"localhost:9000+p1"}}`,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Got %+v, want %+v", actual, expected)
+	}
+
+	replica := &ReplicaItemBase{Address: address}
+	endpoints, err := replica.GetMemberEndpoints()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if endpoints["Frontend"][""] != "http://localhost:8080/#anchor" {
+		t.Errorf("Got %+v, want Frontend endpoint http://localhost:8080/#anchor", endpoints)
+	}
+}
+
+func TestParseServiceGroupAddressReturnsError(t *testing.T) {
+	testCases := []string{
+		`{"Endpoints":{}}`,
+		`#Frontend{"Endpoints":{}}`,
+		`#Frontend%%{"Endpoints":`,
+	}
+
+	for _, address := range testCases {
+		if _, err := ParseServiceGroupAddress(address); err == nil {
+			t.Errorf("Error should have been returned for %q", address)
+		}
+	}
+}
+
+func TestLabelResolverServiceGroupMember(t *testing.T) {
+	server := httptest.NewServer(handleServiceGroup())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "1.0", nil)
+
+	service := &ServiceItem{
+		ID:             "TestApplication~TestGroup",
+		Name:           "fabric:/TestApplication/TestGroup",
+		IsServiceGroup: true,
+	}
+	app := &ApplicationItem{Name: "fabric:/TestApplication"}
+
+	resolver := NewLabelResolver(sfClient, "traefik")
+	resolver.Sources = []LabelSourceConfig{{Source: LabelSourceServiceGroupMember, Prefix: "traefik"}}
+
+	labels, err := resolver.ResolveMember(service, app, "Frontend")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	rule := labels["frontend.rule"]
+	if rule.Origin != "fabric:/TestApplication/TestGroup#Frontend" {
+		t.Errorf("Got origin %s, want fabric:/TestApplication/TestGroup#Frontend", rule.Origin)
+	}
+
+	labels, err = resolver.ResolveMember(service, app, "Backend")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(labels) != 0 {
+		t.Errorf("Got %+v, want no labels", labels)
+	}
+}
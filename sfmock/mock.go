@@ -0,0 +1,859 @@
+// Code generated by mockgen from api.go. DO NOT EDIT.
+
+package sfmock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// Mock implements servicefabric.API by calling the Func field named
+// after each method, and records every call. Methods whose Func is
+// nil return zero values and ErrNotStubbed. Funcs must be set before
+// the mock is used, after which it is safe for concurrent use.
+type Mock struct {
+	mu    sync.Mutex
+	calls []Call
+
+	GetClusterVersionFunc              func() (*sf.ClusterVersion, error)
+	GetApplicationsFunc                func() (*sf.ApplicationItemsPage, error)
+	GetApplicationsWithOptionsFunc     func(sf.ApplicationsOptions) (*sf.ApplicationItemsPage, error)
+	GetServicesFunc                    func(string) (*sf.ServiceItemsPage, error)
+	GetServicesWithOptionsFunc         func(string, sf.ServicesOptions) (*sf.ServiceItemsPage, error)
+	GetServiceApplicationNameFunc      func(sf.ServiceID) (*sf.NameInfo, error)
+	GetServiceTypesFunc                func(string, string) ([]sf.ServiceType, error)
+	GetServiceExtensionFunc            func(string, string, string, string, interface{}) error
+	GetServiceExtensionMapFunc         func(*sf.ServiceItem, *sf.ApplicationItem, string) (map[string]string, error)
+	GetServiceManifestFunc             func(string, string, string) (*sf.ServiceManifest, error)
+	GetServiceGroupMembersFunc         func(string, string) (*sf.ServiceGroupMembers, error)
+	GetServiceGroupDescriptionFunc     func(sf.ServiceID) (*sf.ServiceGroupDescription, error)
+	GetPartitionsFunc                  func(string, string) (*sf.PartitionItemsPage, error)
+	GetPartitionsWithOptionsFunc       func(string, string, sf.ListOptions) (*sf.PartitionItemsPage, error)
+	GetServicePartitionsFunc           func(sf.ServiceID) (*sf.PartitionItemsPage, error)
+	GetPartitionFunc                   func(sf.PartitionID) (*sf.PartitionItem, error)
+	GetPartitionServiceNameFunc        func(sf.PartitionID) (*sf.NameInfo, error)
+	GetInstancesFunc                   func(string, string, string) (*sf.InstanceItemsPage, error)
+	GetInstancesWithOptionsFunc        func(string, string, string, sf.ListOptions) (*sf.InstanceItemsPage, error)
+	GetReplicasFunc                    func(string, string, string) (*sf.ReplicaItemsPage, error)
+	GetReplicasWithOptionsFunc         func(string, string, string, sf.ListOptions) (*sf.ReplicaItemsPage, error)
+	GetPartitionMembersFunc            func(string, string, string) ([]sf.PartitionMember, error)
+	GetPartitionMembersWithOptionsFunc func(string, string, string, sf.ListOptions) ([]sf.PartitionMember, error)
+	GetPartitionMembersByIDFunc        func(sf.PartitionID) ([]sf.PartitionMember, error)
+	GetPrimaryFunc                     func(context.Context, string, string, string) (*sf.ReplicaItem, error)
+	GetPropertiesFunc                  func(string) (bool, map[string]string, error)
+	GetPropertyFunc                    func(string, string) (*sf.Property, error)
+	GetServiceLabelsFunc               func(*sf.ServiceItem, *sf.ApplicationItem, string) (map[string]string, error)
+	GetClusterEventsFunc               func(sf.EventsOptions) ([]sf.Event, error)
+	GetNodesEventsFunc                 func(sf.EventsOptions) ([]sf.Event, error)
+	GetNodeEventsFunc                  func(string, sf.EventsOptions) ([]sf.Event, error)
+	GetApplicationsEventsFunc          func(sf.EventsOptions) ([]sf.Event, error)
+	GetApplicationEventsFunc           func(sf.ApplicationID, sf.EventsOptions) ([]sf.Event, error)
+	GetServicesEventsFunc              func(sf.EventsOptions) ([]sf.Event, error)
+	GetServiceEventsFunc               func(sf.ServiceID, sf.EventsOptions) ([]sf.Event, error)
+	GetPartitionsEventsFunc            func(sf.EventsOptions) ([]sf.Event, error)
+	GetPartitionEventsFunc             func(sf.PartitionID, sf.EventsOptions) ([]sf.Event, error)
+	GetPartitionReplicasEventsFunc     func(sf.PartitionID, sf.EventsOptions) ([]sf.Event, error)
+	GetPartitionReplicaEventsFunc      func(sf.PartitionID, string, sf.EventsOptions) ([]sf.Event, error)
+	GetBackupPolicyFunc                func(string) (*sf.BackupPolicyDescription, error)
+	GetPartitionBackupListFunc         func(sf.PartitionID, sf.BackupListOptions) ([]sf.BackupInfo, error)
+	GetPartitionBackupProgressFunc     func(sf.PartitionID) (*sf.BackupProgressInfo, error)
+	GetPartitionRestoreProgressFunc    func(sf.PartitionID) (*sf.RestoreProgressInfo, error)
+	WaitForBackupFunc                  func(context.Context, sf.PartitionID) (*sf.BackupProgressInfo, error)
+	WaitForRestoreFunc                 func(context.Context, sf.PartitionID) (*sf.RestoreProgressInfo, error)
+	GetChaosFunc                       func() (*sf.ChaosInfo, error)
+	GetChaosEventsFunc                 func(sf.ChaosEventsOptions) ([]sf.ChaosEvent, error)
+	GetChaosScheduleFunc               func() (*sf.ChaosScheduleDescription, error)
+	GetDataLossProgressFunc            func(sf.ServiceID, sf.PartitionID, sf.OperationID) (*sf.PartitionDataLossProgress, error)
+	GetQuorumLossProgressFunc          func(sf.ServiceID, sf.PartitionID, sf.OperationID) (*sf.PartitionQuorumLossProgress, error)
+	GetPartitionRestartProgressFunc    func(sf.ServiceID, sf.PartitionID, sf.OperationID) (*sf.PartitionRestartProgress, error)
+	GetNodeTransitionProgressFunc      func(string, sf.OperationID) (*sf.NodeTransitionProgress, error)
+	GetRepairTaskListFunc              func(sf.RepairTaskListOptions) ([]sf.RepairTask, error)
+	PutPropertyFunc                    func(string, string, string) error
+	CreateBackupPolicyFunc             func(sf.BackupPolicyDescription) error
+	EnableApplicationBackupFunc        func(sf.ApplicationID, string) error
+	EnableServiceBackupFunc            func(sf.ServiceID, string) error
+	EnablePartitionBackupFunc          func(sf.PartitionID, string) error
+	BackupPartitionFunc                func(sf.PartitionID, sf.BackupPartitionOptions) error
+	RestorePartitionFunc               func(sf.PartitionID, sf.RestorePartitionDescription) error
+	StartChaosFunc                     func(sf.ChaosParameters) error
+	StopChaosFunc                      func() error
+	PostChaosScheduleFunc              func(sf.ChaosScheduleDescription) error
+	RunChaosFunc                       func(context.Context, sf.ChaosParameters, time.Duration) (*sf.ChaosSummary, error)
+	StartDataLossFunc                  func(sf.ServiceID, sf.PartitionID, sf.DataLossMode) (*sf.FaultOperation, error)
+	StartQuorumLossFunc                func(sf.ServiceID, sf.PartitionID, sf.QuorumLossMode, time.Duration) (*sf.FaultOperation, error)
+	StartPartitionRestartFunc          func(sf.ServiceID, sf.PartitionID, sf.RestartPartitionMode) (*sf.FaultOperation, error)
+	StartNodeTransitionFunc            func(string, string, sf.NodeTransitionType, time.Duration) (*sf.FaultOperation, error)
+	CancelOperationFunc                func(sf.OperationID, bool) error
+	RestartReplicaFunc                 func(string, sf.PartitionID, string) error
+	RemoveReplicaFunc                  func(string, sf.PartitionID, string, bool) error
+	CreateRepairTaskFunc               func(sf.RepairTask) (string, error)
+	CancelRepairTaskFunc               func(string, string, bool) (string, error)
+	ForceApproveRepairTaskFunc         func(string, string) (string, error)
+	UpdateRepairExecutionStateFunc     func(sf.RepairTask) (string, error)
+	DeleteRepairTaskFunc               func(string, string) error
+}
+
+var _ sf.API = (*Mock)(nil)
+
+// GetClusterVersion calls GetClusterVersionFunc
+func (m *Mock) GetClusterVersion() (r0 *sf.ClusterVersion, err error) {
+	m.record("GetClusterVersion")
+	if m.GetClusterVersionFunc == nil {
+		err = notStubbed("GetClusterVersion")
+		return
+	}
+	return m.GetClusterVersionFunc()
+}
+
+// GetApplications calls GetApplicationsFunc
+func (m *Mock) GetApplications() (r0 *sf.ApplicationItemsPage, err error) {
+	m.record("GetApplications")
+	if m.GetApplicationsFunc == nil {
+		err = notStubbed("GetApplications")
+		return
+	}
+	return m.GetApplicationsFunc()
+}
+
+// GetApplicationsWithOptions calls GetApplicationsWithOptionsFunc
+func (m *Mock) GetApplicationsWithOptions(opts sf.ApplicationsOptions) (r0 *sf.ApplicationItemsPage, err error) {
+	m.record("GetApplicationsWithOptions", opts)
+	if m.GetApplicationsWithOptionsFunc == nil {
+		err = notStubbed("GetApplicationsWithOptions")
+		return
+	}
+	return m.GetApplicationsWithOptionsFunc(opts)
+}
+
+// GetServices calls GetServicesFunc
+func (m *Mock) GetServices(appName string) (r0 *sf.ServiceItemsPage, err error) {
+	m.record("GetServices", appName)
+	if m.GetServicesFunc == nil {
+		err = notStubbed("GetServices")
+		return
+	}
+	return m.GetServicesFunc(appName)
+}
+
+// GetServicesWithOptions calls GetServicesWithOptionsFunc
+func (m *Mock) GetServicesWithOptions(appName string, opts sf.ServicesOptions) (r0 *sf.ServiceItemsPage, err error) {
+	m.record("GetServicesWithOptions", appName, opts)
+	if m.GetServicesWithOptionsFunc == nil {
+		err = notStubbed("GetServicesWithOptions")
+		return
+	}
+	return m.GetServicesWithOptionsFunc(appName, opts)
+}
+
+// GetServiceApplicationName calls GetServiceApplicationNameFunc
+func (m *Mock) GetServiceApplicationName(serviceID sf.ServiceID) (r0 *sf.NameInfo, err error) {
+	m.record("GetServiceApplicationName", serviceID)
+	if m.GetServiceApplicationNameFunc == nil {
+		err = notStubbed("GetServiceApplicationName")
+		return
+	}
+	return m.GetServiceApplicationNameFunc(serviceID)
+}
+
+// GetServiceTypes calls GetServiceTypesFunc
+func (m *Mock) GetServiceTypes(appType string, applicationVersion string) (r0 []sf.ServiceType, err error) {
+	m.record("GetServiceTypes", appType, applicationVersion)
+	if m.GetServiceTypesFunc == nil {
+		err = notStubbed("GetServiceTypes")
+		return
+	}
+	return m.GetServiceTypesFunc(appType, applicationVersion)
+}
+
+// GetServiceExtension calls GetServiceExtensionFunc
+func (m *Mock) GetServiceExtension(appType string, applicationVersion string, serviceTypeName string, extensionKey string, response interface{}) (err error) {
+	m.record("GetServiceExtension", appType, applicationVersion, serviceTypeName, extensionKey, response)
+	if m.GetServiceExtensionFunc == nil {
+		err = notStubbed("GetServiceExtension")
+		return
+	}
+	return m.GetServiceExtensionFunc(appType, applicationVersion, serviceTypeName, extensionKey, response)
+}
+
+// GetServiceExtensionMap calls GetServiceExtensionMapFunc
+func (m *Mock) GetServiceExtensionMap(service *sf.ServiceItem, app *sf.ApplicationItem, extensionKey string) (r0 map[string]string, err error) {
+	m.record("GetServiceExtensionMap", service, app, extensionKey)
+	if m.GetServiceExtensionMapFunc == nil {
+		err = notStubbed("GetServiceExtensionMap")
+		return
+	}
+	return m.GetServiceExtensionMapFunc(service, app, extensionKey)
+}
+
+// GetServiceManifest calls GetServiceManifestFunc
+func (m *Mock) GetServiceManifest(appType string, applicationVersion string, serviceManifestName string) (r0 *sf.ServiceManifest, err error) {
+	m.record("GetServiceManifest", appType, applicationVersion, serviceManifestName)
+	if m.GetServiceManifestFunc == nil {
+		err = notStubbed("GetServiceManifest")
+		return
+	}
+	return m.GetServiceManifestFunc(appType, applicationVersion, serviceManifestName)
+}
+
+// GetServiceGroupMembers calls GetServiceGroupMembersFunc
+func (m *Mock) GetServiceGroupMembers(appName string, serviceName string) (r0 *sf.ServiceGroupMembers, err error) {
+	m.record("GetServiceGroupMembers", appName, serviceName)
+	if m.GetServiceGroupMembersFunc == nil {
+		err = notStubbed("GetServiceGroupMembers")
+		return
+	}
+	return m.GetServiceGroupMembersFunc(appName, serviceName)
+}
+
+// GetServiceGroupDescription calls GetServiceGroupDescriptionFunc
+func (m *Mock) GetServiceGroupDescription(serviceID sf.ServiceID) (r0 *sf.ServiceGroupDescription, err error) {
+	m.record("GetServiceGroupDescription", serviceID)
+	if m.GetServiceGroupDescriptionFunc == nil {
+		err = notStubbed("GetServiceGroupDescription")
+		return
+	}
+	return m.GetServiceGroupDescriptionFunc(serviceID)
+}
+
+// GetPartitions calls GetPartitionsFunc
+func (m *Mock) GetPartitions(appName string, serviceName string) (r0 *sf.PartitionItemsPage, err error) {
+	m.record("GetPartitions", appName, serviceName)
+	if m.GetPartitionsFunc == nil {
+		err = notStubbed("GetPartitions")
+		return
+	}
+	return m.GetPartitionsFunc(appName, serviceName)
+}
+
+// GetPartitionsWithOptions calls GetPartitionsWithOptionsFunc
+func (m *Mock) GetPartitionsWithOptions(appName string, serviceName string, opts sf.ListOptions) (r0 *sf.PartitionItemsPage, err error) {
+	m.record("GetPartitionsWithOptions", appName, serviceName, opts)
+	if m.GetPartitionsWithOptionsFunc == nil {
+		err = notStubbed("GetPartitionsWithOptions")
+		return
+	}
+	return m.GetPartitionsWithOptionsFunc(appName, serviceName, opts)
+}
+
+// GetServicePartitions calls GetServicePartitionsFunc
+func (m *Mock) GetServicePartitions(serviceID sf.ServiceID) (r0 *sf.PartitionItemsPage, err error) {
+	m.record("GetServicePartitions", serviceID)
+	if m.GetServicePartitionsFunc == nil {
+		err = notStubbed("GetServicePartitions")
+		return
+	}
+	return m.GetServicePartitionsFunc(serviceID)
+}
+
+// GetPartition calls GetPartitionFunc
+func (m *Mock) GetPartition(partitionID sf.PartitionID) (r0 *sf.PartitionItem, err error) {
+	m.record("GetPartition", partitionID)
+	if m.GetPartitionFunc == nil {
+		err = notStubbed("GetPartition")
+		return
+	}
+	return m.GetPartitionFunc(partitionID)
+}
+
+// GetPartitionServiceName calls GetPartitionServiceNameFunc
+func (m *Mock) GetPartitionServiceName(partitionID sf.PartitionID) (r0 *sf.NameInfo, err error) {
+	m.record("GetPartitionServiceName", partitionID)
+	if m.GetPartitionServiceNameFunc == nil {
+		err = notStubbed("GetPartitionServiceName")
+		return
+	}
+	return m.GetPartitionServiceNameFunc(partitionID)
+}
+
+// GetInstances calls GetInstancesFunc
+func (m *Mock) GetInstances(appName string, serviceName string, partitionName string) (r0 *sf.InstanceItemsPage, err error) {
+	m.record("GetInstances", appName, serviceName, partitionName)
+	if m.GetInstancesFunc == nil {
+		err = notStubbed("GetInstances")
+		return
+	}
+	return m.GetInstancesFunc(appName, serviceName, partitionName)
+}
+
+// GetInstancesWithOptions calls GetInstancesWithOptionsFunc
+func (m *Mock) GetInstancesWithOptions(appName string, serviceName string, partitionName string, opts sf.ListOptions) (r0 *sf.InstanceItemsPage, err error) {
+	m.record("GetInstancesWithOptions", appName, serviceName, partitionName, opts)
+	if m.GetInstancesWithOptionsFunc == nil {
+		err = notStubbed("GetInstancesWithOptions")
+		return
+	}
+	return m.GetInstancesWithOptionsFunc(appName, serviceName, partitionName, opts)
+}
+
+// GetReplicas calls GetReplicasFunc
+func (m *Mock) GetReplicas(appName string, serviceName string, partitionName string) (r0 *sf.ReplicaItemsPage, err error) {
+	m.record("GetReplicas", appName, serviceName, partitionName)
+	if m.GetReplicasFunc == nil {
+		err = notStubbed("GetReplicas")
+		return
+	}
+	return m.GetReplicasFunc(appName, serviceName, partitionName)
+}
+
+// GetReplicasWithOptions calls GetReplicasWithOptionsFunc
+func (m *Mock) GetReplicasWithOptions(appName string, serviceName string, partitionName string, opts sf.ListOptions) (r0 *sf.ReplicaItemsPage, err error) {
+	m.record("GetReplicasWithOptions", appName, serviceName, partitionName, opts)
+	if m.GetReplicasWithOptionsFunc == nil {
+		err = notStubbed("GetReplicasWithOptions")
+		return
+	}
+	return m.GetReplicasWithOptionsFunc(appName, serviceName, partitionName, opts)
+}
+
+// GetPartitionMembers calls GetPartitionMembersFunc
+func (m *Mock) GetPartitionMembers(appName string, serviceName string, partitionName string) (r0 []sf.PartitionMember, err error) {
+	m.record("GetPartitionMembers", appName, serviceName, partitionName)
+	if m.GetPartitionMembersFunc == nil {
+		err = notStubbed("GetPartitionMembers")
+		return
+	}
+	return m.GetPartitionMembersFunc(appName, serviceName, partitionName)
+}
+
+// GetPartitionMembersWithOptions calls GetPartitionMembersWithOptionsFunc
+func (m *Mock) GetPartitionMembersWithOptions(appName string, serviceName string, partitionName string, opts sf.ListOptions) (r0 []sf.PartitionMember, err error) {
+	m.record("GetPartitionMembersWithOptions", appName, serviceName, partitionName, opts)
+	if m.GetPartitionMembersWithOptionsFunc == nil {
+		err = notStubbed("GetPartitionMembersWithOptions")
+		return
+	}
+	return m.GetPartitionMembersWithOptionsFunc(appName, serviceName, partitionName, opts)
+}
+
+// GetPartitionMembersByID calls GetPartitionMembersByIDFunc
+func (m *Mock) GetPartitionMembersByID(partitionID sf.PartitionID) (r0 []sf.PartitionMember, err error) {
+	m.record("GetPartitionMembersByID", partitionID)
+	if m.GetPartitionMembersByIDFunc == nil {
+		err = notStubbed("GetPartitionMembersByID")
+		return
+	}
+	return m.GetPartitionMembersByIDFunc(partitionID)
+}
+
+// GetPrimary calls GetPrimaryFunc
+func (m *Mock) GetPrimary(ctx context.Context, appName string, serviceName string, partitionName string) (r0 *sf.ReplicaItem, err error) {
+	m.record("GetPrimary", ctx, appName, serviceName, partitionName)
+	if m.GetPrimaryFunc == nil {
+		err = notStubbed("GetPrimary")
+		return
+	}
+	return m.GetPrimaryFunc(ctx, appName, serviceName, partitionName)
+}
+
+// GetProperties calls GetPropertiesFunc
+func (m *Mock) GetProperties(name string) (r0 bool, r1 map[string]string, err error) {
+	m.record("GetProperties", name)
+	if m.GetPropertiesFunc == nil {
+		err = notStubbed("GetProperties")
+		return
+	}
+	return m.GetPropertiesFunc(name)
+}
+
+// GetProperty calls GetPropertyFunc
+func (m *Mock) GetProperty(name string, propertyName string) (r0 *sf.Property, err error) {
+	m.record("GetProperty", name, propertyName)
+	if m.GetPropertyFunc == nil {
+		err = notStubbed("GetProperty")
+		return
+	}
+	return m.GetPropertyFunc(name, propertyName)
+}
+
+// GetServiceLabels calls GetServiceLabelsFunc
+func (m *Mock) GetServiceLabels(service *sf.ServiceItem, app *sf.ApplicationItem, prefix string) (r0 map[string]string, err error) {
+	m.record("GetServiceLabels", service, app, prefix)
+	if m.GetServiceLabelsFunc == nil {
+		err = notStubbed("GetServiceLabels")
+		return
+	}
+	return m.GetServiceLabelsFunc(service, app, prefix)
+}
+
+// GetClusterEvents calls GetClusterEventsFunc
+func (m *Mock) GetClusterEvents(opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetClusterEvents", opts)
+	if m.GetClusterEventsFunc == nil {
+		err = notStubbed("GetClusterEvents")
+		return
+	}
+	return m.GetClusterEventsFunc(opts)
+}
+
+// GetNodesEvents calls GetNodesEventsFunc
+func (m *Mock) GetNodesEvents(opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetNodesEvents", opts)
+	if m.GetNodesEventsFunc == nil {
+		err = notStubbed("GetNodesEvents")
+		return
+	}
+	return m.GetNodesEventsFunc(opts)
+}
+
+// GetNodeEvents calls GetNodeEventsFunc
+func (m *Mock) GetNodeEvents(nodeName string, opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetNodeEvents", nodeName, opts)
+	if m.GetNodeEventsFunc == nil {
+		err = notStubbed("GetNodeEvents")
+		return
+	}
+	return m.GetNodeEventsFunc(nodeName, opts)
+}
+
+// GetApplicationsEvents calls GetApplicationsEventsFunc
+func (m *Mock) GetApplicationsEvents(opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetApplicationsEvents", opts)
+	if m.GetApplicationsEventsFunc == nil {
+		err = notStubbed("GetApplicationsEvents")
+		return
+	}
+	return m.GetApplicationsEventsFunc(opts)
+}
+
+// GetApplicationEvents calls GetApplicationEventsFunc
+func (m *Mock) GetApplicationEvents(applicationID sf.ApplicationID, opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetApplicationEvents", applicationID, opts)
+	if m.GetApplicationEventsFunc == nil {
+		err = notStubbed("GetApplicationEvents")
+		return
+	}
+	return m.GetApplicationEventsFunc(applicationID, opts)
+}
+
+// GetServicesEvents calls GetServicesEventsFunc
+func (m *Mock) GetServicesEvents(opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetServicesEvents", opts)
+	if m.GetServicesEventsFunc == nil {
+		err = notStubbed("GetServicesEvents")
+		return
+	}
+	return m.GetServicesEventsFunc(opts)
+}
+
+// GetServiceEvents calls GetServiceEventsFunc
+func (m *Mock) GetServiceEvents(serviceID sf.ServiceID, opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetServiceEvents", serviceID, opts)
+	if m.GetServiceEventsFunc == nil {
+		err = notStubbed("GetServiceEvents")
+		return
+	}
+	return m.GetServiceEventsFunc(serviceID, opts)
+}
+
+// GetPartitionsEvents calls GetPartitionsEventsFunc
+func (m *Mock) GetPartitionsEvents(opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetPartitionsEvents", opts)
+	if m.GetPartitionsEventsFunc == nil {
+		err = notStubbed("GetPartitionsEvents")
+		return
+	}
+	return m.GetPartitionsEventsFunc(opts)
+}
+
+// GetPartitionEvents calls GetPartitionEventsFunc
+func (m *Mock) GetPartitionEvents(partitionID sf.PartitionID, opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetPartitionEvents", partitionID, opts)
+	if m.GetPartitionEventsFunc == nil {
+		err = notStubbed("GetPartitionEvents")
+		return
+	}
+	return m.GetPartitionEventsFunc(partitionID, opts)
+}
+
+// GetPartitionReplicasEvents calls GetPartitionReplicasEventsFunc
+func (m *Mock) GetPartitionReplicasEvents(partitionID sf.PartitionID, opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetPartitionReplicasEvents", partitionID, opts)
+	if m.GetPartitionReplicasEventsFunc == nil {
+		err = notStubbed("GetPartitionReplicasEvents")
+		return
+	}
+	return m.GetPartitionReplicasEventsFunc(partitionID, opts)
+}
+
+// GetPartitionReplicaEvents calls GetPartitionReplicaEventsFunc
+func (m *Mock) GetPartitionReplicaEvents(partitionID sf.PartitionID, replicaID string, opts sf.EventsOptions) (r0 []sf.Event, err error) {
+	m.record("GetPartitionReplicaEvents", partitionID, replicaID, opts)
+	if m.GetPartitionReplicaEventsFunc == nil {
+		err = notStubbed("GetPartitionReplicaEvents")
+		return
+	}
+	return m.GetPartitionReplicaEventsFunc(partitionID, replicaID, opts)
+}
+
+// GetBackupPolicy calls GetBackupPolicyFunc
+func (m *Mock) GetBackupPolicy(policyName string) (r0 *sf.BackupPolicyDescription, err error) {
+	m.record("GetBackupPolicy", policyName)
+	if m.GetBackupPolicyFunc == nil {
+		err = notStubbed("GetBackupPolicy")
+		return
+	}
+	return m.GetBackupPolicyFunc(policyName)
+}
+
+// GetPartitionBackupList calls GetPartitionBackupListFunc
+func (m *Mock) GetPartitionBackupList(partitionID sf.PartitionID, opts sf.BackupListOptions) (r0 []sf.BackupInfo, err error) {
+	m.record("GetPartitionBackupList", partitionID, opts)
+	if m.GetPartitionBackupListFunc == nil {
+		err = notStubbed("GetPartitionBackupList")
+		return
+	}
+	return m.GetPartitionBackupListFunc(partitionID, opts)
+}
+
+// GetPartitionBackupProgress calls GetPartitionBackupProgressFunc
+func (m *Mock) GetPartitionBackupProgress(partitionID sf.PartitionID) (r0 *sf.BackupProgressInfo, err error) {
+	m.record("GetPartitionBackupProgress", partitionID)
+	if m.GetPartitionBackupProgressFunc == nil {
+		err = notStubbed("GetPartitionBackupProgress")
+		return
+	}
+	return m.GetPartitionBackupProgressFunc(partitionID)
+}
+
+// GetPartitionRestoreProgress calls GetPartitionRestoreProgressFunc
+func (m *Mock) GetPartitionRestoreProgress(partitionID sf.PartitionID) (r0 *sf.RestoreProgressInfo, err error) {
+	m.record("GetPartitionRestoreProgress", partitionID)
+	if m.GetPartitionRestoreProgressFunc == nil {
+		err = notStubbed("GetPartitionRestoreProgress")
+		return
+	}
+	return m.GetPartitionRestoreProgressFunc(partitionID)
+}
+
+// WaitForBackup calls WaitForBackupFunc
+func (m *Mock) WaitForBackup(ctx context.Context, partitionID sf.PartitionID) (r0 *sf.BackupProgressInfo, err error) {
+	m.record("WaitForBackup", ctx, partitionID)
+	if m.WaitForBackupFunc == nil {
+		err = notStubbed("WaitForBackup")
+		return
+	}
+	return m.WaitForBackupFunc(ctx, partitionID)
+}
+
+// WaitForRestore calls WaitForRestoreFunc
+func (m *Mock) WaitForRestore(ctx context.Context, partitionID sf.PartitionID) (r0 *sf.RestoreProgressInfo, err error) {
+	m.record("WaitForRestore", ctx, partitionID)
+	if m.WaitForRestoreFunc == nil {
+		err = notStubbed("WaitForRestore")
+		return
+	}
+	return m.WaitForRestoreFunc(ctx, partitionID)
+}
+
+// GetChaos calls GetChaosFunc
+func (m *Mock) GetChaos() (r0 *sf.ChaosInfo, err error) {
+	m.record("GetChaos")
+	if m.GetChaosFunc == nil {
+		err = notStubbed("GetChaos")
+		return
+	}
+	return m.GetChaosFunc()
+}
+
+// GetChaosEvents calls GetChaosEventsFunc
+func (m *Mock) GetChaosEvents(opts sf.ChaosEventsOptions) (r0 []sf.ChaosEvent, err error) {
+	m.record("GetChaosEvents", opts)
+	if m.GetChaosEventsFunc == nil {
+		err = notStubbed("GetChaosEvents")
+		return
+	}
+	return m.GetChaosEventsFunc(opts)
+}
+
+// GetChaosSchedule calls GetChaosScheduleFunc
+func (m *Mock) GetChaosSchedule() (r0 *sf.ChaosScheduleDescription, err error) {
+	m.record("GetChaosSchedule")
+	if m.GetChaosScheduleFunc == nil {
+		err = notStubbed("GetChaosSchedule")
+		return
+	}
+	return m.GetChaosScheduleFunc()
+}
+
+// GetDataLossProgress calls GetDataLossProgressFunc
+func (m *Mock) GetDataLossProgress(serviceID sf.ServiceID, partitionID sf.PartitionID, operationID sf.OperationID) (r0 *sf.PartitionDataLossProgress, err error) {
+	m.record("GetDataLossProgress", serviceID, partitionID, operationID)
+	if m.GetDataLossProgressFunc == nil {
+		err = notStubbed("GetDataLossProgress")
+		return
+	}
+	return m.GetDataLossProgressFunc(serviceID, partitionID, operationID)
+}
+
+// GetQuorumLossProgress calls GetQuorumLossProgressFunc
+func (m *Mock) GetQuorumLossProgress(serviceID sf.ServiceID, partitionID sf.PartitionID, operationID sf.OperationID) (r0 *sf.PartitionQuorumLossProgress, err error) {
+	m.record("GetQuorumLossProgress", serviceID, partitionID, operationID)
+	if m.GetQuorumLossProgressFunc == nil {
+		err = notStubbed("GetQuorumLossProgress")
+		return
+	}
+	return m.GetQuorumLossProgressFunc(serviceID, partitionID, operationID)
+}
+
+// GetPartitionRestartProgress calls GetPartitionRestartProgressFunc
+func (m *Mock) GetPartitionRestartProgress(serviceID sf.ServiceID, partitionID sf.PartitionID, operationID sf.OperationID) (r0 *sf.PartitionRestartProgress, err error) {
+	m.record("GetPartitionRestartProgress", serviceID, partitionID, operationID)
+	if m.GetPartitionRestartProgressFunc == nil {
+		err = notStubbed("GetPartitionRestartProgress")
+		return
+	}
+	return m.GetPartitionRestartProgressFunc(serviceID, partitionID, operationID)
+}
+
+// GetNodeTransitionProgress calls GetNodeTransitionProgressFunc
+func (m *Mock) GetNodeTransitionProgress(nodeName string, operationID sf.OperationID) (r0 *sf.NodeTransitionProgress, err error) {
+	m.record("GetNodeTransitionProgress", nodeName, operationID)
+	if m.GetNodeTransitionProgressFunc == nil {
+		err = notStubbed("GetNodeTransitionProgress")
+		return
+	}
+	return m.GetNodeTransitionProgressFunc(nodeName, operationID)
+}
+
+// GetRepairTaskList calls GetRepairTaskListFunc
+func (m *Mock) GetRepairTaskList(opts sf.RepairTaskListOptions) (r0 []sf.RepairTask, err error) {
+	m.record("GetRepairTaskList", opts)
+	if m.GetRepairTaskListFunc == nil {
+		err = notStubbed("GetRepairTaskList")
+		return
+	}
+	return m.GetRepairTaskListFunc(opts)
+}
+
+// PutProperty calls PutPropertyFunc
+func (m *Mock) PutProperty(name string, propertyName string, value string) (err error) {
+	m.record("PutProperty", name, propertyName, value)
+	if m.PutPropertyFunc == nil {
+		err = notStubbed("PutProperty")
+		return
+	}
+	return m.PutPropertyFunc(name, propertyName, value)
+}
+
+// CreateBackupPolicy calls CreateBackupPolicyFunc
+func (m *Mock) CreateBackupPolicy(policy sf.BackupPolicyDescription) (err error) {
+	m.record("CreateBackupPolicy", policy)
+	if m.CreateBackupPolicyFunc == nil {
+		err = notStubbed("CreateBackupPolicy")
+		return
+	}
+	return m.CreateBackupPolicyFunc(policy)
+}
+
+// EnableApplicationBackup calls EnableApplicationBackupFunc
+func (m *Mock) EnableApplicationBackup(applicationID sf.ApplicationID, policyName string) (err error) {
+	m.record("EnableApplicationBackup", applicationID, policyName)
+	if m.EnableApplicationBackupFunc == nil {
+		err = notStubbed("EnableApplicationBackup")
+		return
+	}
+	return m.EnableApplicationBackupFunc(applicationID, policyName)
+}
+
+// EnableServiceBackup calls EnableServiceBackupFunc
+func (m *Mock) EnableServiceBackup(serviceID sf.ServiceID, policyName string) (err error) {
+	m.record("EnableServiceBackup", serviceID, policyName)
+	if m.EnableServiceBackupFunc == nil {
+		err = notStubbed("EnableServiceBackup")
+		return
+	}
+	return m.EnableServiceBackupFunc(serviceID, policyName)
+}
+
+// EnablePartitionBackup calls EnablePartitionBackupFunc
+func (m *Mock) EnablePartitionBackup(partitionID sf.PartitionID, policyName string) (err error) {
+	m.record("EnablePartitionBackup", partitionID, policyName)
+	if m.EnablePartitionBackupFunc == nil {
+		err = notStubbed("EnablePartitionBackup")
+		return
+	}
+	return m.EnablePartitionBackupFunc(partitionID, policyName)
+}
+
+// BackupPartition calls BackupPartitionFunc
+func (m *Mock) BackupPartition(partitionID sf.PartitionID, opts sf.BackupPartitionOptions) (err error) {
+	m.record("BackupPartition", partitionID, opts)
+	if m.BackupPartitionFunc == nil {
+		err = notStubbed("BackupPartition")
+		return
+	}
+	return m.BackupPartitionFunc(partitionID, opts)
+}
+
+// RestorePartition calls RestorePartitionFunc
+func (m *Mock) RestorePartition(partitionID sf.PartitionID, restore sf.RestorePartitionDescription) (err error) {
+	m.record("RestorePartition", partitionID, restore)
+	if m.RestorePartitionFunc == nil {
+		err = notStubbed("RestorePartition")
+		return
+	}
+	return m.RestorePartitionFunc(partitionID, restore)
+}
+
+// StartChaos calls StartChaosFunc
+func (m *Mock) StartChaos(params sf.ChaosParameters) (err error) {
+	m.record("StartChaos", params)
+	if m.StartChaosFunc == nil {
+		err = notStubbed("StartChaos")
+		return
+	}
+	return m.StartChaosFunc(params)
+}
+
+// StopChaos calls StopChaosFunc
+func (m *Mock) StopChaos() (err error) {
+	m.record("StopChaos")
+	if m.StopChaosFunc == nil {
+		err = notStubbed("StopChaos")
+		return
+	}
+	return m.StopChaosFunc()
+}
+
+// PostChaosSchedule calls PostChaosScheduleFunc
+func (m *Mock) PostChaosSchedule(schedule sf.ChaosScheduleDescription) (err error) {
+	m.record("PostChaosSchedule", schedule)
+	if m.PostChaosScheduleFunc == nil {
+		err = notStubbed("PostChaosSchedule")
+		return
+	}
+	return m.PostChaosScheduleFunc(schedule)
+}
+
+// RunChaos calls RunChaosFunc
+func (m *Mock) RunChaos(ctx context.Context, params sf.ChaosParameters, duration time.Duration) (r0 *sf.ChaosSummary, err error) {
+	m.record("RunChaos", ctx, params, duration)
+	if m.RunChaosFunc == nil {
+		err = notStubbed("RunChaos")
+		return
+	}
+	return m.RunChaosFunc(ctx, params, duration)
+}
+
+// StartDataLoss calls StartDataLossFunc
+func (m *Mock) StartDataLoss(serviceID sf.ServiceID, partitionID sf.PartitionID, mode sf.DataLossMode) (r0 *sf.FaultOperation, err error) {
+	m.record("StartDataLoss", serviceID, partitionID, mode)
+	if m.StartDataLossFunc == nil {
+		err = notStubbed("StartDataLoss")
+		return
+	}
+	return m.StartDataLossFunc(serviceID, partitionID, mode)
+}
+
+// StartQuorumLoss calls StartQuorumLossFunc
+func (m *Mock) StartQuorumLoss(serviceID sf.ServiceID, partitionID sf.PartitionID, mode sf.QuorumLossMode, duration time.Duration) (r0 *sf.FaultOperation, err error) {
+	m.record("StartQuorumLoss", serviceID, partitionID, mode, duration)
+	if m.StartQuorumLossFunc == nil {
+		err = notStubbed("StartQuorumLoss")
+		return
+	}
+	return m.StartQuorumLossFunc(serviceID, partitionID, mode, duration)
+}
+
+// StartPartitionRestart calls StartPartitionRestartFunc
+func (m *Mock) StartPartitionRestart(serviceID sf.ServiceID, partitionID sf.PartitionID, mode sf.RestartPartitionMode) (r0 *sf.FaultOperation, err error) {
+	m.record("StartPartitionRestart", serviceID, partitionID, mode)
+	if m.StartPartitionRestartFunc == nil {
+		err = notStubbed("StartPartitionRestart")
+		return
+	}
+	return m.StartPartitionRestartFunc(serviceID, partitionID, mode)
+}
+
+// StartNodeTransition calls StartNodeTransitionFunc
+func (m *Mock) StartNodeTransition(nodeName string, nodeInstanceID string, transition sf.NodeTransitionType, stopDuration time.Duration) (r0 *sf.FaultOperation, err error) {
+	m.record("StartNodeTransition", nodeName, nodeInstanceID, transition, stopDuration)
+	if m.StartNodeTransitionFunc == nil {
+		err = notStubbed("StartNodeTransition")
+		return
+	}
+	return m.StartNodeTransitionFunc(nodeName, nodeInstanceID, transition, stopDuration)
+}
+
+// CancelOperation calls CancelOperationFunc
+func (m *Mock) CancelOperation(operationID sf.OperationID, force bool) (err error) {
+	m.record("CancelOperation", operationID, force)
+	if m.CancelOperationFunc == nil {
+		err = notStubbed("CancelOperation")
+		return
+	}
+	return m.CancelOperationFunc(operationID, force)
+}
+
+// RestartReplica calls RestartReplicaFunc
+func (m *Mock) RestartReplica(nodeName string, partitionID sf.PartitionID, replicaID string) (err error) {
+	m.record("RestartReplica", nodeName, partitionID, replicaID)
+	if m.RestartReplicaFunc == nil {
+		err = notStubbed("RestartReplica")
+		return
+	}
+	return m.RestartReplicaFunc(nodeName, partitionID, replicaID)
+}
+
+// RemoveReplica calls RemoveReplicaFunc
+func (m *Mock) RemoveReplica(nodeName string, partitionID sf.PartitionID, replicaID string, force bool) (err error) {
+	m.record("RemoveReplica", nodeName, partitionID, replicaID, force)
+	if m.RemoveReplicaFunc == nil {
+		err = notStubbed("RemoveReplica")
+		return
+	}
+	return m.RemoveReplicaFunc(nodeName, partitionID, replicaID, force)
+}
+
+// CreateRepairTask calls CreateRepairTaskFunc
+func (m *Mock) CreateRepairTask(task sf.RepairTask) (r0 string, err error) {
+	m.record("CreateRepairTask", task)
+	if m.CreateRepairTaskFunc == nil {
+		err = notStubbed("CreateRepairTask")
+		return
+	}
+	return m.CreateRepairTaskFunc(task)
+}
+
+// CancelRepairTask calls CancelRepairTaskFunc
+func (m *Mock) CancelRepairTask(taskID string, version string, requestAbort bool) (r0 string, err error) {
+	m.record("CancelRepairTask", taskID, version, requestAbort)
+	if m.CancelRepairTaskFunc == nil {
+		err = notStubbed("CancelRepairTask")
+		return
+	}
+	return m.CancelRepairTaskFunc(taskID, version, requestAbort)
+}
+
+// ForceApproveRepairTask calls ForceApproveRepairTaskFunc
+func (m *Mock) ForceApproveRepairTask(taskID string, version string) (r0 string, err error) {
+	m.record("ForceApproveRepairTask", taskID, version)
+	if m.ForceApproveRepairTaskFunc == nil {
+		err = notStubbed("ForceApproveRepairTask")
+		return
+	}
+	return m.ForceApproveRepairTaskFunc(taskID, version)
+}
+
+// UpdateRepairExecutionState calls UpdateRepairExecutionStateFunc
+func (m *Mock) UpdateRepairExecutionState(task sf.RepairTask) (r0 string, err error) {
+	m.record("UpdateRepairExecutionState", task)
+	if m.UpdateRepairExecutionStateFunc == nil {
+		err = notStubbed("UpdateRepairExecutionState")
+		return
+	}
+	return m.UpdateRepairExecutionStateFunc(task)
+}
+
+// DeleteRepairTask calls DeleteRepairTaskFunc
+func (m *Mock) DeleteRepairTask(taskID string, version string) (err error) {
+	m.record("DeleteRepairTask", taskID, version)
+	if m.DeleteRepairTaskFunc == nil {
+		err = notStubbed("DeleteRepairTask")
+		return
+	}
+	return m.DeleteRepairTaskFunc(taskID, version)
+}
@@ -0,0 +1,84 @@
+package sfmock
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// countApplications stands in for provider code which only
+// depends on the narrow reader it needs
+func countApplications(reader sf.ApplicationReader) (int, error) {
+	apps, err := reader.GetApplications()
+	if err != nil {
+		return 0, err
+	}
+	return len(apps.Items), nil
+}
+
+func TestMockCallsFunc(t *testing.T) {
+	mock := &Mock{
+		GetApplicationsFunc: func() (*sf.ApplicationItemsPage, error) {
+			return &sf.ApplicationItemsPage{Items: []sf.ApplicationItem{{Name: "fabric:/TestApplication"}}}, nil
+		},
+	}
+
+	count, err := countApplications(mock)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Got %d applications, want 1", count)
+	}
+}
+
+func TestMockNotStubbed(t *testing.T) {
+	mock := &Mock{}
+
+	exists, properties, err := mock.GetProperties("TestApplication/TestService")
+	if !errors.Is(err, ErrNotStubbed) {
+		t.Errorf("Got %v, want ErrNotStubbed", err)
+	}
+	if exists || properties != nil {
+		t.Errorf("Got %v %v, want zero values", exists, properties)
+	}
+}
+
+func TestMockRecordsConcurrentCalls(t *testing.T) {
+	mock := &Mock{
+		GetServicesFunc: func(appName string) (*sf.ServiceItemsPage, error) {
+			return &sf.ServiceItemsPage{}, nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = mock.GetServices("TestApplication")
+			_ = mock.StopChaos()
+		}()
+	}
+	wg.Wait()
+
+	if calls := mock.Calls(); len(calls) != 20 {
+		t.Errorf("Got %d calls, want 20", len(calls))
+	}
+	calls := mock.CallsTo("GetServices")
+	if len(calls) != 10 {
+		t.Fatalf("Got %d calls to GetServices, want 10", len(calls))
+	}
+	expected := Call{Method: "GetServices", Args: []interface{}{"TestApplication"}}
+	if !reflect.DeepEqual(calls[0], expected) {
+		t.Errorf("Got %+v, want %+v", calls[0], expected)
+	}
+
+	mock.Reset()
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("Got %+v, want no calls after Reset", calls)
+	}
+}
@@ -0,0 +1,59 @@
+// Package sfmock provides a mock of servicefabric.API, for unit
+// testing code that uses the client without a cluster or HTTP.
+package sfmock
+
+import (
+	"errors"
+	"fmt"
+)
+
+//go:generate go run ../internal/mockgen -source ../api.go -output mock.go
+
+// ErrNotStubbed is returned by a Mock method whose Func is not set
+var ErrNotStubbed = errors.New("mock method not stubbed")
+
+// Call a call made to a Mock
+type Call struct {
+	// Method the name of the method called
+	Method string
+	// Args the arguments of the call in order
+	Args []interface{}
+}
+
+// Calls returns every call made so far, oldest first
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made to method so far, oldest first
+func (m *Mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made so far
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *Mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func notStubbed(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotStubbed, method)
+}
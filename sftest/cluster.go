@@ -0,0 +1,331 @@
+// Package sftest provides an in-memory Service Fabric cluster served
+// over HTTP, for testing code built on the servicefabric client
+// without a real cluster.
+package sftest
+
+import (
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// DefaultClusterVersion the runtime version reported by a new cluster
+const DefaultClusterVersion = "6.4.617.9590"
+
+// Cluster an in-memory Service Fabric cluster. Entities are listed in
+// the order they were added. Every method is safe for concurrent use,
+// including while requests are being served.
+type Cluster struct {
+	server *httptest.Server
+
+	mu             sync.Mutex
+	clusterVersion string
+	pageSize       int
+	latency        time.Duration
+	faults         []*Fault
+	requests       []Request
+
+	applications []*application
+	services     map[string]*service
+	partitions   map[string]*partition
+	serviceTypes map[string][]sf.ServiceType
+	names        map[string]*name
+}
+
+type application struct {
+	item     sf.ApplicationItem
+	services []*service
+}
+
+type service struct {
+	application *application
+	item        sf.ServiceItem
+	partitions  []*partition
+}
+
+type partition struct {
+	service  *service
+	item     sf.PartitionItem
+	replicas []interface{}
+}
+
+type name struct {
+	properties []*sf.Property
+	sequence   int64
+}
+
+// Request a request received by the cluster
+type Request struct {
+	Method string
+	// Path the unescaped request path
+	Path string
+	// RawQuery the encoded query string
+	RawQuery string
+}
+
+// NewCluster starts serving an empty cluster, which must
+// be closed once the test is done with it
+func NewCluster() *Cluster {
+	c := &Cluster{
+		clusterVersion: DefaultClusterVersion,
+		services:       map[string]*service{},
+		partitions:     map[string]*partition{},
+		serviceTypes:   map[string][]sf.ServiceType{},
+		names:          map[string]*name{},
+	}
+	c.server = httptest.NewServer(c)
+	return c
+}
+
+// URL the management endpoint of the cluster
+func (c *Cluster) URL() string {
+	return c.server.URL
+}
+
+// Close shuts down the cluster, blocking until
+// every outstanding request has completed
+func (c *Cluster) Close() {
+	c.server.Close()
+}
+
+// Client returns a client of the cluster
+func (c *Cluster) Client() *sf.Client {
+	// NewClient only fails without an endpoint
+	client, _ := sf.NewClient(c.server.Client(), c.URL(), sf.DefaultAPIVersion, nil)
+	return client
+}
+
+// SetClusterVersion sets the runtime version reported by the cluster
+func (c *Cluster) SetClusterVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clusterVersion = version
+}
+
+// SetPageSize limits every list response to size items, returning a
+// continuation token for the rest. Zero, the default, returns every
+// item unless the request sets MaxResults.
+func (c *Cluster) SetPageSize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pageSize = size
+}
+
+// SetLatency delays every response by latency
+func (c *Cluster) SetLatency(latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latency = latency
+}
+
+// Requests returns the requests received so far, oldest first
+func (c *Cluster) Requests() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Request(nil), c.requests...)
+}
+
+// AddApplication adds an application. An empty ID is derived from
+// the Name or an empty Name from the ID, and an empty HealthState and
+// Status default to Ok and Ready.
+func (c *Cluster) AddApplication(app sf.ApplicationItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if app.ID == "" {
+		app.ID = sf.ApplicationIDFromName(app.Name).String()
+	}
+	if app.Name == "" {
+		app.Name = sf.ApplicationID(app.ID).Name()
+	}
+	app.HealthState = defaultString(app.HealthState, "Ok")
+	app.Status = defaultString(app.Status, "Ready")
+	c.applications = append(c.applications, &application{item: app})
+}
+
+// AddService adds a service to the application named appName, which
+// must already have been added. An empty ID is derived from the Name
+// or an empty Name from the ID, and an empty HealthState and
+// ServiceStatus default to Ok and Active.
+func (c *Cluster) AddService(appName string, svc sf.ServiceItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	app := c.application(appName)
+	if app == nil {
+		panic("sftest: no application " + appName)
+	}
+
+	if svc.ID == "" {
+		svc.ID = sf.ServiceIDFromName(svc.Name).String()
+	}
+	if svc.Name == "" {
+		svc.Name = sf.ServiceID(svc.ID).Name()
+	}
+	svc.HealthState = defaultString(svc.HealthState, "Ok")
+	svc.ServiceStatus = defaultString(svc.ServiceStatus, "Active")
+
+	s := &service{application: app, item: svc}
+	app.services = append(app.services, s)
+	c.services[svc.Name] = s
+}
+
+// AddPartition adds a partition to the service named serviceName,
+// which must already have been added. An empty HealthState and
+// PartitionStatus default to Ok and Ready.
+func (c *Cluster) AddPartition(serviceName string, p sf.PartitionItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	svc := c.service(serviceName)
+	if svc == nil {
+		panic("sftest: no service " + serviceName)
+	}
+
+	p.HealthState = defaultString(p.HealthState, "Ok")
+	p.PartitionStatus = defaultString(p.PartitionStatus, "Ready")
+	if p.ServiceKind == "" {
+		p.ServiceKind = svc.item.ServiceKind
+	}
+
+	added := &partition{service: svc, item: p}
+	svc.partitions = append(svc.partitions, added)
+	c.partitions[p.PartitionInformation.ID] = added
+}
+
+// AddReplica adds a replica of a stateful service to the
+// partition identified by partitionID
+func (c *Cluster) AddReplica(partitionID string, replica sf.ReplicaItem) {
+	c.addReplica(partitionID, replica)
+}
+
+// AddInstance adds an instance of a stateless service to the
+// partition identified by partitionID
+func (c *Cluster) AddInstance(partitionID string, instance sf.InstanceItem) {
+	c.addReplica(partitionID, instance)
+}
+
+func (c *Cluster) addReplica(partitionID string, replica interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.partitions[partitionID]
+	if !ok {
+		panic("sftest: no partition " + partitionID)
+	}
+	p.replicas = append(p.replicas, replica)
+}
+
+// AddServiceType adds a service type to a version of an application type
+func (c *Cluster) AddServiceType(appTypeName, appTypeVersion string, serviceType sf.ServiceType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := serviceTypesKey(appTypeName, appTypeVersion)
+	c.serviceTypes[key] = append(c.serviceTypes[key], serviceType)
+}
+
+// SetProperty sets a string property of a name, creating the name
+// if it does not exist yet. Names may be given with or without the
+// fabric:/ scheme and in id form.
+func (c *Cluster) SetProperty(nameID, propertyName, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.putProperty(canonicalName(nameID), propertyName, sf.PropValue{Kind: "String", Data: value}, "")
+}
+
+// Property returns the value of a property of a name and
+// whether it exists
+func (c *Cluster) Property(nameID, propertyName string) (sf.PropValue, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.names[canonicalName(nameID)]
+	if n == nil {
+		return sf.PropValue{}, false
+	}
+	for _, property := range n.properties {
+		if property.Name == propertyName {
+			return property.Value, true
+		}
+	}
+	return sf.PropValue{}, false
+}
+
+func (c *Cluster) putProperty(fullName, propertyName string, value sf.PropValue, customTypeID string) {
+	n := c.names[fullName]
+	if n == nil {
+		n = &name{}
+		c.names[fullName] = n
+	}
+	n.sequence++
+
+	property := &sf.Property{
+		Name:  propertyName,
+		Value: value,
+		Metadata: sf.Metadata{
+			CustomTypeID:             customTypeID,
+			LastModifiedUtcTimestamp: time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+			Parent:                   fullName,
+			SequenceNumber:           strconv.FormatInt(n.sequence, 10),
+			SizeInBytes:              int64(len(value.Data)),
+			TypeID:                   value.Kind,
+		},
+	}
+	for i, existing := range n.properties {
+		if existing.Name == propertyName {
+			n.properties[i] = property
+			return
+		}
+	}
+	n.properties = append(n.properties, property)
+	sort.SliceStable(n.properties, func(i, j int) bool {
+		return n.properties[i].Name < n.properties[j].Name
+	})
+}
+
+func (c *Cluster) application(nameOrID string) *application {
+	fullName := canonicalName(nameOrID)
+	for _, app := range c.applications {
+		if app.item.Name == fullName {
+			return app
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) service(nameOrID string) *service {
+	return c.services[canonicalName(nameOrID)]
+}
+
+// nameExists reports whether a name has been created, either
+// by an application or service or by setting a property
+func (c *Cluster) nameExists(fullName string) bool {
+	if _, ok := c.names[fullName]; ok {
+		return true
+	}
+	return c.application(fullName) != nil || c.service(fullName) != nil
+}
+
+// canonicalName returns the fabric:/ name for a name or id, accepting
+// the / separated and ~ separated forms used by the client
+func canonicalName(nameOrID string) string {
+	id := strings.TrimPrefix(nameOrID, "fabric:/")
+	return "fabric:/" + strings.Replace(id, "~", "/", -1)
+}
+
+func serviceTypesKey(appTypeName, appTypeVersion string) string {
+	return appTypeName + "\x00" + appTypeVersion
+}
+
+func defaultString(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
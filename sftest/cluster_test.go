@@ -0,0 +1,198 @@
+package sftest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+const testPartitionID = "bce46a8c-b62d-4996-89dc-7ffc00a96902"
+
+func newTestCluster() *Cluster {
+	cluster := NewCluster()
+	cluster.AddApplication(sf.ApplicationItem{Name: "fabric:/TestApplication", TypeName: "TestApplicationType", TypeVersion: "1.0.0"})
+	cluster.AddApplication(sf.ApplicationItem{Name: "fabric:/OtherApplication", TypeName: "OtherApplicationType", TypeVersion: "1.0.0"})
+	cluster.AddApplication(sf.ApplicationItem{ID: "ThirdApplication", TypeName: "OtherApplicationType", TypeVersion: "1.0.0"})
+	cluster.AddService("fabric:/TestApplication", sf.ServiceItem{Name: "fabric:/TestApplication/TestService", ServiceKind: "Stateful", TypeName: "TestServiceType"})
+	cluster.AddPartition("fabric:/TestApplication/TestService", sf.PartitionItem{
+		PartitionInformation: sf.PartitionInformation{ID: testPartitionID, ServicePartitionKind: "Singleton"},
+	})
+	cluster.AddReplica(testPartitionID, sf.ReplicaItem{
+		ID:              "131496928082309293",
+		ReplicaItemBase: &sf.ReplicaItemBase{NodeName: "_Node_0", ReplicaRole: "Primary", ReplicaStatus: "Ready", HealthState: "Ok"},
+	})
+	cluster.AddServiceType("TestApplicationType", "1.0.0", sf.ServiceType{
+		ServiceManifestName:    "TestServicePkg",
+		ServiceTypeDescription: sf.ServiceTypeDescription{ServiceTypeName: "TestServiceType", IsStateful: true},
+	})
+	return cluster
+}
+
+func TestClusterPagesApplications(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.SetPageSize(1)
+
+	apps, err := cluster.Client().GetApplications()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	var names []string
+	for _, app := range apps.Items {
+		names = append(names, app.Name)
+	}
+	expected := []string{"fabric:/TestApplication", "fabric:/OtherApplication", "fabric:/ThirdApplication"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Got %v, want %v", names, expected)
+	}
+	if requests := cluster.Requests(); len(requests) != 3 || requests[2].RawQuery != "api-version=3.0&ContinuationToken=2" {
+		t.Errorf("Got %+v, want three paged requests", requests)
+	}
+
+	filtered, err := cluster.Client().GetApplicationsWithOptions(sf.ApplicationsOptions{ApplicationTypeName: "OtherApplicationType"})
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(filtered.Items) != 2 {
+		t.Errorf("Got %+v, want the two applications of OtherApplicationType", filtered.Items)
+	}
+}
+
+func TestClusterServesTopology(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	client := cluster.Client()
+
+	services, err := client.GetServices("TestApplication")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(services.Items) != 1 || services.Items[0].ID != "TestApplication~TestService" {
+		t.Fatalf("Got %+v, want TestService", services.Items)
+	}
+
+	partitions, err := client.GetPartitions("TestApplication", "TestApplication/TestService")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(partitions.Items) != 1 || partitions.Items[0].ServiceKind != "Stateful" || partitions.Items[0].PartitionStatus != "Ready" {
+		t.Fatalf("Got %+v, want a ready stateful partition", partitions.Items)
+	}
+
+	replicas, err := client.GetReplicas("TestApplication", "TestApplication/TestService", testPartitionID)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(replicas.Items) != 1 || replicas.Items[0].ID != "131496928082309293" || replicas.Items[0].NodeName != "_Node_0" {
+		t.Errorf("Got %+v, want the primary replica", replicas.Items)
+	}
+
+	serviceName, err := client.GetPartitionServiceName(sf.PartitionID(testPartitionID))
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	expectedName := &sf.NameInfo{ID: "TestApplication~TestService", Name: "fabric:/TestApplication/TestService"}
+	if !reflect.DeepEqual(serviceName, expectedName) {
+		t.Errorf("Got %+v, want %+v", serviceName, expectedName)
+	}
+
+	serviceTypes, err := client.GetServiceTypes("TestApplicationType", "1.0.0")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if len(serviceTypes) != 1 || serviceTypes[0].ServiceTypeDescription.ServiceTypeName != "TestServiceType" {
+		t.Errorf("Got %+v, want TestServiceType", serviceTypes)
+	}
+
+	if _, err := client.GetServices("TestApplicationNonExistent"); err == nil {
+		t.Error("Expected an error listing the services of an unknown application")
+	}
+}
+
+func TestClusterProperties(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.SetPageSize(1)
+	cluster.SetProperty("TestApplication~TestService", "traefik.enable", "true")
+	cluster.SetProperty("fabric:/TestApplication/TestService", "traefik.frontend.rule", "Host:example.com")
+
+	exists, properties, err := cluster.Client().GetProperties("TestApplication/TestService")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	expected := map[string]string{"traefik.enable": "true", "traefik.frontend.rule": "Host:example.com"}
+	if !exists || !reflect.DeepEqual(properties, expected) {
+		t.Errorf("Got %v %v, want %v", exists, properties, expected)
+	}
+
+	exists, _, err = cluster.Client().GetProperties("TestApplication/MissingService")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if exists {
+		t.Error("Expected an unknown name not to exist")
+	}
+}
+
+func TestClusterPutProperty(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+
+	body := strings.NewReader(`{"PropertyName":"weight","Value":{"Kind":"Int64","Data":"10"}}`)
+	req, _ := http.NewRequest(http.MethodPut, cluster.URL()+"/Names/NewName/$/GetProperty?api-version=6.0", body)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Got status %d, want 200", res.StatusCode)
+	}
+
+	value, ok := cluster.Property("fabric:/NewName", "weight")
+	if !ok || value != (sf.PropValue{Kind: "Int64", Data: "10"}) {
+		t.Errorf("Got %+v %v, want the Int64 property", value, ok)
+	}
+}
+
+func TestClusterInjectFault(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.InjectFault(Fault{Path: "GetServices", StatusCode: http.StatusServiceUnavailable, ErrorCode: "FABRIC_E_SERVICE_OFFLINE", Times: 1})
+
+	client := cluster.Client()
+	if _, err := client.GetApplications(); err != nil {
+		t.Fatalf("Expected requests not matching the fault to succeed %v", err)
+	}
+	_, err := client.GetServices("TestApplication")
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Got %v, want the injected 503", err)
+	}
+	if _, err := client.GetServices("TestApplication"); err != nil {
+		t.Errorf("Expected the fault to clear after one request %v", err)
+	}
+}
+
+func TestClusterLatency(t *testing.T) {
+	cluster := newTestCluster()
+	defer cluster.Close()
+	cluster.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := cluster.Client().WithContext(ctx).GetApplications()
+	if err == nil {
+		t.Fatal("Expected the request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Got %v, want the request to give up at the deadline", elapsed)
+	}
+}
@@ -0,0 +1,476 @@
+package sftest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	sf "github.com/jjcollinge/servicefabric"
+)
+
+// Service Fabric error codes returned by the cluster
+const (
+	ErrorCodeInvalidArgument         = "FABRIC_E_INVALID_ARGUMENT"
+	ErrorCodeApplicationNotFound     = "FABRIC_E_APPLICATION_NOT_FOUND"
+	ErrorCodeApplicationTypeNotFound = "FABRIC_E_APPLICATION_TYPE_NOT_FOUND"
+	ErrorCodeServiceDoesNotExist     = "FABRIC_E_SERVICE_DOES_NOT_EXIST"
+	ErrorCodePartitionNotFound       = "FABRIC_E_PARTITION_NOT_FOUND"
+	ErrorCodeNameDoesNotExist        = "FABRIC_E_NAME_DOES_NOT_EXIST"
+	ErrorCodePropertyDoesNotExist    = "FABRIC_E_PROPERTY_DOES_NOT_EXIST"
+)
+
+// Fault makes the cluster fail the requests it matches
+type Fault struct {
+	// Method matches the request method, empty matches every method
+	Method string
+	// Path matches requests whose unescaped path contains Path,
+	// empty matches every path
+	Path string
+	// StatusCode the status code of the failed responses,
+	// zero fails with 500 Internal Server Error
+	StatusCode int
+	// ErrorCode the Service Fabric error code in the failed
+	// responses, for example FABRIC_E_TIMEOUT
+	ErrorCode string
+	// Times the number of requests to fail, zero fails every match
+	Times int
+}
+
+// InjectFault fails the requests matched by fault until it has failed
+// fault.Times requests. Faults are matched in the order they were
+// injected.
+func (c *Cluster) InjectFault(fault Fault) {
+	if fault.StatusCode == 0 {
+		fault.StatusCode = http.StatusInternalServerError
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faults = append(c.faults, &fault)
+}
+
+// ClearFaults removes every injected fault
+func (c *Cluster) ClearFaults() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faults = nil
+}
+
+// matchFault returns the first fault matching r and counts it
+// against the fault's remaining Times
+func (c *Cluster) matchFault(r *http.Request) *Fault {
+	for i, fault := range c.faults {
+		if fault.Method != "" && fault.Method != r.Method {
+			continue
+		}
+		if !strings.Contains(r.URL.Path, fault.Path) {
+			continue
+		}
+
+		matched := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				c.faults = append(c.faults[:i], c.faults[i+1:]...)
+			}
+		}
+		return &matched
+	}
+	return nil
+}
+
+// errorResponse encapsulates the error model
+// returned by the Service Fabric API
+type errorResponse struct {
+	Error sf.FabricErrorDetail `json:"Error"`
+}
+
+// statusError an error response returned by a route
+type statusError struct {
+	statusCode int
+	code       string
+	message    string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+func notFound(code, format string, args ...interface{}) error {
+	return &statusError{statusCode: http.StatusNotFound, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &statusError{statusCode: http.StatusBadRequest, code: ErrorCodeInvalidArgument, message: fmt.Sprintf(format, args...)}
+}
+
+// ServeHTTP serves the Service Fabric REST API for the cluster
+func (c *Cluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.requests = append(c.requests, Request{Method: r.Method, Path: r.URL.Path, RawQuery: r.URL.RawQuery})
+	latency := c.latency
+	fault := c.matchFault(r)
+	c.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	if fault != nil {
+		message := fmt.Sprintf("injected fault for %s %s", r.Method, r.URL.Path)
+		writeError(w, &statusError{statusCode: fault.StatusCode, code: fault.ErrorCode, message: message})
+		return
+	}
+
+	if r.URL.Query().Get("api-version") == "" {
+		writeError(w, badRequest("api-version is required"))
+		return
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, badRequest("could not read request body: %v", err))
+			return
+		}
+	}
+
+	c.mu.Lock()
+	statusCode, value, err := c.route(r, body)
+	c.mu.Unlock()
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, statusCode, value)
+}
+
+// route dispatches a request by the segments of its path, which are
+// separated by /$/ in the Service Fabric API
+func (c *Cluster) route(r *http.Request, body []byte) (int, interface{}, error) {
+	segments := strings.Split(strings.TrimSuffix(r.URL.Path, "/"), "/$/")
+	segments[0] = strings.TrimPrefix(segments[0], "/")
+	query := r.URL.Query()
+
+	collection, id := splitSegment(segments[0])
+	switch {
+	case collection == "" && len(segments) == 2 && segments[1] == "GetClusterVersion":
+		return http.StatusOK, sf.ClusterVersion{Version: c.clusterVersion}, nil
+	case collection == "Applications":
+		return c.routeApplications(id, segments[1:], query)
+	case collection == "Services" && len(segments) == 2:
+		return c.routeServices(id, segments[1], query)
+	case collection == "Partitions":
+		return c.routePartitions(id, segments[1:], query)
+	case collection == "ApplicationTypes" && len(segments) == 2 && segments[1] == "GetServiceTypes":
+		serviceTypes, ok := c.serviceTypes[serviceTypesKey(id, query.Get("ApplicationTypeVersion"))]
+		if !ok {
+			return 0, nil, notFound(ErrorCodeApplicationTypeNotFound, "application type %s version %s not found", id, query.Get("ApplicationTypeVersion"))
+		}
+		return http.StatusOK, serviceTypes, nil
+	case collection == "Names" && id != "":
+		return c.routeNames(r.Method, canonicalName(id), segments[1:], query, body)
+	}
+	return 0, nil, notFound("", "no route for %s %s", r.Method, r.URL.Path)
+}
+
+func (c *Cluster) routeApplications(appID string, segments []string, query url.Values) (int, interface{}, error) {
+	if appID == "" {
+		if len(segments) != 0 {
+			return 0, nil, notFound("", "unsupported application call")
+		}
+		var items []interface{}
+		for _, app := range c.applications {
+			if typeName := query.Get("ApplicationTypeName"); typeName != "" && app.item.TypeName != typeName {
+				continue
+			}
+			item := app.item
+			if query.Get("ExcludeApplicationParameters") == "true" {
+				item.Parameters = nil
+			}
+			items = append(items, item)
+		}
+		return c.page(items, query)
+	}
+
+	app := c.application(appID)
+	if app == nil {
+		return 0, nil, notFound(ErrorCodeApplicationNotFound, "application %s not found", appID)
+	}
+	if len(segments) == 0 {
+		return http.StatusOK, app.item, nil
+	}
+
+	call, serviceID := splitSegment(segments[0])
+	if call != "GetServices" {
+		return 0, nil, notFound("", "unsupported application call %s", call)
+	}
+	if serviceID == "" {
+		if len(segments) != 1 {
+			return 0, nil, notFound("", "unsupported service call")
+		}
+		var items []interface{}
+		for _, svc := range app.services {
+			if typeName := query.Get("ServiceTypeName"); typeName != "" && svc.item.TypeName != typeName {
+				continue
+			}
+			items = append(items, svc.item)
+		}
+		return c.page(items, query)
+	}
+
+	svc := c.service(serviceID)
+	if svc == nil || svc.application != app {
+		return 0, nil, notFound(ErrorCodeServiceDoesNotExist, "service %s not found", serviceID)
+	}
+	if len(segments) == 1 {
+		return http.StatusOK, svc.item, nil
+	}
+
+	call, partitionID := splitSegment(segments[1])
+	if call != "GetPartitions" {
+		return 0, nil, notFound("", "unsupported service call %s", call)
+	}
+	if partitionID == "" && len(segments) == 2 {
+		return c.pagePartitions(svc, query)
+	}
+
+	p := c.partitions[partitionID]
+	if p == nil || p.service != svc {
+		return 0, nil, notFound(ErrorCodePartitionNotFound, "partition %s not found", partitionID)
+	}
+	switch {
+	case len(segments) == 2:
+		return http.StatusOK, p.item, nil
+	case len(segments) == 3 && segments[2] == "GetReplicas":
+		return c.page(p.replicas, query)
+	}
+	return 0, nil, notFound("", "unsupported partition call")
+}
+
+func (c *Cluster) routeServices(serviceID, call string, query url.Values) (int, interface{}, error) {
+	svc := c.service(serviceID)
+	if svc == nil {
+		return 0, nil, notFound(ErrorCodeServiceDoesNotExist, "service %s not found", serviceID)
+	}
+
+	switch call {
+	case "GetPartitions":
+		return c.pagePartitions(svc, query)
+	case "GetApplicationName":
+		return http.StatusOK, sf.NameInfo{ID: svc.application.item.ID, Name: svc.application.item.Name}, nil
+	}
+	return 0, nil, notFound("", "unsupported service call %s", call)
+}
+
+func (c *Cluster) routePartitions(partitionID string, segments []string, query url.Values) (int, interface{}, error) {
+	p := c.partitions[partitionID]
+	if p == nil {
+		return 0, nil, notFound(ErrorCodePartitionNotFound, "partition %s not found", partitionID)
+	}
+
+	switch {
+	case len(segments) == 0:
+		return http.StatusOK, p.item, nil
+	case len(segments) == 1 && segments[0] == "GetServiceName":
+		return http.StatusOK, sf.NameInfo{ID: p.service.item.ID, Name: p.service.item.Name}, nil
+	case len(segments) == 1 && segments[0] == "GetReplicas":
+		return c.page(p.replicas, query)
+	}
+	return 0, nil, notFound("", "unsupported partition call")
+}
+
+func (c *Cluster) routeNames(method, fullName string, segments []string, query url.Values, body []byte) (int, interface{}, error) {
+	if !c.nameExists(fullName) && method != http.MethodPut {
+		return 0, nil, notFound(ErrorCodeNameDoesNotExist, "name %s does not exist", fullName)
+	}
+
+	switch {
+	case len(segments) == 0 && method == http.MethodGet:
+		return http.StatusOK, nil, nil
+	case len(segments) == 1 && segments[0] == "GetProperties" && method == http.MethodGet:
+		return c.pageProperties(fullName, query)
+	case len(segments) == 1 && segments[0] == "GetProperty":
+		return c.routeProperty(method, fullName, query, body)
+	}
+	return 0, nil, notFound("", "unsupported name call")
+}
+
+func (c *Cluster) routeProperty(method, fullName string, query url.Values, body []byte) (int, interface{}, error) {
+	if method == http.MethodPut {
+		var description struct {
+			PropertyName string       `json:"PropertyName"`
+			Value        sf.PropValue `json:"Value"`
+			CustomTypeID string       `json:"CustomTypeId"`
+		}
+		if err := json.Unmarshal(body, &description); err != nil {
+			return 0, nil, badRequest("could not deserialise property description: %v", err)
+		}
+		if description.PropertyName == "" {
+			return 0, nil, badRequest("PropertyName is required")
+		}
+		c.putProperty(fullName, description.PropertyName, description.Value, description.CustomTypeID)
+		return http.StatusOK, nil, nil
+	}
+
+	propertyName := query.Get("PropertyName")
+	n := c.names[fullName]
+	index := -1
+	if n != nil {
+		for i, property := range n.properties {
+			if property.Name == propertyName {
+				index = i
+			}
+		}
+	}
+	if index < 0 {
+		return 0, nil, notFound(ErrorCodePropertyDoesNotExist, "property %s of %s does not exist", propertyName, fullName)
+	}
+
+	switch method {
+	case http.MethodGet:
+		return http.StatusOK, n.properties[index], nil
+	case http.MethodDelete:
+		n.properties = append(n.properties[:index], n.properties[index+1:]...)
+		return http.StatusOK, nil, nil
+	}
+	return 0, nil, notFound("", "unsupported property call")
+}
+
+func (c *Cluster) pagePartitions(svc *service, query url.Values) (int, interface{}, error) {
+	items := make([]interface{}, 0, len(svc.partitions))
+	for _, p := range svc.partitions {
+		items = append(items, p.item)
+	}
+	return c.page(items, query)
+}
+
+func (c *Cluster) pageProperties(fullName string, query url.Values) (int, interface{}, error) {
+	var items []interface{}
+	if n := c.names[fullName]; n != nil {
+		for _, property := range n.properties {
+			item := *property
+			if query.Get("IncludeValues") != "true" {
+				item.Value = sf.PropValue{}
+			}
+			items = append(items, item)
+		}
+	}
+
+	start, end, err := c.pageBounds(len(items), query)
+	if err != nil {
+		return 0, nil, err
+	}
+	page := struct {
+		ContinuationToken string        `json:"ContinuationToken"`
+		IsConsistent      bool          `json:"IsConsistent"`
+		Properties        []interface{} `json:"Properties"`
+	}{continuationToken(end, len(items)), true, nonNil(items[start:end])}
+	return http.StatusOK, page, nil
+}
+
+// page returns the page of items selected by the continuation
+// token and page size of the request
+func (c *Cluster) page(items []interface{}, query url.Values) (int, interface{}, error) {
+	start, end, err := c.pageBounds(len(items), query)
+	if err != nil {
+		return 0, nil, err
+	}
+	page := struct {
+		ContinuationToken string        `json:"ContinuationToken"`
+		Items             []interface{} `json:"Items"`
+	}{continuationToken(end, len(items)), nonNil(items[start:end])}
+	return http.StatusOK, page, nil
+}
+
+// pageBounds returns the range of the page requested by query. The
+// continuation token is the offset of the first item of the page.
+func (c *Cluster) pageBounds(count int, query url.Values) (int, int, error) {
+	start := 0
+	if token := query.Get("ContinuationToken"); token != "" {
+		var err error
+		start, err = strconv.Atoi(token)
+		if err != nil || start < 0 || start > count {
+			return 0, 0, badRequest("invalid continuation token %s", token)
+		}
+	}
+
+	size := c.pageSize
+	if maxResults := query.Get("MaxResults"); maxResults != "" {
+		requested, err := strconv.Atoi(maxResults)
+		if err != nil || requested < 0 {
+			return 0, 0, badRequest("invalid MaxResults %s", maxResults)
+		}
+		if requested > 0 && (size == 0 || requested < size) {
+			size = requested
+		}
+	}
+
+	end := count
+	if size > 0 && start+size < count {
+		end = start + size
+	}
+	return start, end, nil
+}
+
+func continuationToken(end, count int) string {
+	if end >= count {
+		return ""
+	}
+	return strconv.Itoa(end)
+}
+
+// splitSegment splits a path segment such as Applications/App into
+// its collection and the id that follows, which may contain /
+func splitSegment(segment string) (string, string) {
+	parts := strings.SplitN(segment, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func nonNil(items []interface{}) []interface{} {
+	if items == nil {
+		return []interface{}{}
+	}
+	return items
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	statusErr, ok := err.(*statusError)
+	if !ok {
+		statusErr = &statusError{statusCode: http.StatusInternalServerError, message: err.Error()}
+	}
+	writeJSON(w, statusErr.statusCode, errorResponse{Error: sf.FabricErrorDetail{Code: statusErr.code, Message: statusErr.message}})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	if value == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,406 @@
+package sftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder records or replays
+type Mode int
+
+// Recorder modes
+const (
+	// ModeAuto replays the cassette if it exists and records it otherwise
+	ModeAuto Mode = iota
+	// ModeRecord sends every request and records it, replacing the cassette
+	ModeRecord
+	// ModeReplay answers every request from the cassette
+	ModeReplay
+)
+
+// ErrNoInteraction is returned when replaying a request
+// which the cassette has no unused recording of
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// redacted replaces redacted header values and matches
+const redacted = "REDACTED"
+
+// Redaction replaces sensitive values in recorded requests and responses
+type Redaction struct {
+	// Pattern matches the values to redact
+	Pattern *regexp.Regexp
+	// Replacement replaces each match
+	Replacement string
+	// Replace returns the replacement of each match instead of
+	// Replacement, it must always return the same replacement for
+	// the same match so replayed requests can be matched
+	Replace func(match string) string
+}
+
+func (r Redaction) apply(s string) string {
+	if r.Replace != nil {
+		return r.Pattern.ReplaceAllStringFunc(s, r.Replace)
+	}
+	return r.Pattern.ReplaceAllLiteralString(s, r.Replacement)
+}
+
+// Built in redactions
+var (
+	// RedactCertificates replaces PEM blocks, base64 encoded
+	// certificates and certificate thumbprints
+	RedactCertificates = Redaction{
+		Pattern:     regexp.MustCompile(`-----BEGIN [A-Z ]+-----[^-]*-----END [A-Z ]+-----|\bMII[A-Za-z0-9+/]{60,}={0,2}|\b[0-9A-Fa-f]{40}\b`),
+		Replacement: redacted,
+	}
+	// RedactTokens replaces bearer tokens and JSON web tokens
+	RedactTokens = Redaction{
+		Pattern:     regexp.MustCompile(`Bearer [A-Za-z0-9._~+/-]+=*|\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		Replacement: redacted,
+	}
+	// RedactNodeIPs replaces the IPv4 addresses of nodes, such as those
+	// in replica endpoints, with addresses in 10.0.0.0/8. Only addresses
+	// after a URL scheme, followed by a port or given as a node's
+	// IpAddressOrFQDN are replaced, leaving four part versions alone.
+	// Each address is always replaced by the same address so distinct
+	// nodes stay distinct.
+	RedactNodeIPs = Redaction{
+		Pattern: regexp.MustCompile(`:(?:\\*/){2}` + ipv4 + `\b|\b` + ipv4 + `:[0-9]+\b|"IpAddressOrFQDN\\*"\s*:\s*\\*"` + ipv4 + `\b`),
+		Replace: func(match string) string {
+			return ipv4Pattern.ReplaceAllStringFunc(match, redactNodeIP)
+		},
+	}
+)
+
+// ipv4 matches an IPv4 address
+const ipv4 = `(?:(?:25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])`
+
+var ipv4Pattern = regexp.MustCompile(`\b` + ipv4 + `\b`)
+
+// redactNodeIP returns the address in 10.0.0.0/8 replacing ip
+func redactNodeIP(ip string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ip))
+	sum := h.Sum32()
+	return fmt.Sprintf("10.%d.%d.%d", byte(sum>>16), byte(sum>>8), byte(sum))
+}
+
+// DefaultRedactions are applied by a new Recorder
+var DefaultRedactions = []Redaction{RedactCertificates, RedactTokens, RedactNodeIPs}
+
+// DefaultRedactedHeaders are the headers whose values
+// a new Recorder replaces
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+// DefaultIgnoredQueryParams are the query parameters a new Recorder
+// ignores when matching requests. The client sets timeout from the
+// deadline of its context, which varies from run to run.
+var DefaultIgnoredQueryParams = []string{"timeout"}
+
+// Cassette the interactions recorded by a Recorder
+type Cassette struct {
+	Interactions []Interaction `json:"Interactions"`
+}
+
+// Interaction a recorded request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"Request"`
+	Response RecordedResponse `json:"Response"`
+}
+
+// RecordedRequest a recorded request
+type RecordedRequest struct {
+	Method string `json:"Method"`
+	// URL the path and query of the request, the cluster's
+	// endpoint is left out of the recording
+	URL    string      `json:"URL"`
+	Header http.Header `json:"Header,omitempty"`
+	Body   string      `json:"Body,omitempty"`
+}
+
+// RecordedResponse a recorded response
+type RecordedResponse struct {
+	StatusCode int         `json:"StatusCode"`
+	Header     http.Header `json:"Header,omitempty"`
+	Body       string      `json:"Body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper which records requests and their
+// responses to a cassette file, and replays them from the cassette so
+// tests written against a real cluster can run without one. Recorded
+// values are redacted before they are saved.
+//
+// The client replaces the transport of its http.Client when it is
+// given a TLS config, so when recording from a secure cluster the TLS
+// config belongs in Transport instead.
+type Recorder struct {
+	// Path the cassette file
+	Path string
+	// Mode whether to record or replay
+	Mode Mode
+	// Transport sends requests while recording,
+	// nil uses http.DefaultTransport
+	Transport http.RoundTripper
+	// Redactions are applied to the recorded URLs, bodies and
+	// header values, and to replayed requests before matching
+	Redactions []Redaction
+	// RedactedHeaders the headers whose recorded values are replaced
+	RedactedHeaders []string
+	// IgnoredQueryParams the query parameters ignored when
+	// matching replayed requests
+	IgnoredQueryParams []string
+
+	mu        sync.Mutex
+	started   bool
+	recording bool
+	cassette  Cassette
+	replayed  []bool
+}
+
+// NewRecorder returns a recorder of the cassette at path using the
+// default redactions
+func NewRecorder(path string, mode Mode) *Recorder {
+	return &Recorder{
+		Path:               path,
+		Mode:               mode,
+		Redactions:         DefaultRedactions,
+		RedactedHeaders:    DefaultRedactedHeaders,
+		IgnoredQueryParams: DefaultIgnoredQueryParams,
+	}
+}
+
+// Recording reports whether the recorder is recording, which in
+// ModeAuto depends on whether the cassette existed when the first
+// request was made
+func (r *Recorder) Recording() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.start(); err != nil {
+		return false, err
+	}
+	return r.recording, nil
+}
+
+// RoundTrip records or replays a single request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	err := r.start()
+	recording := r.recording
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read request body: %v", err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if recording {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+// Save writes the recorded interactions to the cassette file,
+// it does nothing when replaying
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started || !r.recording {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialise cassette: %+v", err)
+	}
+	if err := ioutil.WriteFile(r.Path, data, 0644); err != nil {
+		return fmt.Errorf("could not write cassette %s: %v", r.Path, err)
+	}
+	return nil
+}
+
+// start decides whether to record and loads the cassette to replay
+func (r *Recorder) start() error {
+	if r.started {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(r.Path)
+	switch {
+	case r.Mode == ModeRecord, r.Mode == ModeAuto && os.IsNotExist(err):
+		r.recording = true
+	case err != nil:
+		return fmt.Errorf("could not read cassette %s: %v", r.Path, err)
+	default:
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return fmt.Errorf("could not deserialise cassette %s: %+v", r.Path, err)
+		}
+		r.replayed = make([]bool, len(r.cassette.Interactions))
+	}
+	r.started = true
+	return nil
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resBody []byte
+	if res.Body != nil {
+		resBody, err = ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read response body: %v", err)
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    r.redact(req.URL.RequestURI()),
+			Header: r.redactHeader(req.Header),
+			Body:   r.redact(string(body)),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     r.redactHeader(res.Header),
+			Body:       r.redact(string(resBody)),
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return res, nil
+}
+
+// replay answers a request with the first interaction not yet replayed
+// whose method, URL and body match, so repeated requests such as polls
+// are answered in the order they were recorded
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	uri := r.matchURL(r.redact(req.URL.RequestURI()))
+	redactedBody := r.redact(string(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.replayed[i] || interaction.Request.Method != req.Method {
+			continue
+		}
+		if r.matchURL(interaction.Request.URL) != uri || interaction.Request.Body != redactedBody {
+			continue
+		}
+
+		r.replayed[i] = true
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        cloneHeader(recorded.Header),
+			Body:          ioutil.NopCloser(strings.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, uri)
+}
+
+func (r *Recorder) redact(s string) string {
+	for _, redaction := range r.Redactions {
+		s = redaction.apply(s)
+	}
+	return s
+}
+
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	redactedHeader := http.Header{}
+	for key, values := range header {
+		for _, value := range values {
+			if r.isRedactedHeader(key) {
+				value = redacted
+			}
+			redactedHeader.Add(key, r.redact(value))
+		}
+	}
+	return redactedHeader
+}
+
+func (r *Recorder) isRedactedHeader(key string) bool {
+	for _, redactedKey := range r.RedactedHeaders {
+		if strings.EqualFold(key, redactedKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchURL returns the request URI without the ignored query
+// parameters and with the rest sorted, for matching requests
+func (r *Recorder) matchURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+
+	query := u.Query()
+	for _, param := range r.IgnoredQueryParams {
+		query.Del(param)
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	if len(params) == 0 {
+		return u.EscapedPath()
+	}
+	return u.EscapedPath() + "?" + strings.Join(params, "&")
+}
+
+func cloneHeader(header http.Header) http.Header {
+	cloned := http.Header{}
+	for key, values := range header {
+		cloned[key] = append([]string(nil), values...)
+	}
+	return cloned
+}
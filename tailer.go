@@ -0,0 +1,270 @@
+package servicefabric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	defaultTailInterval  = 5 * time.Second
+	defaultTailOverlap   = time.Minute
+	defaultTailMaxWindow = time.Hour
+)
+
+// EventQuery returns the events within the time window of opts,
+// for example Client.GetClusterEvents on a client bound to ctx with
+// Client.WithContext, so that stopping the tailer cancels the query
+type EventQuery func(ctx context.Context, opts EventsOptions) ([]Event, error)
+
+// EventCheckpoint records how far an EventTailer has read so
+// that tailing can resume where it left off
+type EventCheckpoint struct {
+	// Time every event before Time minus the overlap has been emitted
+	Time time.Time `json:"Time"`
+	// Seen the time stamps of the events already emitted within
+	// the overlap, keyed by EventInstanceId
+	Seen map[string]time.Time `json:"Seen"`
+}
+
+// CheckpointStore persists the checkpoint of an EventTailer
+type CheckpointStore interface {
+	// Load returns the last saved checkpoint, or nil if there is none
+	Load() (*EventCheckpoint, error)
+	// Save replaces the saved checkpoint
+	Save(checkpoint *EventCheckpoint) error
+}
+
+// FileCheckpointStore saves checkpoints as JSON to a file
+type FileCheckpointStore struct {
+	// Path the file the checkpoint is saved to
+	Path string
+}
+
+// NewFileCheckpointStore returns a checkpoint store saving to path
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{Path: path}
+}
+
+// Load returns the checkpoint saved to the file,
+// or nil if the file does not exist yet
+func (s *FileCheckpointStore) Load() (*EventCheckpoint, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint %s: %v", s.Path, err)
+	}
+
+	var checkpoint EventCheckpoint
+	err = json.Unmarshal(data, &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise checkpoint %s: %+v", s.Path, err)
+	}
+	return &checkpoint, nil
+}
+
+// Save writes the checkpoint to a temporary file which then
+// replaces the file, so a crash never leaves it half written
+func (s *FileCheckpointStore) Save(checkpoint *EventCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("could not serialise checkpoint: %+v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not write checkpoint %s: %v", s.Path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write checkpoint %s: %v", s.Path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("could not write checkpoint %s: %v", s.Path, err)
+	}
+	return nil
+}
+
+// EventTailer continuously polls the EventStore in sliding time
+// windows and emits each new event once. Every window reaches back
+// by Overlap to pick up events the EventStore recorded late, and
+// events already emitted are skipped by their EventInstanceId.
+type EventTailer struct {
+	// Query returns the events within a time window
+	Query EventQuery
+	// Options filters the events, its time window is set by the tailer
+	Options EventsOptions
+	// Store persists the checkpoint after each window, optional
+	Store CheckpointStore
+	// Start the time to tail from when there is no saved checkpoint,
+	// zero starts from now
+	Start time.Time
+	// Interval the time between polls once the tailer has caught up
+	Interval time.Duration
+	// Overlap how far each window reaches back before the checkpoint
+	Overlap time.Duration
+	// MaxWindow the longest window queried while catching up
+	MaxWindow time.Duration
+	// OnError is called with errors querying events or saving the
+	// checkpoint, which are otherwise retried on the next poll
+	OnError func(err error)
+
+	now func() time.Time
+}
+
+// NewEventTailer returns a tailer polling query with the default
+// interval, overlap and window, saving its checkpoint to store
+func NewEventTailer(query EventQuery, store CheckpointStore) *EventTailer {
+	return &EventTailer{
+		Query:     query,
+		Store:     store,
+		Interval:  defaultTailInterval,
+		Overlap:   defaultTailOverlap,
+		MaxWindow: defaultTailMaxWindow,
+	}
+}
+
+// Run emits new events to events in time stamp order until ctx is
+// done, which is the only error returned once the checkpoint has
+// been loaded. Run does not close events.
+func (t *EventTailer) Run(ctx context.Context, events chan<- Event) error {
+	checkpoint, err := t.loadCheckpoint()
+	if err != nil {
+		return err
+	}
+
+	for {
+		caughtUp, err := t.poll(ctx, checkpoint, events)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && t.OnError != nil {
+			t.OnError(err)
+		}
+
+		if err != nil || caughtUp {
+			timer := time.NewTimer(t.interval())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+func (t *EventTailer) loadCheckpoint() (*EventCheckpoint, error) {
+	if t.Store != nil {
+		checkpoint, err := t.Store.Load()
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil {
+			if checkpoint.Seen == nil {
+				checkpoint.Seen = map[string]time.Time{}
+			}
+			return checkpoint, nil
+		}
+	}
+
+	start := t.Start
+	if start.IsZero() {
+		start = t.clock()
+	}
+	return &EventCheckpoint{Time: start, Seen: map[string]time.Time{}}, nil
+}
+
+// poll queries a single window, emits its new events and advances
+// the checkpoint. It reports whether the window reached the present.
+func (t *EventTailer) poll(ctx context.Context, checkpoint *EventCheckpoint, events chan<- Event) (bool, error) {
+	now := t.clock()
+	end := now
+	if maxWindow := t.maxWindow(); end.Sub(checkpoint.Time) > maxWindow {
+		end = checkpoint.Time.Add(maxWindow)
+	}
+
+	opts := t.Options
+	opts.StartTime = checkpoint.Time.Add(-t.overlap())
+	opts.EndTime = end
+	window, err := t.Query(ctx, opts)
+	if err != nil {
+		return false, err
+	}
+
+	sort.SliceStable(window, func(i, j int) bool {
+		return window[i].GetEventBase().TimeStamp.Before(window[j].GetEventBase().TimeStamp)
+	})
+	for _, event := range window {
+		base := event.GetEventBase()
+		if _, seen := checkpoint.Seen[base.EventInstanceID]; seen {
+			continue
+		}
+
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		checkpoint.Seen[base.EventInstanceID] = base.TimeStamp
+	}
+
+	// The EventStore rounds the start of the next window down to the
+	// second, so events within that second are returned again and must
+	// still be remembered
+	checkpoint.Time = end
+	horizon := end.Add(-t.overlap()).Truncate(time.Second)
+	for id, timeStamp := range checkpoint.Seen {
+		if timeStamp.Before(horizon) {
+			delete(checkpoint.Seen, id)
+		}
+	}
+
+	if t.Store != nil {
+		if err := t.Store.Save(checkpoint); err != nil {
+			return false, err
+		}
+	}
+	return !end.Before(now), nil
+}
+
+func (t *EventTailer) clock() time.Time {
+	if t.now == nil {
+		return time.Now()
+	}
+	return t.now()
+}
+
+func (t *EventTailer) interval() time.Duration {
+	if t.Interval <= 0 {
+		return defaultTailInterval
+	}
+	return t.Interval
+}
+
+func (t *EventTailer) overlap() time.Duration {
+	if t.Overlap < 0 {
+		return 0
+	}
+	return t.Overlap
+}
+
+func (t *EventTailer) maxWindow() time.Duration {
+	if t.MaxWindow <= 0 {
+		return defaultTailMaxWindow
+	}
+	return t.MaxWindow
+}
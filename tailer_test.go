@@ -0,0 +1,264 @@
+package servicefabric
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type memoryCheckpointStore struct {
+	checkpoint *EventCheckpoint
+	saves      int
+}
+
+func (s *memoryCheckpointStore) Load() (*EventCheckpoint, error) {
+	return s.checkpoint, nil
+}
+
+func (s *memoryCheckpointStore) Save(checkpoint *EventCheckpoint) error {
+	saved := *checkpoint
+	saved.Seen = map[string]time.Time{}
+	for id, timeStamp := range checkpoint.Seen {
+		saved.Seen[id] = timeStamp
+	}
+	s.checkpoint = &saved
+	s.saves++
+	return nil
+}
+
+func testEvent(id string, timeStamp time.Time) Event {
+	return &NodeDownEvent{NodeEventBase: NodeEventBase{EventBase: EventBase{
+		Kind:            EventKindNodeDown,
+		EventInstanceID: id,
+		TimeStamp:       timeStamp,
+	}}}
+}
+
+func TestEventTailer(t *testing.T) {
+	now := time.Date(2018, 4, 3, 18, 0, 0, 0, time.UTC)
+	recorded := []Event{
+		testEvent("1", now.Add(-150*time.Minute)),
+		testEvent("2", now.Add(-90*time.Minute)),
+		testEvent("4", now.Add(-30*time.Second)),
+		testEvent("3", now.Add(-30*time.Minute)),
+	}
+	late := testEvent("5", now.Add(-20*time.Second))
+
+	var windows []EventsOptions
+	query := func(ctx context.Context, opts EventsOptions) ([]Event, error) {
+		windows = append(windows, opts)
+		if len(windows) == 4 {
+			recorded = append(recorded, late)
+		}
+
+		var events []Event
+		for _, event := range recorded {
+			timeStamp := event.GetEventBase().TimeStamp
+			if !timeStamp.Before(opts.StartTime) && !timeStamp.After(opts.EndTime) {
+				events = append(events, event)
+			}
+		}
+		return events, nil
+	}
+
+	store := &memoryCheckpointStore{}
+	tailer := NewEventTailer(query, store)
+	tailer.Options = EventsOptions{EventTypes: []EventKind{EventKindNodeDown}}
+	tailer.Start = now.Add(-3 * time.Hour)
+	tailer.Interval = time.Millisecond
+	tailer.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event, 10)
+	done := make(chan error)
+	go func() {
+		done <- tailer.Run(ctx, events)
+	}()
+
+	var ids []string
+	for len(ids) < 5 {
+		select {
+		case event := <-events:
+			ids = append(ids, event.GetEventBase().EventInstanceID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Got events %v, want 5 events", ids)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Got %v, want %v", err, context.Canceled)
+	}
+	close(events)
+	for event := range events {
+		ids = append(ids, event.GetEventBase().EventInstanceID)
+	}
+
+	expected := []string{"1", "2", "3", "4", "5"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Got %v, want %v", ids, expected)
+	}
+
+	if windows[0].EndTime.Sub(windows[0].StartTime) != time.Hour+time.Minute {
+		t.Errorf("Got window %v to %v, want an hour and the overlap", windows[0].StartTime, windows[0].EndTime)
+	}
+	if !reflect.DeepEqual(windows[0].EventTypes, tailer.Options.EventTypes) {
+		t.Errorf("Got %v, want %v", windows[0].EventTypes, tailer.Options.EventTypes)
+	}
+
+	if store.saves == 0 || !store.checkpoint.Time.Equal(now) {
+		t.Fatalf("Got checkpoint %+v, want one at %v", store.checkpoint, now)
+	}
+	if _, ok := store.checkpoint.Seen["3"]; ok {
+		t.Errorf("Got %v, want events before the overlap to be forgotten", store.checkpoint.Seen)
+	}
+	if _, ok := store.checkpoint.Seen["5"]; !ok {
+		t.Errorf("Got %v, want events within the overlap to be remembered", store.checkpoint.Seen)
+	}
+}
+
+func TestEventTailerResumes(t *testing.T) {
+	now := time.Date(2018, 4, 3, 18, 0, 0, 0, time.UTC)
+	store := &memoryCheckpointStore{checkpoint: &EventCheckpoint{
+		Time: now.Add(-10 * time.Second),
+		Seen: map[string]time.Time{"1": now.Add(-30 * time.Second)},
+	}}
+
+	var window EventsOptions
+	query := func(ctx context.Context, opts EventsOptions) ([]Event, error) {
+		window = opts
+		return []Event{testEvent("1", now.Add(-30*time.Second)), testEvent("2", now.Add(-5*time.Second))}, nil
+	}
+
+	tailer := NewEventTailer(query, store)
+	tailer.now = func() time.Time { return now }
+
+	checkpoint, err := tailer.loadCheckpoint()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	events := make(chan Event, 2)
+	caughtUp, err := tailer.poll(context.Background(), checkpoint, events)
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !caughtUp {
+		t.Error("Got false, want the tailer to have caught up")
+	}
+	if !window.StartTime.Equal(now.Add(-70 * time.Second)) {
+		t.Errorf("Got %v, want the window to start an overlap before the checkpoint", window.StartTime)
+	}
+
+	close(events)
+	var ids []string
+	for event := range events {
+		ids = append(ids, event.GetEventBase().EventInstanceID)
+	}
+	if !reflect.DeepEqual(ids, []string{"2"}) {
+		t.Errorf("Got %v, want [2]", ids)
+	}
+}
+
+func TestEventTailerRemembersEventsInRoundedWindow(t *testing.T) {
+	now := time.Date(2018, 4, 3, 18, 0, 0, 500000000, time.UTC)
+	recorded := []Event{testEvent("1", now.Add(-time.Minute-300*time.Millisecond))}
+
+	// The EventStore only takes whole seconds
+	query := func(ctx context.Context, opts EventsOptions) ([]Event, error) {
+		var events []Event
+		for _, event := range recorded {
+			timeStamp := event.GetEventBase().TimeStamp
+			if !timeStamp.Before(opts.StartTime.Truncate(time.Second)) && !timeStamp.After(opts.EndTime) {
+				events = append(events, event)
+			}
+		}
+		return events, nil
+	}
+
+	tailer := NewEventTailer(query, nil)
+	tailer.Start = now.Add(-2 * time.Second)
+	tailer.now = func() time.Time { return now }
+
+	checkpoint, err := tailer.loadCheckpoint()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	events := make(chan Event, 2)
+	for i := 0; i < 2; i++ {
+		if _, err := tailer.poll(context.Background(), checkpoint, events); err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+	}
+	if len(events) != 1 {
+		t.Errorf("Got %d events, want the event emitted once", len(events))
+	}
+}
+
+func TestEventTailerCancelsQuery(t *testing.T) {
+	query := func(ctx context.Context, opts EventsOptions) ([]Event, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	tailer := NewEventTailer(query, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- tailer.Run(ctx, make(chan Event))
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Got %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the query to be cancelled")
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileCheckpointStore(filepath.Join(dir, "events.json"))
+
+	checkpoint, err := store.Load()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if checkpoint != nil {
+		t.Errorf("Got %+v, want no checkpoint", checkpoint)
+	}
+
+	expected := &EventCheckpoint{
+		Time: time.Date(2018, 4, 3, 18, 0, 0, 0, time.UTC),
+		Seen: map[string]time.Time{"1": time.Date(2018, 4, 3, 17, 59, 30, 0, time.UTC)},
+	}
+	if err := store.Save(expected); err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+
+	actual, err := store.Load()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Got %+v, want %+v", actual, expected)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Got %d files, want only the checkpoint", len(files))
+	}
+}
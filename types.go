@@ -84,13 +84,13 @@ type PartitionInformation struct {
 // ReplicaItemBase shared data used
 // in both replicas and instances
 type ReplicaItemBase struct {
-	Address                      string `json:"Address"`
-	HealthState                  string `json:"HealthState"`
-	LastInBuildDurationInSeconds string `json:"LastInBuildDurationInSeconds"`
-	NodeName                     string `json:"NodeName"`
-	ReplicaRole                  string `json:"ReplicaRole"`
-	ReplicaStatus                string `json:"ReplicaStatus"`
-	ServiceKind                  string `json:"ServiceKind"`
+	Address                      string        `json:"Address"`
+	HealthState                  string        `json:"HealthState"`
+	LastInBuildDurationInSeconds string        `json:"LastInBuildDurationInSeconds"`
+	NodeName                     string        `json:"NodeName"`
+	ReplicaRole                  ReplicaRole   `json:"ReplicaRole"`
+	ReplicaStatus                ReplicaStatus `json:"ReplicaStatus"`
+	ServiceKind                  string        `json:"ServiceKind"`
 }
 
 // ReplicaItemsPage encapsulates the response
@@ -140,14 +140,23 @@ type ServiceType struct {
 
 // ServiceTypeDescription Service Type Description
 type ServiceTypeDescription struct {
-	IsStateful               bool           `json:"IsStateful"`
-	ServiceTypeName          string         `json:"ServiceTypeName"`
-	PlacementConstraints     string         `json:"PlacementConstraints"`
-	HasPersistedState        bool           `json:"HasPersistedState"`
-	Kind                     string         `json:"Kind"`
-	Extensions               []KeyValuePair `json:"Extensions"`
-	LoadMetrics              []interface{}  `json:"LoadMetrics"`
-	ServicePlacementPolicies []interface{}  `json:"ServicePlacementPolicies"`
+	IsStateful               bool                               `json:"IsStateful"`
+	ServiceTypeName          string                             `json:"ServiceTypeName"`
+	PlacementConstraints     string                             `json:"PlacementConstraints"`
+	HasPersistedState        bool                               `json:"HasPersistedState"`
+	Kind                     string                             `json:"Kind"`
+	Extensions               []KeyValuePair                     `json:"Extensions"`
+	LoadMetrics              []ServiceLoadMetricDescription     `json:"LoadMetrics"`
+	ServicePlacementPolicies ServicePlacementPolicyDescriptions `json:"ServicePlacementPolicies"`
+}
+
+// ServiceLoadMetricDescription Service load metric description
+type ServiceLoadMetricDescription struct {
+	Name                 string `json:"Name"`
+	Weight               string `json:"Weight"`
+	PrimaryDefaultLoad   int64  `json:"PrimaryDefaultLoad"`
+	SecondaryDefaultLoad int64  `json:"SecondaryDefaultLoad"`
+	DefaultLoad          int64  `json:"DefaultLoad"`
 }
 
 // PropertiesListPage encapsulates the response model for
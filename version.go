@@ -0,0 +1,156 @@
+package servicefabric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedByCluster is returned when an operation requires a
+// newer API version than the cluster negotiated by NegotiateAPIVersion
+// supports
+var ErrUnsupportedByCluster = errors.New("operation not supported by the cluster")
+
+// legacyClusterAPIVersion the newest API version
+// supported by clusters older than runtime 6.0
+const legacyClusterAPIVersion = "3.0"
+
+// unversionedClusterAPIVersion the newest API version supported by
+// clusters older than runtime 6.4, which cannot report their version
+const unversionedClusterAPIVersion = "6.3"
+
+// ClusterVersion encapsulates the response model for
+// the cluster version in the Service Fabric API
+type ClusterVersion struct {
+	Version string `json:"Version"`
+}
+
+// GetClusterVersion returns the Service Fabric runtime
+// version the cluster is currently running.
+func (c Client) GetClusterVersion() (*ClusterVersion, error) {
+	res, err := c.getHTTP(opGetClusterVersion, "$/GetClusterVersion")
+	if err != nil {
+		return nil, err
+	}
+
+	var version ClusterVersion
+	err = json.Unmarshal(res, &version)
+	if err != nil {
+		return nil, fmt.Errorf("could not deserialise JSON response: %+v", err)
+	}
+	return &version, nil
+}
+
+// NegotiateAPIVersion probes the cluster for the newest API version
+// it supports. Afterwards each call uses the oldest API version that
+// supports it, no older than the version the client was created
+// with and no newer than the cluster supports, and calls the cluster
+// cannot serve fail with ErrUnsupportedByCluster. Clusters older than
+// runtime 6.4 cannot report their version and respond to the probe
+// with 400 or 404, they are capped at the API version of runtime 6.3.
+// If the probe otherwise fails the client is left as it was and the
+// error is returned.
+func (c *Client) NegotiateAPIVersion() error {
+	version, err := c.GetClusterVersion()
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) &&
+		(responseErr.StatusCode == http.StatusBadRequest || responseErr.StatusCode == http.StatusNotFound) {
+		c.clusterAPIVersion = unversionedClusterAPIVersion
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not negotiate API version: %w", err)
+	}
+
+	apiVersion, err := clusterAPIVersion(version.Version)
+	if err != nil {
+		return fmt.Errorf("could not negotiate API version: %w", err)
+	}
+	c.clusterAPIVersion = apiVersion
+	return nil
+}
+
+// apiVersionFor returns the API version to request op with
+func (c Client) apiVersionFor(op operation) (string, error) {
+	version := c.apiVersion
+	if compareAPIVersions(op.minAPIVersion, version) > 0 {
+		version = op.minAPIVersion
+	}
+
+	if c.clusterAPIVersion == "" || compareAPIVersions(version, c.clusterAPIVersion) <= 0 {
+		return version, nil
+	}
+	if compareAPIVersions(op.minAPIVersion, c.clusterAPIVersion) > 0 {
+		return "", fmt.Errorf("%w: %s requires API version %s, cluster supports up to %s",
+			ErrUnsupportedByCluster, op.name, op.minAPIVersion, c.clusterAPIVersion)
+	}
+	return c.clusterAPIVersion, nil
+}
+
+// clusterAPIVersion maps a runtime version such as 6.4.617.9590
+// to the newest API version the runtime supports
+func clusterAPIVersion(runtimeVersion string) (string, error) {
+	major, minor, err := parseAPIVersion(runtimeVersion)
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster version %q", runtimeVersion)
+	}
+	if major < 6 {
+		return legacyClusterAPIVersion, nil
+	}
+	return strconv.Itoa(major) + "." + strconv.Itoa(minor), nil
+}
+
+// compareAPIVersions returns -1, 0 or 1 when a is older than, the
+// same as or newer than b. Only the major and minor versions are
+// compared and unparsable versions sort before all others.
+func compareAPIVersions(a, b string) int {
+	aMajor, aMinor, aErr := parseAPIVersion(a)
+	bMajor, bMinor, bErr := parseAPIVersion(b)
+	switch {
+	case aErr != nil && bErr != nil:
+		return 0
+	case aErr != nil:
+		return -1
+	case bErr != nil:
+		return 1
+	case aMajor != bMajor:
+		return compareInts(aMajor, bMajor)
+	default:
+		return compareInts(aMinor, bMinor)
+	}
+}
+
+// parseAPIVersion returns the major and minor version of an
+// API or runtime version such as 6.4, 7.0-preview or 6.4.617.9590
+func parseAPIVersion(version string) (int, int, error) {
+	if i := strings.Index(version, "-"); i >= 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	minor := 0
+	if len(parts) > 1 {
+		minor, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return major, minor, nil
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
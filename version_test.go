@@ -0,0 +1,161 @@
+package servicefabric
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareAPIVersions(t *testing.T) {
+	testCases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "3.0", b: "6.0", expected: -1},
+		{a: "6.4", b: "6.4", expected: 0},
+		{a: "6.10", b: "6.4", expected: 1},
+		{a: "7.0-preview", b: "7.0", expected: 0},
+		{a: "6.4.617.9590", b: "6.4", expected: 0},
+		{a: "invalid", b: "1.0", expected: -1},
+	}
+
+	for _, test := range testCases {
+		actual := compareAPIVersions(test.a, test.b)
+		if actual != test.expected {
+			t.Errorf("compareAPIVersions(%s, %s): Got %d, want %d", test.a, test.b, actual, test.expected)
+		}
+	}
+}
+
+func TestClusterAPIVersion(t *testing.T) {
+	testCases := []struct {
+		runtimeVersion string
+		expected       string
+	}{
+		{runtimeVersion: "5.7.198.9494", expected: "3.0"},
+		{runtimeVersion: "6.4.617.9590", expected: "6.4"},
+		{runtimeVersion: "7.1.409.9590", expected: "7.1"},
+	}
+
+	for _, test := range testCases {
+		actual, err := clusterAPIVersion(test.runtimeVersion)
+		if err != nil {
+			t.Fatalf("Exception thrown %v", err)
+		}
+		if actual != test.expected {
+			t.Errorf("Got %s, want %s", actual, test.expected)
+		}
+	}
+
+	if _, err := clusterAPIVersion(""); err == nil {
+		t.Error("Expected an error for an empty cluster version")
+	}
+}
+
+func TestAPIVersionFor(t *testing.T) {
+	opNewer := operation{name: "Newer", template: "Newer", minAPIVersion: "7.0"}
+
+	testCases := []struct {
+		name              string
+		apiVersion        string
+		clusterAPIVersion string
+		op                operation
+		expected          string
+	}{
+		{name: "configured version", apiVersion: "3.0", op: opGetApplications, expected: "3.0"},
+		{name: "raised to operation minimum", apiVersion: "3.0", op: opGetProperties, expected: "6.0"},
+		{name: "not negotiated", apiVersion: "3.0", op: opNewer, expected: "7.0"},
+		{name: "within cluster", apiVersion: "3.0", clusterAPIVersion: "6.4", op: opGetProperties, expected: "6.0"},
+		{name: "capped at cluster", apiVersion: "7.0", clusterAPIVersion: "6.4", op: opGetProperties, expected: "6.4"},
+	}
+
+	for _, test := range testCases {
+		sfClient := Client{apiVersion: test.apiVersion, clusterAPIVersion: test.clusterAPIVersion}
+		actual, err := sfClient.apiVersionFor(test.op)
+		if err != nil {
+			t.Fatalf("%s: Exception thrown %v", test.name, err)
+		}
+		if actual != test.expected {
+			t.Errorf("%s: Got %s, want %s", test.name, actual, test.expected)
+		}
+	}
+
+	sfClient := Client{apiVersion: "3.0", clusterAPIVersion: "6.4"}
+	_, err := sfClient.apiVersionFor(opNewer)
+	if !errors.Is(err, ErrUnsupportedByCluster) {
+		t.Errorf("Got %v, want %v", err, ErrUnsupportedByCluster)
+	}
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	server := httptest.NewServer(handleClusterVersion())
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "", nil)
+
+	err := sfClient.NegotiateAPIVersion()
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if sfClient.clusterAPIVersion != "6.4" {
+		t.Errorf("Got %s, want 6.4", sfClient.clusterAPIVersion)
+	}
+
+	exists, properties, err := sfClient.GetProperties("TestApplication/TestService")
+	if err != nil {
+		t.Fatalf("Exception thrown %v", err)
+	}
+	if !exists || len(properties) == 0 {
+		t.Errorf("Got %v %+v, want properties", exists, properties)
+	}
+
+	sfClient.clusterAPIVersion = "3.0"
+	_, _, err = sfClient.GetProperties("TestApplication/TestService")
+	if !errors.Is(err, ErrUnsupportedByCluster) {
+		t.Errorf("Got %v, want %v", err, ErrUnsupportedByCluster)
+	}
+}
+
+func TestNegotiateAPIVersionUnversionedCluster(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		sfClient, _ := NewClient(http.DefaultClient, server.URL, "", nil)
+
+		err := sfClient.NegotiateAPIVersion()
+		server.Close()
+		if err != nil {
+			t.Fatalf("%d: Exception thrown %v", status, err)
+		}
+		if sfClient.clusterAPIVersion != "6.3" {
+			t.Errorf("%d: Got %s, want 6.3", status, sfClient.clusterAPIVersion)
+		}
+
+		_, err = sfClient.GetClusterVersion()
+		if !errors.Is(err, ErrUnsupportedByCluster) {
+			t.Errorf("%d: Got %v, want %v", status, err, ErrUnsupportedByCluster)
+		}
+	}
+}
+
+func TestNegotiateAPIVersionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sfClient, _ := NewClient(http.DefaultClient, server.URL, "", nil)
+
+	err := sfClient.NegotiateAPIVersion()
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) || responseErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Got %v, want the cluster's error", err)
+	}
+	if sfClient.clusterAPIVersion != "" {
+		t.Errorf("Got %s, want the API version to be left unnegotiated", sfClient.clusterAPIVersion)
+	}
+}